@@ -0,0 +1,63 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterPieProbeSpec defines the desired state of ClusterPieProbe
+type ClusterPieProbeSpec struct {
+	// TargetNamespace is the namespace where the probes are run.
+	// The controller should watch the namespace.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="targetNamespace is immutable"
+	TargetNamespace string `json:"targetNamespace"`
+
+	// PieProbeSpec is the spec of the PieProbe created in the target namespace.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="has(self.monitoringStorageClass) != has(self.storageClassSelector)",message="exactly one of monitoringStorageClass and storageClassSelector must be specified"
+	PieProbeSpec PieProbeSpec `json:"pieProbeSpec"`
+}
+
+const (
+	// ClusterPieProbeConditionTargetNamespaceNotWatched is true when the target namespace is not watched
+	// by the controller, so the PieProbe is not created.
+	ClusterPieProbeConditionTargetNamespaceNotWatched = "TargetNamespaceNotWatched"
+)
+
+// ClusterPieProbeStatus defines the observed state of ClusterPieProbe
+type ClusterPieProbeStatus struct {
+	// Conditions are copied from the PieProbe created in the target namespace.
+	// If the target namespace is not watched, the TargetNamespaceNotWatched condition is reported instead.
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:subresource:status
+//+kubebuilder:validation:XValidation:rule="self.metadata.name.size() <= 63",message="name must be no more than 63 characters"
+
+// ClusterPieProbe is the Schema for the clusterpieprobes API
+type ClusterPieProbe struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterPieProbeSpec   `json:"spec,omitempty"`
+	Status ClusterPieProbeStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterPieProbeList contains a list of ClusterPieProbe
+type ClusterPieProbeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterPieProbe `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterPieProbe{}, &ClusterPieProbeList{})
+}
@@ -10,13 +10,24 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // PieProbeSpec defines the desired state of PieProbe
+// +kubebuilder:validation:XValidation:rule="!(self.provisionProbePerNode && has(self.provisionProbeTopologyKey))",message="provisionProbePerNode and provisionProbeTopologyKey are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!(has(self.snapshotProbe) && self.disableMountProbes)",message="snapshotProbe requires the mount probes"
+// +kubebuilder:validation:XValidation:rule="!(has(self.cloneProbe) && self.disableMountProbes)",message="cloneProbe requires the mount probes"
+// +kubebuilder:validation:XValidation:rule="!(has(self.sharedAccessProbe) && has(self.volumeMode) && self.volumeMode == 'Block')",message="sharedAccessProbe requires the Filesystem volumeMode"
+// +kubebuilder:validation:XValidation:rule="!(has(self.sharedAccessProbe) && has(self.resultTransport) && self.resultTransport != 'HTTP')",message="sharedAccessProbe requires the HTTP resultTransport"
 type PieProbeSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// +kubebuilder:validation:Required
+	// MonitoringStorageClass is the name of the StorageClass to be monitored.
+	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="monitoringStorageClass is immutable"
-	MonitoringStorageClass string `json:"monitoringStorageClass"`
+	MonitoringStorageClass string `json:"monitoringStorageClass,omitempty"`
+
+	// StorageClassSelector selects the StorageClasses to be monitored by their labels.
+	// It can be used instead of MonitoringStorageClass to monitor multiple StorageClasses.
+	// +kubebuilder:validation:Optional
+	StorageClassSelector *metav1.LabelSelector `json:"storageClassSelector,omitempty"`
 
 	NodeSelector corev1.NodeSelector `json:"nodeSelector"`
 
@@ -45,12 +56,229 @@ type PieProbeSpec struct {
 
 	//+kubebuilder:validation:Optional
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// VolumeMode is the volumeMode of the PVCs of the probes.
+	// If it is Block, the probes benchmark and verify the raw devices instead of the filesystems.
+	//+kubebuilder:default:="Filesystem"
+	//+kubebuilder:validation:Enum=Filesystem;Block
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:XValidation:rule="self == oldSelf",message="volumeMode is immutable"
+	VolumeMode corev1.PersistentVolumeMode `json:"volumeMode,omitempty"`
+
+	// ProvisionProbeTopologyKey is the node label key used to spread the provision probes.
+	// If it is specified, a provision probe is created for each value of the label among the selected nodes.
+	// e.g. topology.kubernetes.io/zone
+	//+kubebuilder:validation:Optional
+	ProvisionProbeTopologyKey string `json:"provisionProbeTopologyKey,omitempty"`
+
+	// ProvisionProbePerNode creates a provision probe for each selected node.
+	// It is useful for the storage plugins creating node-local volumes.
+	//+kubebuilder:default:=false
+	//+kubebuilder:validation:Optional
+	ProvisionProbePerNode bool `json:"provisionProbePerNode"`
+
+	// PVCRotationPeriod is the age after which the PVCs of the mount probes are recreated.
+	// The PVCs are deleted and recreated between the runs of the mount probes.
+	// They are never rotated if it is not specified.
+	//+kubebuilder:validation:Optional
+	PVCRotationPeriod *metav1.Duration `json:"pvcRotationPeriod,omitempty"`
+
+	// SpoolResults makes the mount probes spool their results on the PVCs if the controller is unreachable.
+	// The spooled results are sent to the controller on the next runs, and the duplicates are ignored.
+	// It is ignored if volumeMode is Block.
+	//+kubebuilder:default:=false
+	//+kubebuilder:validation:Optional
+	SpoolResults bool `json:"spoolResults"`
+
+	// ResultTransport is how the mount probes report their results to the controller.
+	// HTTP posts them to the controller. GRPC streams them to the gRPC receiver of the controller,
+	// and falls back to HTTP if the controller does not expose it. TerminationMessage writes them to
+	// the termination messages of the probe containers, which are read by the controller and need no network path
+	// from the probes to the controller. spoolResults is ignored if it is TerminationMessage.
+	// sharedAccessProbe requires HTTP, because the shared-access probes always post their results to the controller.
+	//+kubebuilder:default:="HTTP"
+	//+kubebuilder:validation:Enum=HTTP;GRPC;TerminationMessage
+	//+kubebuilder:validation:Optional
+	ResultTransport ResultTransport `json:"resultTransport,omitempty"`
+
+	// MetadataOperations is the number of the files created in the metadata benchmark of the mount probes.
+	// The benchmark is disabled if it is 0. It is ignored if volumeMode is Block.
+	//+kubebuilder:default:=0
+	//+kubebuilder:validation:Minimum:=0
+	//+kubebuilder:validation:Maximum:=10000
+	//+kubebuilder:validation:Optional
+	MetadataOperations int `json:"metadataOperations,omitempty"`
+
+	// SnapshotProbe takes a VolumeSnapshot of the mount-probe PVC of each node periodically,
+	// restores it into a new PVC and verifies the data written by the mount probe.
+	// It is disabled if not specified.
+	//+kubebuilder:validation:Optional
+	SnapshotProbe *SnapshotProbeSpec `json:"snapshotProbe,omitempty"`
+
+	// ExpansionProbe provisions a PVC on each node periodically, expands it while it is mounted
+	// and checks that the filesystem grows.
+	// The StorageClasses not allowing volume expansion are skipped.
+	// It is disabled if not specified.
+	//+kubebuilder:validation:Optional
+	ExpansionProbe *ExpansionProbeSpec `json:"expansionProbe,omitempty"`
+
+	// CloneProbe clones the mount-probe PVC of each node periodically,
+	// mounts the clone on the same node and verifies the data written by the mount probe.
+	// It is disabled if not specified.
+	//+kubebuilder:validation:Optional
+	CloneProbe *CloneProbeSpec `json:"cloneProbe,omitempty"`
+
+	// SharedAccessProbe mounts a ReadWriteMany PVC on several nodes at the same time periodically
+	// and checks that the files written on each node are visible on the others.
+	// It is disabled if not specified.
+	//+kubebuilder:validation:Optional
+	SharedAccessProbe *SharedAccessProbeSpec `json:"sharedAccessProbe,omitempty"`
+
+	// FailoverProbe mounts a PVC on a node and writes data to it periodically,
+	// then moves it to another node and verifies the data.
+	// The time to detach the volume from the first node, attach it to the second node and mount it there
+	// is measured separately by watching the VolumeAttachments.
+	// It is disabled if not specified.
+	//+kubebuilder:validation:Optional
+	FailoverProbe *FailoverProbeSpec `json:"failoverProbe,omitempty"`
+}
+
+// SnapshotProbeSpec defines the snapshot probe.
+type SnapshotProbeSpec struct {
+	// VolumeSnapshotClassName is the name of the VolumeSnapshotClass used to take the snapshots.
+	//+kubebuilder:validation:MinLength:=1
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName"`
+
+	// Period is the interval of the snapshot probes in minutes.
+	//+kubebuilder:default:=10
+	//+kubebuilder:validation:Minimum:=1
+	//+kubebuilder:validation:Optional
+	Period int `json:"period"`
+
+	// Timeout is the time limit of each step of the snapshot probe.
+	//+kubebuilder:default:="5m"
+	//+kubebuilder:validation:Optional
+	Timeout metav1.Duration `json:"timeout"`
 }
 
+// ExpansionProbeSpec defines the expansion probe.
+type ExpansionProbeSpec struct {
+	// ExpandedCapacity is the capacity to which the PVC is expanded.
+	// It must be larger than pvcCapacity.
+	//+kubebuilder:default:="200Mi"
+	//+kubebuilder:validation:Optional
+	ExpandedCapacity *resource.Quantity `json:"expandedCapacity"`
+
+	// Period is the interval of the expansion probes in minutes.
+	//+kubebuilder:default:=10
+	//+kubebuilder:validation:Minimum:=1
+	//+kubebuilder:validation:Optional
+	Period int `json:"period"`
+
+	// Timeout is the time limit of each step of the expansion probe.
+	//+kubebuilder:default:="5m"
+	//+kubebuilder:validation:Optional
+	Timeout metav1.Duration `json:"timeout"`
+}
+
+// CloneProbeSpec defines the clone probe.
+type CloneProbeSpec struct {
+	// Period is the interval of the clone probes in minutes.
+	//+kubebuilder:default:=10
+	//+kubebuilder:validation:Minimum:=1
+	//+kubebuilder:validation:Optional
+	Period int `json:"period"`
+
+	// Timeout is the time limit of each step of the clone probe.
+	//+kubebuilder:default:="5m"
+	//+kubebuilder:validation:Optional
+	Timeout metav1.Duration `json:"timeout"`
+}
+
+// SharedAccessProbeSpec defines the shared-access probe.
+type SharedAccessProbeSpec struct {
+	// NodeSelector selects the nodes sharing the PVC among the nodes selected by .spec.nodeSelector.
+	// All of them share the PVC if not specified.
+	//+kubebuilder:validation:Optional
+	NodeSelector *corev1.NodeSelector `json:"nodeSelector,omitempty"`
+
+	// Period is the interval of the shared-access probes in minutes.
+	//+kubebuilder:default:=10
+	//+kubebuilder:validation:Minimum:=1
+	//+kubebuilder:validation:Optional
+	Period int `json:"period"`
+
+	// Deadline is the time limit for the files written on each node to be visible on the others.
+	// It is measured from when the file of the node itself is written.
+	//+kubebuilder:default:="1m"
+	//+kubebuilder:validation:Optional
+	Deadline metav1.Duration `json:"deadline"`
+
+	// Timeout is the time limit of each run of the shared-access probe.
+	// It should be long enough for the PVC to be provisioned and mounted on all the nodes.
+	//+kubebuilder:default:="5m"
+	//+kubebuilder:validation:Optional
+	Timeout metav1.Duration `json:"timeout"`
+}
+
+// FailoverProbeSpec defines the failover probe.
+type FailoverProbeSpec struct {
+	// NodeSelector selects the nodes between which the PVC is moved among the nodes selected by .spec.nodeSelector.
+	// All of them are candidates if not specified.
+	//+kubebuilder:validation:Optional
+	NodeSelector *corev1.NodeSelector `json:"nodeSelector,omitempty"`
+
+	// Period is the interval of the failover probes in minutes.
+	//+kubebuilder:default:=10
+	//+kubebuilder:validation:Minimum:=1
+	//+kubebuilder:validation:Optional
+	Period int `json:"period"`
+
+	// Timeout is the time limit of each step of the failover probe.
+	// The steps taking longer than .spec.probeThreshold are counted as not on time.
+	//+kubebuilder:default:="5m"
+	//+kubebuilder:validation:Optional
+	Timeout metav1.Duration `json:"timeout"`
+}
+
+// ResultTransport is how the mount probes report their results to the controller.
+type ResultTransport string
+
+const (
+	// ResultTransportHTTP makes the mount probes post their results to the controller.
+	ResultTransportHTTP ResultTransport = "HTTP"
+	// ResultTransportGRPC makes the mount probes stream their results to the gRPC receiver of the controller.
+	ResultTransportGRPC ResultTransport = "GRPC"
+	// ResultTransportTerminationMessage makes the mount probes write their results to their termination messages.
+	ResultTransportTerminationMessage ResultTransport = "TerminationMessage"
+)
+
+const (
+	// PieProbeConditionStorageClassNotFound is true when no StorageClass monitored by the PieProbe exists.
+	PieProbeConditionStorageClassNotFound = "StorageClassNotFound"
+	// PieProbeConditionPersistentVolumeLeaked is true when PersistentVolumes of the probes are not reclaimed.
+	PieProbeConditionPersistentVolumeLeaked = "PersistentVolumeLeaked"
+	// PieProbeConditionPersistentVolumeMismatched is true when PersistentVolumes bound to the mount-probe PVCs
+	// do not match the nodes, the StorageClasses or pvcCapacity.
+	PieProbeConditionPersistentVolumeMismatched = "PersistentVolumeMismatched"
+	// PieProbeConditionMountMismatched is true when the filesystems mounted by the mount probes
+	// do not match the fstype or the mountOptions of the StorageClasses, or are read-only.
+	PieProbeConditionMountMismatched = "MountMismatched"
+	// PieProbeConditionTopologyNotFound is true when provisionProbeTopologyKey is specified
+	// and none of the selected nodes has the label, so no provision probe is created.
+	PieProbeConditionTopologyNotFound = "TopologyNotFound"
+)
+
 // PieProbeStatus defines the observed state of PieProbe
 type PieProbeStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// Conditions represent the latest available observations of the PieProbe's state.
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -61,6 +289,7 @@ type PieProbe struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
+	// +kubebuilder:validation:XValidation:rule="has(self.monitoringStorageClass) != has(self.storageClassSelector)",message="exactly one of monitoringStorageClass and storageClassSelector must be specified"
 	Spec   PieProbeSpec   `json:"spec,omitempty"`
 	Status PieProbeStatus `json:"status,omitempty"`
 }
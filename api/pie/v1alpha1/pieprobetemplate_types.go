@@ -0,0 +1,103 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PieProbeTemplateSpec defines the desired state of PieProbeTemplate
+type PieProbeTemplateSpec struct {
+	// StorageClassSelector selects the StorageClasses for which PieProbes are created.
+	// If it is not specified, all StorageClasses are selected.
+	// +kubebuilder:validation:Optional
+	StorageClassSelector *metav1.LabelSelector `json:"storageClassSelector,omitempty"`
+
+	// ExcludedProvisioners is the list of provisioners whose StorageClasses are not selected.
+	// +kubebuilder:validation:Optional
+	ExcludedProvisioners []string `json:"excludedProvisioners,omitempty"`
+
+	// PieProbeSpec is the spec of the PieProbes created for the selected StorageClasses.
+	// monitoringStorageClass is set by the controller, so it must not be specified here.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="!has(self.monitoringStorageClass) && !has(self.storageClassSelector)",message="monitoringStorageClass and storageClassSelector must not be specified"
+	PieProbeSpec PieProbeSpec `json:"pieProbeSpec"`
+}
+
+// StorageClassState is the state of a StorageClass selected by a PieProbeTemplate.
+type StorageClassState string
+
+const (
+	// StorageClassStateManaged means the PieProbe for the StorageClass is controlled by the PieProbeTemplate.
+	StorageClassStateManaged StorageClassState = "Managed"
+	// StorageClassStateIgnored means the StorageClass is opted out by the pie.topolvm.io/ignore annotation.
+	StorageClassStateIgnored StorageClassState = "Ignored"
+	// StorageClassStateExcluded means the provisioner of the StorageClass is in excludedProvisioners.
+	StorageClassStateExcluded StorageClassState = "Excluded"
+	// StorageClassStateConflicted means the PieProbe for the StorageClass exists
+	// and is not controlled by the PieProbeTemplate, so it is left untouched.
+	StorageClassStateConflicted StorageClassState = "Conflicted"
+)
+
+const (
+	// PieProbeTemplateConditionPieProbeConflicted is true when the PieProbes for some StorageClasses
+	// exist and are not controlled by the PieProbeTemplate.
+	PieProbeTemplateConditionPieProbeConflicted = "PieProbeConflicted"
+)
+
+// PieProbeTemplateStorageClassStatus is the state of a StorageClass selected by the PieProbeTemplate.
+type PieProbeTemplateStorageClassStatus struct {
+	// Name is the name of the StorageClass.
+	Name string `json:"name"`
+
+	// PieProbeName is the name of the PieProbe for the StorageClass.
+	// It is empty if the StorageClass is ignored or excluded.
+	// +kubebuilder:validation:Optional
+	PieProbeName string `json:"pieProbeName,omitempty"`
+
+	// State is whether the PieProbe for the StorageClass is controlled by the PieProbeTemplate.
+	State StorageClassState `json:"state"`
+
+	// Message is the reason why the PieProbe for the StorageClass is not controlled by the PieProbeTemplate.
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+}
+
+// PieProbeTemplateStatus defines the observed state of PieProbeTemplate
+type PieProbeTemplateStatus struct {
+	// StorageClasses are the StorageClasses matching storageClassSelector and the states of their PieProbes.
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:Optional
+	StorageClasses []PieProbeTemplateStorageClassStatus `json:"storageClasses,omitempty"`
+
+	// Conditions represent the latest available observations of the PieProbeTemplate's state.
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:subresource:status
+
+// PieProbeTemplate is the Schema for the pieprobetemplates API
+type PieProbeTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PieProbeTemplateSpec   `json:"spec,omitempty"`
+	Status PieProbeTemplateStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PieProbeTemplateList contains a list of PieProbeTemplate
+type PieProbeTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PieProbeTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PieProbeTemplate{}, &PieProbeTemplateList{})
+}
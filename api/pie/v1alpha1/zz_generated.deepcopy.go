@@ -5,16 +5,173 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloneProbeSpec) DeepCopyInto(out *CloneProbeSpec) {
+	*out = *in
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneProbeSpec.
+func (in *CloneProbeSpec) DeepCopy() *CloneProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloneProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPieProbe) DeepCopyInto(out *ClusterPieProbe) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPieProbe.
+func (in *ClusterPieProbe) DeepCopy() *ClusterPieProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPieProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterPieProbe) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPieProbeList) DeepCopyInto(out *ClusterPieProbeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterPieProbe, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPieProbeList.
+func (in *ClusterPieProbeList) DeepCopy() *ClusterPieProbeList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPieProbeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterPieProbeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPieProbeSpec) DeepCopyInto(out *ClusterPieProbeSpec) {
+	*out = *in
+	in.PieProbeSpec.DeepCopyInto(&out.PieProbeSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPieProbeSpec.
+func (in *ClusterPieProbeSpec) DeepCopy() *ClusterPieProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPieProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPieProbeStatus) DeepCopyInto(out *ClusterPieProbeStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPieProbeStatus.
+func (in *ClusterPieProbeStatus) DeepCopy() *ClusterPieProbeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPieProbeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExpansionProbeSpec) DeepCopyInto(out *ExpansionProbeSpec) {
+	*out = *in
+	if in.ExpandedCapacity != nil {
+		in, out := &in.ExpandedCapacity, &out.ExpandedCapacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExpansionProbeSpec.
+func (in *ExpansionProbeSpec) DeepCopy() *ExpansionProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExpansionProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailoverProbeSpec) DeepCopyInto(out *FailoverProbeSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(corev1.NodeSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailoverProbeSpec.
+func (in *FailoverProbeSpec) DeepCopy() *FailoverProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FailoverProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PieProbe) DeepCopyInto(out *PieProbe) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PieProbe.
@@ -70,6 +227,11 @@ func (in *PieProbeList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PieProbeSpec) DeepCopyInto(out *PieProbeSpec) {
 	*out = *in
+	if in.StorageClassSelector != nil {
+		in, out := &in.StorageClassSelector, &out.StorageClassSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	in.NodeSelector.DeepCopyInto(&out.NodeSelector)
 	out.ProbeThreshold = in.ProbeThreshold
 	if in.PVCCapacity != nil {
@@ -78,6 +240,36 @@ func (in *PieProbeSpec) DeepCopyInto(out *PieProbeSpec) {
 		*out = &x
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
+	if in.PVCRotationPeriod != nil {
+		in, out := &in.PVCRotationPeriod, &out.PVCRotationPeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.SnapshotProbe != nil {
+		in, out := &in.SnapshotProbe, &out.SnapshotProbe
+		*out = new(SnapshotProbeSpec)
+		**out = **in
+	}
+	if in.ExpansionProbe != nil {
+		in, out := &in.ExpansionProbe, &out.ExpansionProbe
+		*out = new(ExpansionProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloneProbe != nil {
+		in, out := &in.CloneProbe, &out.CloneProbe
+		*out = new(CloneProbeSpec)
+		**out = **in
+	}
+	if in.SharedAccessProbe != nil {
+		in, out := &in.SharedAccessProbe, &out.SharedAccessProbe
+		*out = new(SharedAccessProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailoverProbe != nil {
+		in, out := &in.FailoverProbe, &out.FailoverProbe
+		*out = new(FailoverProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PieProbeSpec.
@@ -93,6 +285,13 @@ func (in *PieProbeSpec) DeepCopy() *PieProbeSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PieProbeStatus) DeepCopyInto(out *PieProbeStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PieProbeStatus.
@@ -104,3 +303,168 @@ func (in *PieProbeStatus) DeepCopy() *PieProbeStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PieProbeTemplate) DeepCopyInto(out *PieProbeTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PieProbeTemplate.
+func (in *PieProbeTemplate) DeepCopy() *PieProbeTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(PieProbeTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PieProbeTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PieProbeTemplateList) DeepCopyInto(out *PieProbeTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PieProbeTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PieProbeTemplateList.
+func (in *PieProbeTemplateList) DeepCopy() *PieProbeTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(PieProbeTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PieProbeTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PieProbeTemplateSpec) DeepCopyInto(out *PieProbeTemplateSpec) {
+	*out = *in
+	if in.StorageClassSelector != nil {
+		in, out := &in.StorageClassSelector, &out.StorageClassSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExcludedProvisioners != nil {
+		in, out := &in.ExcludedProvisioners, &out.ExcludedProvisioners
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.PieProbeSpec.DeepCopyInto(&out.PieProbeSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PieProbeTemplateSpec.
+func (in *PieProbeTemplateSpec) DeepCopy() *PieProbeTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PieProbeTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PieProbeTemplateStatus) DeepCopyInto(out *PieProbeTemplateStatus) {
+	*out = *in
+	if in.StorageClasses != nil {
+		in, out := &in.StorageClasses, &out.StorageClasses
+		*out = make([]PieProbeTemplateStorageClassStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PieProbeTemplateStatus.
+func (in *PieProbeTemplateStatus) DeepCopy() *PieProbeTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PieProbeTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PieProbeTemplateStorageClassStatus) DeepCopyInto(out *PieProbeTemplateStorageClassStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PieProbeTemplateStorageClassStatus.
+func (in *PieProbeTemplateStorageClassStatus) DeepCopy() *PieProbeTemplateStorageClassStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PieProbeTemplateStorageClassStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedAccessProbeSpec) DeepCopyInto(out *SharedAccessProbeSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(corev1.NodeSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Deadline = in.Deadline
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedAccessProbeSpec.
+func (in *SharedAccessProbeSpec) DeepCopy() *SharedAccessProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedAccessProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotProbeSpec) DeepCopyInto(out *SnapshotProbeSpec) {
+	*out = *in
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotProbeSpec.
+func (in *SnapshotProbeSpec) DeepCopy() *SnapshotProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,143 @@
+package resultv1
+
+import (
+	"time"
+
+	"github.com/topolvm/pie/types"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NewResult converts the result into the message.
+func NewResult(result *types.ResultEnvelope) *Result {
+	msg := &Result{
+		SchemaVersion: result.SchemaVersion,
+		RunId:         result.RunID,
+		Kind:          result.Kind,
+		Identity: &ProbeIdentity{
+			PieProbeName: result.Identity.PieProbeName,
+			Node:         result.Identity.Node,
+			StorageClass: result.Identity.StorageClass,
+			Namespace:    result.Identity.Namespace,
+			PodName:      result.Identity.PodName,
+			PvcName:      result.Identity.PVCName,
+			PvName:       result.Identity.PVName,
+		},
+		StartedAt:  timestamppb.New(result.StartedAt),
+		FinishedAt: timestamppb.New(result.FinishedAt),
+	}
+	for _, measurement := range result.Measurements {
+		var m Measurement
+		switch {
+		case measurement.Type == types.MeasurementTypePerformance && measurement.Performance != nil:
+			p := measurement.Performance
+			m.Measurement = &Measurement_Performance{Performance: &PerformanceMeasurement{
+				VolumeMode:    p.VolumeMode,
+				IoMode:        p.IOMode,
+				Succeeded:     p.Succeeded,
+				FailureReason: p.FailureReason,
+				ReadLatency:   p.ReadLatency,
+				WriteLatency:  p.WriteLatency,
+			}}
+		case measurement.Type == types.MeasurementTypeMount && measurement.Mount != nil:
+			m.Measurement = &Measurement_Mount{Mount: &MountMeasurement{
+				FilesystemType: measurement.Mount.FilesystemType,
+				MountOptions:   measurement.Mount.MountOptions,
+				ReadOnly:       measurement.Mount.ReadOnly,
+			}}
+		case measurement.Type == types.MeasurementTypeFilesystemUsage && measurement.FilesystemUsage != nil:
+			u := measurement.FilesystemUsage
+			m.Measurement = &Measurement_FilesystemUsage{FilesystemUsage: &FilesystemUsageMeasurement{
+				TotalBytes:  u.TotalBytes,
+				FreeBytes:   u.FreeBytes,
+				TotalInodes: u.TotalInodes,
+				FreeInodes:  u.FreeInodes,
+			}}
+		case measurement.Type == types.MeasurementTypeMetadataOperation && measurement.MetadataOperation != nil:
+			o := measurement.MetadataOperation
+			m.Measurement = &Measurement_MetadataOperation{MetadataOperation: &MetadataOperationMeasurement{
+				Operation: o.Operation,
+				Latency:   o.Latency,
+				Errors:    int64(o.Errors),
+			}}
+		default:
+			continue
+		}
+		msg.Measurements = append(msg.Measurements, &m)
+	}
+	return msg
+}
+
+// ToEnvelope converts the message into types.ResultEnvelope.
+// The measurements unknown to this version are dropped.
+func (x *Result) ToEnvelope() *types.ResultEnvelope {
+	identity := x.GetIdentity()
+	result := &types.ResultEnvelope{
+		SchemaVersion: x.GetSchemaVersion(),
+		RunID:         x.GetRunId(),
+		Kind:          x.GetKind(),
+		Identity: types.ProbeIdentity{
+			PieProbeName: identity.GetPieProbeName(),
+			Node:         identity.GetNode(),
+			StorageClass: identity.GetStorageClass(),
+			Namespace:    identity.GetNamespace(),
+			PodName:      identity.GetPodName(),
+			PVCName:      identity.GetPvcName(),
+			PVName:       identity.GetPvName(),
+		},
+		StartedAt:  toTime(x.GetStartedAt()),
+		FinishedAt: toTime(x.GetFinishedAt()),
+	}
+	for _, m := range x.GetMeasurements() {
+		switch v := m.GetMeasurement().(type) {
+		case *Measurement_Performance:
+			result.Measurements = append(result.Measurements, types.Measurement{
+				Type: types.MeasurementTypePerformance,
+				Performance: &types.PerformanceMeasurement{
+					VolumeMode:    v.Performance.GetVolumeMode(),
+					IOMode:        v.Performance.GetIoMode(),
+					Succeeded:     v.Performance.GetSucceeded(),
+					FailureReason: v.Performance.GetFailureReason(),
+					ReadLatency:   v.Performance.GetReadLatency(),
+					WriteLatency:  v.Performance.GetWriteLatency(),
+				},
+			})
+		case *Measurement_Mount:
+			result.Measurements = append(result.Measurements, types.Measurement{
+				Type: types.MeasurementTypeMount,
+				Mount: &types.MountInfo{
+					FilesystemType: v.Mount.GetFilesystemType(),
+					MountOptions:   v.Mount.GetMountOptions(),
+					ReadOnly:       v.Mount.GetReadOnly(),
+				},
+			})
+		case *Measurement_FilesystemUsage:
+			result.Measurements = append(result.Measurements, types.Measurement{
+				Type: types.MeasurementTypeFilesystemUsage,
+				FilesystemUsage: &types.FilesystemUsage{
+					TotalBytes:  v.FilesystemUsage.GetTotalBytes(),
+					FreeBytes:   v.FilesystemUsage.GetFreeBytes(),
+					TotalInodes: v.FilesystemUsage.GetTotalInodes(),
+					FreeInodes:  v.FilesystemUsage.GetFreeInodes(),
+				},
+			})
+		case *Measurement_MetadataOperation:
+			result.Measurements = append(result.Measurements, types.Measurement{
+				Type: types.MeasurementTypeMetadataOperation,
+				MetadataOperation: &types.MetadataOperationResult{
+					Operation: v.MetadataOperation.GetOperation(),
+					Latency:   v.MetadataOperation.GetLatency(),
+					Errors:    int(v.MetadataOperation.GetErrors()),
+				},
+			})
+		}
+	}
+	return result
+}
+
+// toTime returns the zero time if the timestamp is not set.
+func toTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
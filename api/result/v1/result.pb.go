@@ -0,0 +1,913 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: api/result/v1/result.proto
+
+package resultv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ReportResultsResponse_Status int32
+
+const (
+	ReportResultsResponse_STATUS_UNSPECIFIED ReportResultsResponse_Status = 0
+	// The result is recorded.
+	ReportResultsResponse_STATUS_ACCEPTED ReportResultsResponse_Status = 1
+	// The result of the same run ID has been recorded already.
+	ReportResultsResponse_STATUS_DUPLICATE ReportResultsResponse_Status = 2
+	// The result is invalid, which is never accepted if sent again.
+	ReportResultsResponse_STATUS_REJECTED ReportResultsResponse_Status = 3
+)
+
+// Enum value maps for ReportResultsResponse_Status.
+var (
+	ReportResultsResponse_Status_name = map[int32]string{
+		0: "STATUS_UNSPECIFIED",
+		1: "STATUS_ACCEPTED",
+		2: "STATUS_DUPLICATE",
+		3: "STATUS_REJECTED",
+	}
+	ReportResultsResponse_Status_value = map[string]int32{
+		"STATUS_UNSPECIFIED": 0,
+		"STATUS_ACCEPTED":    1,
+		"STATUS_DUPLICATE":   2,
+		"STATUS_REJECTED":    3,
+	}
+)
+
+func (x ReportResultsResponse_Status) Enum() *ReportResultsResponse_Status {
+	p := new(ReportResultsResponse_Status)
+	*p = x
+	return p
+}
+
+func (x ReportResultsResponse_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReportResultsResponse_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_result_v1_result_proto_enumTypes[0].Descriptor()
+}
+
+func (ReportResultsResponse_Status) Type() protoreflect.EnumType {
+	return &file_api_result_v1_result_proto_enumTypes[0]
+}
+
+func (x ReportResultsResponse_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReportResultsResponse_Status.Descriptor instead.
+func (ReportResultsResponse_Status) EnumDescriptor() ([]byte, []int) {
+	return file_api_result_v1_result_proto_rawDescGZIP(), []int{1, 0}
+}
+
+type ReportResultsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        *Result                `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportResultsRequest) Reset() {
+	*x = ReportResultsRequest{}
+	mi := &file_api_result_v1_result_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportResultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportResultsRequest) ProtoMessage() {}
+
+func (x *ReportResultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_result_v1_result_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportResultsRequest.ProtoReflect.Descriptor instead.
+func (*ReportResultsRequest) Descriptor() ([]byte, []int) {
+	return file_api_result_v1_result_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ReportResultsRequest) GetResult() *Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type ReportResultsResponse struct {
+	state  protoimpl.MessageState       `protogen:"open.v1"`
+	RunId  string                       `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	Status ReportResultsResponse_Status `protobuf:"varint,2,opt,name=status,proto3,enum=pie.result.v1.ReportResultsResponse_Status" json:"status,omitempty"`
+	// message tells why the result is rejected.
+	Message       string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportResultsResponse) Reset() {
+	*x = ReportResultsResponse{}
+	mi := &file_api_result_v1_result_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportResultsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportResultsResponse) ProtoMessage() {}
+
+func (x *ReportResultsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_result_v1_result_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportResultsResponse.ProtoReflect.Descriptor instead.
+func (*ReportResultsResponse) Descriptor() ([]byte, []int) {
+	return file_api_result_v1_result_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ReportResultsResponse) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *ReportResultsResponse) GetStatus() ReportResultsResponse_Status {
+	if x != nil {
+		return x.Status
+	}
+	return ReportResultsResponse_STATUS_UNSPECIFIED
+}
+
+func (x *ReportResultsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Result is the result of a run of a probe.
+type Result struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// schema_version is "<major>.<minor>" and its major version is the one of this package.
+	SchemaVersion string `protobuf:"bytes,1,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	// run_id identifies the run of the probe.
+	RunId         string                 `protobuf:"bytes,2,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	Kind          string                 `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`
+	Identity      *ProbeIdentity         `protobuf:"bytes,4,opt,name=identity,proto3" json:"identity,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	Measurements  []*Measurement         `protobuf:"bytes,7,rep,name=measurements,proto3" json:"measurements,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	mi := &file_api_result_v1_result_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_api_result_v1_result_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_api_result_v1_result_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Result) GetSchemaVersion() string {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return ""
+}
+
+func (x *Result) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *Result) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Result) GetIdentity() *ProbeIdentity {
+	if x != nil {
+		return x.Identity
+	}
+	return nil
+}
+
+func (x *Result) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Result) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+func (x *Result) GetMeasurements() []*Measurement {
+	if x != nil {
+		return x.Measurements
+	}
+	return nil
+}
+
+// ProbeIdentity identifies the probe and the volume probed.
+type ProbeIdentity struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	PieProbeName string                 `protobuf:"bytes,1,opt,name=pie_probe_name,json=pieProbeName,proto3" json:"pie_probe_name,omitempty"`
+	Node         string                 `protobuf:"bytes,2,opt,name=node,proto3" json:"node,omitempty"`
+	StorageClass string                 `protobuf:"bytes,3,opt,name=storage_class,json=storageClass,proto3" json:"storage_class,omitempty"`
+	Namespace    string                 `protobuf:"bytes,4,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	PodName      string                 `protobuf:"bytes,5,opt,name=pod_name,json=podName,proto3" json:"pod_name,omitempty"`
+	PvcName      string                 `protobuf:"bytes,6,opt,name=pvc_name,json=pvcName,proto3" json:"pvc_name,omitempty"`
+	// pv_name is empty if the PVC is not bound yet when the probe is scheduled.
+	PvName        string `protobuf:"bytes,7,opt,name=pv_name,json=pvName,proto3" json:"pv_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProbeIdentity) Reset() {
+	*x = ProbeIdentity{}
+	mi := &file_api_result_v1_result_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProbeIdentity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProbeIdentity) ProtoMessage() {}
+
+func (x *ProbeIdentity) ProtoReflect() protoreflect.Message {
+	mi := &file_api_result_v1_result_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProbeIdentity.ProtoReflect.Descriptor instead.
+func (*ProbeIdentity) Descriptor() ([]byte, []int) {
+	return file_api_result_v1_result_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ProbeIdentity) GetPieProbeName() string {
+	if x != nil {
+		return x.PieProbeName
+	}
+	return ""
+}
+
+func (x *ProbeIdentity) GetNode() string {
+	if x != nil {
+		return x.Node
+	}
+	return ""
+}
+
+func (x *ProbeIdentity) GetStorageClass() string {
+	if x != nil {
+		return x.StorageClass
+	}
+	return ""
+}
+
+func (x *ProbeIdentity) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *ProbeIdentity) GetPodName() string {
+	if x != nil {
+		return x.PodName
+	}
+	return ""
+}
+
+func (x *ProbeIdentity) GetPvcName() string {
+	if x != nil {
+		return x.PvcName
+	}
+	return ""
+}
+
+func (x *ProbeIdentity) GetPvName() string {
+	if x != nil {
+		return x.PvName
+	}
+	return ""
+}
+
+// Measurement is a measurement in a result.
+// The receivers ignore the measurements added in the newer minor versions.
+type Measurement struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Measurement:
+	//
+	//	*Measurement_Performance
+	//	*Measurement_Mount
+	//	*Measurement_FilesystemUsage
+	//	*Measurement_MetadataOperation
+	Measurement   isMeasurement_Measurement `protobuf_oneof:"measurement"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Measurement) Reset() {
+	*x = Measurement{}
+	mi := &file_api_result_v1_result_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Measurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Measurement) ProtoMessage() {}
+
+func (x *Measurement) ProtoReflect() protoreflect.Message {
+	mi := &file_api_result_v1_result_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Measurement.ProtoReflect.Descriptor instead.
+func (*Measurement) Descriptor() ([]byte, []int) {
+	return file_api_result_v1_result_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Measurement) GetMeasurement() isMeasurement_Measurement {
+	if x != nil {
+		return x.Measurement
+	}
+	return nil
+}
+
+func (x *Measurement) GetPerformance() *PerformanceMeasurement {
+	if x != nil {
+		if x, ok := x.Measurement.(*Measurement_Performance); ok {
+			return x.Performance
+		}
+	}
+	return nil
+}
+
+func (x *Measurement) GetMount() *MountMeasurement {
+	if x != nil {
+		if x, ok := x.Measurement.(*Measurement_Mount); ok {
+			return x.Mount
+		}
+	}
+	return nil
+}
+
+func (x *Measurement) GetFilesystemUsage() *FilesystemUsageMeasurement {
+	if x != nil {
+		if x, ok := x.Measurement.(*Measurement_FilesystemUsage); ok {
+			return x.FilesystemUsage
+		}
+	}
+	return nil
+}
+
+func (x *Measurement) GetMetadataOperation() *MetadataOperationMeasurement {
+	if x != nil {
+		if x, ok := x.Measurement.(*Measurement_MetadataOperation); ok {
+			return x.MetadataOperation
+		}
+	}
+	return nil
+}
+
+type isMeasurement_Measurement interface {
+	isMeasurement_Measurement()
+}
+
+type Measurement_Performance struct {
+	Performance *PerformanceMeasurement `protobuf:"bytes,1,opt,name=performance,proto3,oneof"`
+}
+
+type Measurement_Mount struct {
+	Mount *MountMeasurement `protobuf:"bytes,2,opt,name=mount,proto3,oneof"`
+}
+
+type Measurement_FilesystemUsage struct {
+	FilesystemUsage *FilesystemUsageMeasurement `protobuf:"bytes,3,opt,name=filesystem_usage,json=filesystemUsage,proto3,oneof"`
+}
+
+type Measurement_MetadataOperation struct {
+	MetadataOperation *MetadataOperationMeasurement `protobuf:"bytes,4,opt,name=metadata_operation,json=metadataOperation,proto3,oneof"`
+}
+
+func (*Measurement_Performance) isMeasurement_Measurement() {}
+
+func (*Measurement_Mount) isMeasurement_Measurement() {}
+
+func (*Measurement_FilesystemUsage) isMeasurement_Measurement() {}
+
+func (*Measurement_MetadataOperation) isMeasurement_Measurement() {}
+
+// PerformanceMeasurement is the result of the benchmark of the volume.
+type PerformanceMeasurement struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	VolumeMode string                 `protobuf:"bytes,1,opt,name=volume_mode,json=volumeMode,proto3" json:"volume_mode,omitempty"`
+	// io_mode is empty if the probe failed before the benchmark.
+	IoMode    string `protobuf:"bytes,2,opt,name=io_mode,json=ioMode,proto3" json:"io_mode,omitempty"`
+	Succeeded bool   `protobuf:"varint,3,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	// failure_reason is the class of the error if the probe failed.
+	FailureReason string `protobuf:"bytes,4,opt,name=failure_reason,json=failureReason,proto3" json:"failure_reason,omitempty"`
+	// read_latency and write_latency are the mean latencies in seconds.
+	ReadLatency   float64 `protobuf:"fixed64,5,opt,name=read_latency,json=readLatency,proto3" json:"read_latency,omitempty"`
+	WriteLatency  float64 `protobuf:"fixed64,6,opt,name=write_latency,json=writeLatency,proto3" json:"write_latency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PerformanceMeasurement) Reset() {
+	*x = PerformanceMeasurement{}
+	mi := &file_api_result_v1_result_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PerformanceMeasurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PerformanceMeasurement) ProtoMessage() {}
+
+func (x *PerformanceMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_api_result_v1_result_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PerformanceMeasurement.ProtoReflect.Descriptor instead.
+func (*PerformanceMeasurement) Descriptor() ([]byte, []int) {
+	return file_api_result_v1_result_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PerformanceMeasurement) GetVolumeMode() string {
+	if x != nil {
+		return x.VolumeMode
+	}
+	return ""
+}
+
+func (x *PerformanceMeasurement) GetIoMode() string {
+	if x != nil {
+		return x.IoMode
+	}
+	return ""
+}
+
+func (x *PerformanceMeasurement) GetSucceeded() bool {
+	if x != nil {
+		return x.Succeeded
+	}
+	return false
+}
+
+func (x *PerformanceMeasurement) GetFailureReason() string {
+	if x != nil {
+		return x.FailureReason
+	}
+	return ""
+}
+
+func (x *PerformanceMeasurement) GetReadLatency() float64 {
+	if x != nil {
+		return x.ReadLatency
+	}
+	return 0
+}
+
+func (x *PerformanceMeasurement) GetWriteLatency() float64 {
+	if x != nil {
+		return x.WriteLatency
+	}
+	return 0
+}
+
+// MountMeasurement is the mount of the filesystem probed.
+type MountMeasurement struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	FilesystemType string                 `protobuf:"bytes,1,opt,name=filesystem_type,json=filesystemType,proto3" json:"filesystem_type,omitempty"`
+	MountOptions   []string               `protobuf:"bytes,2,rep,name=mount_options,json=mountOptions,proto3" json:"mount_options,omitempty"`
+	ReadOnly       bool                   `protobuf:"varint,3,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *MountMeasurement) Reset() {
+	*x = MountMeasurement{}
+	mi := &file_api_result_v1_result_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MountMeasurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MountMeasurement) ProtoMessage() {}
+
+func (x *MountMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_api_result_v1_result_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MountMeasurement.ProtoReflect.Descriptor instead.
+func (*MountMeasurement) Descriptor() ([]byte, []int) {
+	return file_api_result_v1_result_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MountMeasurement) GetFilesystemType() string {
+	if x != nil {
+		return x.FilesystemType
+	}
+	return ""
+}
+
+func (x *MountMeasurement) GetMountOptions() []string {
+	if x != nil {
+		return x.MountOptions
+	}
+	return nil
+}
+
+func (x *MountMeasurement) GetReadOnly() bool {
+	if x != nil {
+		return x.ReadOnly
+	}
+	return false
+}
+
+// FilesystemUsageMeasurement is the capacity and the inodes of the filesystem probed.
+type FilesystemUsageMeasurement struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TotalBytes    uint64                 `protobuf:"varint,1,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	FreeBytes     uint64                 `protobuf:"varint,2,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"`
+	TotalInodes   uint64                 `protobuf:"varint,3,opt,name=total_inodes,json=totalInodes,proto3" json:"total_inodes,omitempty"`
+	FreeInodes    uint64                 `protobuf:"varint,4,opt,name=free_inodes,json=freeInodes,proto3" json:"free_inodes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FilesystemUsageMeasurement) Reset() {
+	*x = FilesystemUsageMeasurement{}
+	mi := &file_api_result_v1_result_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FilesystemUsageMeasurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilesystemUsageMeasurement) ProtoMessage() {}
+
+func (x *FilesystemUsageMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_api_result_v1_result_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilesystemUsageMeasurement.ProtoReflect.Descriptor instead.
+func (*FilesystemUsageMeasurement) Descriptor() ([]byte, []int) {
+	return file_api_result_v1_result_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FilesystemUsageMeasurement) GetTotalBytes() uint64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *FilesystemUsageMeasurement) GetFreeBytes() uint64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *FilesystemUsageMeasurement) GetTotalInodes() uint64 {
+	if x != nil {
+		return x.TotalInodes
+	}
+	return 0
+}
+
+func (x *FilesystemUsageMeasurement) GetFreeInodes() uint64 {
+	if x != nil {
+		return x.FreeInodes
+	}
+	return 0
+}
+
+// MetadataOperationMeasurement is the result of an operation of the metadata benchmark.
+type MetadataOperationMeasurement struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Operation string                 `protobuf:"bytes,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	// latency is the mean latency in seconds of the succeeded operations. It is 0 if none of them succeeded.
+	Latency       float64 `protobuf:"fixed64,2,opt,name=latency,proto3" json:"latency,omitempty"`
+	Errors        int64   `protobuf:"varint,3,opt,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetadataOperationMeasurement) Reset() {
+	*x = MetadataOperationMeasurement{}
+	mi := &file_api_result_v1_result_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetadataOperationMeasurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetadataOperationMeasurement) ProtoMessage() {}
+
+func (x *MetadataOperationMeasurement) ProtoReflect() protoreflect.Message {
+	mi := &file_api_result_v1_result_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetadataOperationMeasurement.ProtoReflect.Descriptor instead.
+func (*MetadataOperationMeasurement) Descriptor() ([]byte, []int) {
+	return file_api_result_v1_result_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *MetadataOperationMeasurement) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *MetadataOperationMeasurement) GetLatency() float64 {
+	if x != nil {
+		return x.Latency
+	}
+	return 0
+}
+
+func (x *MetadataOperationMeasurement) GetErrors() int64 {
+	if x != nil {
+		return x.Errors
+	}
+	return 0
+}
+
+var File_api_result_v1_result_proto protoreflect.FileDescriptor
+
+const file_api_result_v1_result_proto_rawDesc = "" +
+	"\n" +
+	"\x1aapi/result/v1/result.proto\x12\rpie.result.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"E\n" +
+	"\x14ReportResultsRequest\x12-\n" +
+	"\x06result\x18\x01 \x01(\v2\x15.pie.result.v1.ResultR\x06result\"\xef\x01\n" +
+	"\x15ReportResultsResponse\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\tR\x05runId\x12C\n" +
+	"\x06status\x18\x02 \x01(\x0e2+.pie.result.v1.ReportResultsResponse.StatusR\x06status\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"`\n" +
+	"\x06Status\x12\x16\n" +
+	"\x12STATUS_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fSTATUS_ACCEPTED\x10\x01\x12\x14\n" +
+	"\x10STATUS_DUPLICATE\x10\x02\x12\x13\n" +
+	"\x0fSTATUS_REJECTED\x10\x03\"\xcc\x02\n" +
+	"\x06Result\x12%\n" +
+	"\x0eschema_version\x18\x01 \x01(\tR\rschemaVersion\x12\x15\n" +
+	"\x06run_id\x18\x02 \x01(\tR\x05runId\x12\x12\n" +
+	"\x04kind\x18\x03 \x01(\tR\x04kind\x128\n" +
+	"\bidentity\x18\x04 \x01(\v2\x1c.pie.result.v1.ProbeIdentityR\bidentity\x129\n" +
+	"\n" +
+	"started_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x12>\n" +
+	"\fmeasurements\x18\a \x03(\v2\x1a.pie.result.v1.MeasurementR\fmeasurements\"\xdb\x01\n" +
+	"\rProbeIdentity\x12$\n" +
+	"\x0epie_probe_name\x18\x01 \x01(\tR\fpieProbeName\x12\x12\n" +
+	"\x04node\x18\x02 \x01(\tR\x04node\x12#\n" +
+	"\rstorage_class\x18\x03 \x01(\tR\fstorageClass\x12\x1c\n" +
+	"\tnamespace\x18\x04 \x01(\tR\tnamespace\x12\x19\n" +
+	"\bpod_name\x18\x05 \x01(\tR\apodName\x12\x19\n" +
+	"\bpvc_name\x18\x06 \x01(\tR\apvcName\x12\x17\n" +
+	"\apv_name\x18\a \x01(\tR\x06pvName\"\xd6\x02\n" +
+	"\vMeasurement\x12I\n" +
+	"\vperformance\x18\x01 \x01(\v2%.pie.result.v1.PerformanceMeasurementH\x00R\vperformance\x127\n" +
+	"\x05mount\x18\x02 \x01(\v2\x1f.pie.result.v1.MountMeasurementH\x00R\x05mount\x12V\n" +
+	"\x10filesystem_usage\x18\x03 \x01(\v2).pie.result.v1.FilesystemUsageMeasurementH\x00R\x0ffilesystemUsage\x12\\\n" +
+	"\x12metadata_operation\x18\x04 \x01(\v2+.pie.result.v1.MetadataOperationMeasurementH\x00R\x11metadataOperationB\r\n" +
+	"\vmeasurement\"\xdf\x01\n" +
+	"\x16PerformanceMeasurement\x12\x1f\n" +
+	"\vvolume_mode\x18\x01 \x01(\tR\n" +
+	"volumeMode\x12\x17\n" +
+	"\aio_mode\x18\x02 \x01(\tR\x06ioMode\x12\x1c\n" +
+	"\tsucceeded\x18\x03 \x01(\bR\tsucceeded\x12%\n" +
+	"\x0efailure_reason\x18\x04 \x01(\tR\rfailureReason\x12!\n" +
+	"\fread_latency\x18\x05 \x01(\x01R\vreadLatency\x12#\n" +
+	"\rwrite_latency\x18\x06 \x01(\x01R\fwriteLatency\"}\n" +
+	"\x10MountMeasurement\x12'\n" +
+	"\x0ffilesystem_type\x18\x01 \x01(\tR\x0efilesystemType\x12#\n" +
+	"\rmount_options\x18\x02 \x03(\tR\fmountOptions\x12\x1b\n" +
+	"\tread_only\x18\x03 \x01(\bR\breadOnly\"\xa0\x01\n" +
+	"\x1aFilesystemUsageMeasurement\x12\x1f\n" +
+	"\vtotal_bytes\x18\x01 \x01(\x04R\n" +
+	"totalBytes\x12\x1d\n" +
+	"\n" +
+	"free_bytes\x18\x02 \x01(\x04R\tfreeBytes\x12!\n" +
+	"\ftotal_inodes\x18\x03 \x01(\x04R\vtotalInodes\x12\x1f\n" +
+	"\vfree_inodes\x18\x04 \x01(\x04R\n" +
+	"freeInodes\"n\n" +
+	"\x1cMetadataOperationMeasurement\x12\x1c\n" +
+	"\toperation\x18\x01 \x01(\tR\toperation\x12\x18\n" +
+	"\alatency\x18\x02 \x01(\x01R\alatency\x12\x16\n" +
+	"\x06errors\x18\x03 \x01(\x03R\x06errors2o\n" +
+	"\rResultService\x12^\n" +
+	"\rReportResults\x12#.pie.result.v1.ReportResultsRequest\x1a$.pie.result.v1.ReportResultsResponse(\x010\x01B/Z-github.com/topolvm/pie/api/result/v1;resultv1b\x06proto3"
+
+var (
+	file_api_result_v1_result_proto_rawDescOnce sync.Once
+	file_api_result_v1_result_proto_rawDescData []byte
+)
+
+func file_api_result_v1_result_proto_rawDescGZIP() []byte {
+	file_api_result_v1_result_proto_rawDescOnce.Do(func() {
+		file_api_result_v1_result_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_result_v1_result_proto_rawDesc), len(file_api_result_v1_result_proto_rawDesc)))
+	})
+	return file_api_result_v1_result_proto_rawDescData
+}
+
+var file_api_result_v1_result_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_result_v1_result_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_api_result_v1_result_proto_goTypes = []any{
+	(ReportResultsResponse_Status)(0),    // 0: pie.result.v1.ReportResultsResponse.Status
+	(*ReportResultsRequest)(nil),         // 1: pie.result.v1.ReportResultsRequest
+	(*ReportResultsResponse)(nil),        // 2: pie.result.v1.ReportResultsResponse
+	(*Result)(nil),                       // 3: pie.result.v1.Result
+	(*ProbeIdentity)(nil),                // 4: pie.result.v1.ProbeIdentity
+	(*Measurement)(nil),                  // 5: pie.result.v1.Measurement
+	(*PerformanceMeasurement)(nil),       // 6: pie.result.v1.PerformanceMeasurement
+	(*MountMeasurement)(nil),             // 7: pie.result.v1.MountMeasurement
+	(*FilesystemUsageMeasurement)(nil),   // 8: pie.result.v1.FilesystemUsageMeasurement
+	(*MetadataOperationMeasurement)(nil), // 9: pie.result.v1.MetadataOperationMeasurement
+	(*timestamppb.Timestamp)(nil),        // 10: google.protobuf.Timestamp
+}
+var file_api_result_v1_result_proto_depIdxs = []int32{
+	3,  // 0: pie.result.v1.ReportResultsRequest.result:type_name -> pie.result.v1.Result
+	0,  // 1: pie.result.v1.ReportResultsResponse.status:type_name -> pie.result.v1.ReportResultsResponse.Status
+	4,  // 2: pie.result.v1.Result.identity:type_name -> pie.result.v1.ProbeIdentity
+	10, // 3: pie.result.v1.Result.started_at:type_name -> google.protobuf.Timestamp
+	10, // 4: pie.result.v1.Result.finished_at:type_name -> google.protobuf.Timestamp
+	5,  // 5: pie.result.v1.Result.measurements:type_name -> pie.result.v1.Measurement
+	6,  // 6: pie.result.v1.Measurement.performance:type_name -> pie.result.v1.PerformanceMeasurement
+	7,  // 7: pie.result.v1.Measurement.mount:type_name -> pie.result.v1.MountMeasurement
+	8,  // 8: pie.result.v1.Measurement.filesystem_usage:type_name -> pie.result.v1.FilesystemUsageMeasurement
+	9,  // 9: pie.result.v1.Measurement.metadata_operation:type_name -> pie.result.v1.MetadataOperationMeasurement
+	1,  // 10: pie.result.v1.ResultService.ReportResults:input_type -> pie.result.v1.ReportResultsRequest
+	2,  // 11: pie.result.v1.ResultService.ReportResults:output_type -> pie.result.v1.ReportResultsResponse
+	11, // [11:12] is the sub-list for method output_type
+	10, // [10:11] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_api_result_v1_result_proto_init() }
+func file_api_result_v1_result_proto_init() {
+	if File_api_result_v1_result_proto != nil {
+		return
+	}
+	file_api_result_v1_result_proto_msgTypes[4].OneofWrappers = []any{
+		(*Measurement_Performance)(nil),
+		(*Measurement_Mount)(nil),
+		(*Measurement_FilesystemUsage)(nil),
+		(*Measurement_MetadataOperation)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_result_v1_result_proto_rawDesc), len(file_api_result_v1_result_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_result_v1_result_proto_goTypes,
+		DependencyIndexes: file_api_result_v1_result_proto_depIdxs,
+		EnumInfos:         file_api_result_v1_result_proto_enumTypes,
+		MessageInfos:      file_api_result_v1_result_proto_msgTypes,
+	}.Build()
+	File_api_result_v1_result_proto = out.File
+	file_api_result_v1_result_proto_goTypes = nil
+	file_api_result_v1_result_proto_depIdxs = nil
+}
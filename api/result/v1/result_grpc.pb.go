@@ -0,0 +1,123 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: api/result/v1/result.proto
+
+package resultv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ResultService_ReportResults_FullMethodName = "/pie.result.v1.ResultService/ReportResults"
+)
+
+// ResultServiceClient is the client API for ResultService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ResultService receives the results of the probes.
+type ResultServiceClient interface {
+	// ReportResults receives the results of the mount probes.
+	// Each result is acknowledged in the order received.
+	ReportResults(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ReportResultsRequest, ReportResultsResponse], error)
+}
+
+type resultServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewResultServiceClient(cc grpc.ClientConnInterface) ResultServiceClient {
+	return &resultServiceClient{cc}
+}
+
+func (c *resultServiceClient) ReportResults(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ReportResultsRequest, ReportResultsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ResultService_ServiceDesc.Streams[0], ResultService_ReportResults_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ReportResultsRequest, ReportResultsResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ResultService_ReportResultsClient = grpc.BidiStreamingClient[ReportResultsRequest, ReportResultsResponse]
+
+// ResultServiceServer is the server API for ResultService service.
+// All implementations must embed UnimplementedResultServiceServer
+// for forward compatibility.
+//
+// ResultService receives the results of the probes.
+type ResultServiceServer interface {
+	// ReportResults receives the results of the mount probes.
+	// Each result is acknowledged in the order received.
+	ReportResults(grpc.BidiStreamingServer[ReportResultsRequest, ReportResultsResponse]) error
+	mustEmbedUnimplementedResultServiceServer()
+}
+
+// UnimplementedResultServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedResultServiceServer struct{}
+
+func (UnimplementedResultServiceServer) ReportResults(grpc.BidiStreamingServer[ReportResultsRequest, ReportResultsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ReportResults not implemented")
+}
+func (UnimplementedResultServiceServer) mustEmbedUnimplementedResultServiceServer() {}
+func (UnimplementedResultServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeResultServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ResultServiceServer will
+// result in compilation errors.
+type UnsafeResultServiceServer interface {
+	mustEmbedUnimplementedResultServiceServer()
+}
+
+func RegisterResultServiceServer(s grpc.ServiceRegistrar, srv ResultServiceServer) {
+	// If the following call pancis, it indicates UnimplementedResultServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ResultService_ServiceDesc, srv)
+}
+
+func _ResultService_ReportResults_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ResultServiceServer).ReportResults(&grpc.GenericServerStream[ReportResultsRequest, ReportResultsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ResultService_ReportResultsServer = grpc.BidiStreamingServer[ReportResultsRequest, ReportResultsResponse]
+
+// ResultService_ServiceDesc is the grpc.ServiceDesc for ResultService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ResultService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pie.result.v1.ResultService",
+	HandlerType: (*ResultServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ReportResults",
+			Handler:       _ResultService_ReportResults_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "api/result/v1/result.proto",
+}
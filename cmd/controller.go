@@ -4,15 +4,19 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"net"
 	"net/http"
 	"net/http/pprof"
 	"time"
 
 	"github.com/spf13/cobra"
 	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	resultv1 "github.com/topolvm/pie/api/result/v1"
 	"github.com/topolvm/pie/internal/controller"
 	"github.com/topolvm/pie/internal/controller/pie"
 	"github.com/topolvm/pie/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -26,6 +30,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
+// maxGRPCMessageSize is the size limit of the messages received by the gRPC receiver.
+const maxGRPCMessageSize = 64 << 10
+
 var controllerCmd = &cobra.Command{
 	Use: "controller",
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -42,7 +49,11 @@ var (
 	healthProbeAddr      string
 	containerImage       string
 	namespace            string
+	watchNamespaces      []string
+	watchAllNamespaces   bool
 	controllerURL        string
+	controllerGRPCAddr   string
+	grpcBindAddr         string
 	enablePProf          bool
 
 	opts zap.Options
@@ -62,7 +73,15 @@ func init() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flags.StringVar(&containerImage, "container-image", "", "The container image for pie.")
 	flags.StringVar(&namespace, "namespace", "", "The namespace which the controller uses.")
+	flags.StringSliceVar(&watchNamespaces, "watch-namespaces", nil,
+		"The namespaces which the controller watches in addition to --namespace.")
+	flags.BoolVar(&watchAllNamespaces, "watch-all-namespaces", false,
+		"Watch all namespaces. --watch-namespaces is ignored if it is set.")
 	flags.StringVar(&controllerURL, "controller-url", "", "The controller URL which probe pods access")
+	flags.StringVar(&controllerGRPCAddr, "controller-grpc-address", "",
+		"The controller gRPC address which probe pods access if the GRPC resultTransport is used")
+	flags.StringVar(&grpcBindAddr, "grpc-bind-address", "",
+		"The address the gRPC receiver binds to. The gRPC receiver is disabled if it is empty.")
 	flags.BoolVar(&enablePProf, "enable-pprof", false, "Enable PProf function")
 	opts.Development = true
 
@@ -91,6 +110,17 @@ func subMain() error {
 		}
 	}
 
+	// watchedNamespaces is nil if the controller watches all namespaces.
+	var watchedNamespaces []string
+	cacheOptions := cache.Options{}
+	if !watchAllNamespaces {
+		watchedNamespaces = append([]string{namespace}, watchNamespaces...)
+		cacheOptions.DefaultNamespaces = map[string]cache.Config{}
+		for _, ns := range watchedNamespaces {
+			cacheOptions.DefaultNamespaces[ns] = cache.Config{}
+		}
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsOption,
@@ -98,11 +128,7 @@ func subMain() error {
 		HealthProbeBindAddress: healthProbeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "650e0359.topolvm.io", // This is just a unique string. The value itself has no meaning.
-		Cache: cache.Options{
-			DefaultNamespaces: map[string]cache.Config{
-				namespace: {},
-			},
-		},
+		Cache:                  cacheOptions,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -110,25 +136,20 @@ func subMain() error {
 	}
 
 	exporter := metrics.NewMetrics()
-	err = mgr.Add(makeReceiveRunner(exporter))
-	if err != nil {
-		setupLog.Error(err, "unable to start receiverRunner")
-		return err
-	}
-
 	if containerImage == "" {
 		err = errors.New("container image empty")
 		setupLog.Error(err, "the container image should be specified")
 		return err
 	}
 
-	probePodReconciler := controller.NewProbePodReconciler(
+	probePVReconciler := controller.NewProbePVReconciler(
 		mgr.GetClient(),
 		exporter,
+		watchedNamespaces,
 	)
-	err = probePodReconciler.SetupWithManager(mgr)
+	err = probePVReconciler.SetupWithManager(mgr)
 	if err != nil {
-		setupLog.Error(err, "unable to start probePodReconciler")
+		setupLog.Error(err, "unable to start probePVReconciler")
 		return err
 	}
 
@@ -138,10 +159,18 @@ func subMain() error {
 		return err
 	}
 
+	if controllerGRPCAddr != "" && grpcBindAddr == "" {
+		err = errors.New("empty grpcBindAddr")
+		setupLog.Error(err, "the grpcBindAddr should be specified with the controllerGRPCAddr")
+		return err
+	}
+
 	pieProbeController := pie.NewPieProbeController(
 		mgr.GetClient(),
 		containerImage,
 		controllerURL,
+		controllerGRPCAddr,
+		exporter,
 	)
 	err = pieProbeController.SetupWithManager(mgr)
 	if err != nil {
@@ -149,6 +178,52 @@ func subMain() error {
 		return err
 	}
 
+	// The results of the mount probes are delivered to the receiver or read from the termination messages.
+	recorder := metrics.NewResultRecorder(exporter, pieProbeController)
+	err = mgr.Add(makeReceiveRunner(exporter, recorder))
+	if err != nil {
+		setupLog.Error(err, "unable to start receiverRunner")
+		return err
+	}
+	if grpcBindAddr != "" {
+		err = mgr.Add(makeGRPCReceiveRunner(grpcBindAddr, recorder))
+		if err != nil {
+			setupLog.Error(err, "unable to start grpcReceiverRunner")
+			return err
+		}
+	}
+
+	probePodReconciler := controller.NewProbePodReconciler(
+		mgr.GetClient(),
+		exporter,
+		recorder,
+	)
+	err = probePodReconciler.SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to start probePodReconciler")
+		return err
+	}
+
+	pieProbeTemplateController := pie.NewPieProbeTemplateController(
+		mgr.GetClient(),
+		namespace,
+	)
+	err = pieProbeTemplateController.SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to start pieProbeTemplateController")
+		return err
+	}
+
+	clusterPieProbeController := pie.NewClusterPieProbeController(
+		mgr.GetClient(),
+		watchedNamespaces,
+	)
+	err = clusterPieProbeController.SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to start clusterPieProbeController")
+		return err
+	}
+
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -169,9 +244,9 @@ func subMain() error {
 	return nil
 }
 
-func makeReceiveRunner(exporter metrics.MetricsExporter) manager.Runnable {
+func makeReceiveRunner(exporter metrics.MetricsExporter, recorder *metrics.ResultRecorder) manager.Runnable {
 	return manager.RunnableFunc(func(ctx context.Context) error {
-		handler := metrics.NewReceiver(exporter)
+		handler := metrics.NewReceiver(exporter, recorder)
 		s := &http.Server{
 			Addr:           ":8082",
 			Handler:        handler,
@@ -190,3 +265,29 @@ func makeReceiveRunner(exporter metrics.MetricsExporter) manager.Runnable {
 		return s.ListenAndServe()
 	})
 }
+
+// makeGRPCReceiveRunner returns the runnable serving the gRPC receiver of the results of the mount probes.
+// The flow control is done by HTTP/2, and the messages larger than any result are refused.
+func makeGRPCReceiveRunner(bindAddr string, recorder *metrics.ResultRecorder) manager.Runnable {
+	return manager.RunnableFunc(func(ctx context.Context) error {
+		s := grpc.NewServer(
+			grpc.MaxRecvMsgSize(maxGRPCMessageSize),
+			grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+				MinTime: 10 * time.Second,
+			}),
+		)
+		resultv1.RegisterResultServiceServer(s, metrics.NewGRPCReceiver(recorder))
+
+		lis, err := net.Listen("tcp", bindAddr)
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			<-ctx.Done()
+			s.Stop()
+		}()
+
+		return s.Serve(lis)
+	})
+}
@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/topolvm/pie/probe"
+	"github.com/topolvm/pie/types"
 )
 
 var probeCmd = &cobra.Command{
@@ -17,12 +23,29 @@ var probeCmd = &cobra.Command{
 			return errors.New("no Storage Class specified")
 		}
 
+		// The result is reported as a timeout if the Pod is terminated while probing.
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+		defer stop()
+
 		return probe.SubMain(
-			probeConfig.pieProbeName,
-			probeConfig.nodeName,
+			ctx,
+			types.ProbeIdentity{
+				PieProbeName: probeConfig.pieProbeName,
+				Node:         probeConfig.nodeName,
+				StorageClass: probeConfig.storageClass,
+				Namespace:    probeConfig.namespace,
+				PodName:      probeConfig.podName,
+				PVCName:      probeConfig.pvcName,
+				PVName:       probeConfig.pvName,
+			},
 			probeConfig.fioFilename,
-			probeConfig.storageClass,
+			probeConfig.devicePath,
 			probeConfig.controllerAddr,
+			probeConfig.grpcControllerAddr,
+			probeConfig.metadataOperations,
+			probeConfig.timeout,
+			probeConfig.spool,
+			probeConfig.terminationMessagePath,
 		)
 	},
 }
@@ -31,8 +54,138 @@ var probeConfig struct {
 	controllerAddr string
 	storageClass   string
 	fioFilename    string
+	devicePath     string
 	nodeName       string
 	pieProbeName   string
+	namespace      string
+	podName        string
+	pvcName        string
+	pvName         string
+
+	metadataOperations int
+	timeout            time.Duration
+	spool              bool
+
+	grpcControllerAddr     string
+	terminationMessagePath string
+}
+
+var verifyCmd = &cobra.Command{
+	Use: "verify",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if verifyConfig.devicePath != "" {
+			return probe.VerifyKnownBlock(
+				verifyConfig.devicePath,
+				verifyConfig.pieProbeName,
+				verifyConfig.nodeName,
+				verifyConfig.storageClass,
+			)
+		}
+		return probe.VerifyKnownFile(
+			verifyConfig.path,
+			verifyConfig.pieProbeName,
+			verifyConfig.nodeName,
+			verifyConfig.storageClass,
+		)
+	},
+}
+
+var verifyConfig struct {
+	path         string
+	devicePath   string
+	storageClass string
+	nodeName     string
+	pieProbeName string
+}
+
+var writeCmd = &cobra.Command{
+	Use: "write",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		if writeConfig.devicePath != "" {
+			err = probe.WriteKnownBlock(
+				writeConfig.devicePath,
+				writeConfig.pieProbeName,
+				writeConfig.nodeName,
+				writeConfig.storageClass,
+			)
+		} else {
+			err = probe.WriteKnownFile(
+				writeConfig.path,
+				writeConfig.pieProbeName,
+				writeConfig.nodeName,
+				writeConfig.storageClass,
+			)
+		}
+		if err != nil || !writeConfig.hold {
+			return err
+		}
+
+		// Keep the volume in use until the Pod is deleted.
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+		defer stop()
+		<-ctx.Done()
+		return nil
+	},
+}
+
+var writeConfig struct {
+	path         string
+	devicePath   string
+	storageClass string
+	nodeName     string
+	pieProbeName string
+	hold         bool
+}
+
+var waitExpansionCmd = &cobra.Command{
+	Use: "wait-expansion",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if waitExpansionConfig.devicePath != "" {
+			return probe.WaitForDeviceExpansion(waitExpansionConfig.devicePath, waitExpansionConfig.readyFile)
+		}
+		return probe.WaitForFilesystemExpansion(waitExpansionConfig.path, waitExpansionConfig.readyFile)
+	},
+}
+
+var waitExpansionConfig struct {
+	path       string
+	devicePath string
+	readyFile  string
+}
+
+var sharedAccessCmd = &cobra.Command{
+	Use: "shared-access",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if sharedAccessConfig.nodeName == "" {
+			return errors.New("no node name specified")
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+		defer stop()
+
+		return probe.SharedAccessMain(
+			ctx,
+			sharedAccessConfig.namespace,
+			sharedAccessConfig.pieProbeName,
+			sharedAccessConfig.nodeName,
+			sharedAccessConfig.storageClass,
+			sharedAccessConfig.path,
+			sharedAccessConfig.controllerAddr,
+			sharedAccessConfig.nodes,
+			sharedAccessConfig.deadline,
+		)
+	},
+}
+
+var sharedAccessConfig struct {
+	controllerAddr string
+	path           string
+	storageClass   string
+	nodeName       string
+	pieProbeName   string
+	namespace      string
+	nodes          []string
+	deadline       time.Duration
 }
 
 var provisionProbeCmd = &cobra.Command{
@@ -51,11 +204,68 @@ func init() {
 		"http://localhost:8080",
 		"metrics aggregator's address",
 	)
+	fs.StringVar(&probeConfig.grpcControllerAddr, "grpc-destination-address", "",
+		"metrics aggregator's gRPC address, to which the result is streamed instead of --destination-address")
 	fs.StringVar(&probeConfig.storageClass, "storage-class", "", "target StorageClass name")
 	fs.StringVar(&probeConfig.fioFilename, "path", "/test", "target I/O test directory path")
+	fs.StringVar(&probeConfig.devicePath, "device-path", "", "target raw block device path, which overrides --path")
 	fs.StringVar(&probeConfig.nodeName, "node-name", "", "node name")
 	fs.StringVar(&probeConfig.pieProbeName, "pie-probe-name", "", "pie probe name")
+	fs.StringVar(&probeConfig.namespace, "namespace", "", "namespace of the probe Pod reported with the result")
+	fs.StringVar(&probeConfig.podName, "pod-name", "", "name of the probe Pod reported with the result")
+	fs.StringVar(&probeConfig.pvcName, "pvc-name", "", "name of the target PVC reported with the result")
+	fs.StringVar(&probeConfig.pvName, "pv-name", "", "name of the target PV reported with the result")
+	fs.IntVar(&probeConfig.metadataOperations, "metadata-operations", 0,
+		"number of files created in the metadata benchmark, which is disabled if 0")
+	fs.DurationVar(&probeConfig.timeout, "timeout", 3*time.Minute,
+		"time limit of the probe, after which the result is reported as a timeout")
+	fs.BoolVar(&probeConfig.spool, "spool", false,
+		"spool the result on the volume if it is not delivered, and replay it on the next run")
+	fs.StringVar(&probeConfig.terminationMessagePath, "termination-message-path", "",
+		"write the result to this termination message path instead of sending it to --destination-address")
 	rootCmd.AddCommand(probeCmd)
 
+	fs = verifyCmd.Flags()
+	fs.StringVar(&verifyConfig.path, "path", "/test", "target directory path")
+	fs.StringVar(&verifyConfig.devicePath, "device-path", "", "target raw block device path, which overrides --path")
+	fs.StringVar(&verifyConfig.storageClass, "storage-class", "", "StorageClass name of the source volume")
+	fs.StringVar(&verifyConfig.nodeName, "node-name", "", "node name of the source volume")
+	fs.StringVar(&verifyConfig.pieProbeName, "pie-probe-name", "", "pie probe name")
+	rootCmd.AddCommand(verifyCmd)
+
+	fs = writeCmd.Flags()
+	fs.StringVar(&writeConfig.path, "path", "/test", "target directory path")
+	fs.StringVar(&writeConfig.devicePath, "device-path", "", "target raw block device path, which overrides --path")
+	fs.StringVar(&writeConfig.storageClass, "storage-class", "", "target StorageClass name")
+	fs.StringVar(&writeConfig.nodeName, "node-name", "", "node name")
+	fs.StringVar(&writeConfig.pieProbeName, "pie-probe-name", "", "pie probe name")
+	fs.BoolVar(&writeConfig.hold, "hold", false, "keep running until terminated after writing")
+	rootCmd.AddCommand(writeCmd)
+
+	fs = waitExpansionCmd.Flags()
+	fs.StringVar(&waitExpansionConfig.path, "path", "/test", "target directory path")
+	fs.StringVar(&waitExpansionConfig.devicePath, "device-path", "",
+		"target raw block device path, which overrides --path")
+	fs.StringVar(&waitExpansionConfig.readyFile, "ready-file", "",
+		"file created after the initial size is recorded, which the readiness probe checks")
+	rootCmd.AddCommand(waitExpansionCmd)
+
+	fs = sharedAccessCmd.Flags()
+	fs.StringVar(
+		&sharedAccessConfig.controllerAddr,
+		"destination-address",
+		"http://localhost:8080",
+		"metrics aggregator's address",
+	)
+	fs.StringVar(&sharedAccessConfig.path, "path", "/test", "target directory path")
+	fs.StringVar(&sharedAccessConfig.storageClass, "storage-class", "", "target StorageClass name")
+	fs.StringVar(&sharedAccessConfig.nodeName, "node-name", "", "node name")
+	fs.StringVar(&sharedAccessConfig.pieProbeName, "pie-probe-name", "", "pie probe name")
+	fs.StringVar(&sharedAccessConfig.namespace, "namespace", "", "namespace of the pie probe")
+	fs.StringSliceVar(&sharedAccessConfig.nodes, "nodes", nil, "names of the nodes sharing the volume")
+	fs.DurationVar(&sharedAccessConfig.deadline, "deadline", time.Minute,
+		"time limit for the files written on the other nodes to be visible")
+	rootCmd.AddCommand(sharedAccessCmd)
+
 	rootCmd.AddCommand(provisionProbeCmd)
 }
@@ -1,13 +1,43 @@
 package constants
 
 const (
-	ProvisionProbeNamePrefix = "provision"
-	MountProbeNamePrefix     = "mount"
-	ProbeContainerName       = "probe"
-	PodFinalizerName         = "pie.topolvm.io/pod"
-	PVCNamePrefix            = "pie-pvc"
+	ProvisionProbeNamePrefix    = "provision"
+	MountProbeNamePrefix        = "mount"
+	SnapshotProbeNamePrefix     = "snapshot"
+	ExpansionProbeNamePrefix    = "expansion"
+	CloneProbeNamePrefix        = "clone"
+	SharedAccessProbeNamePrefix = "shared-access"
+	FailoverProbeNamePrefix     = "failover"
+	ProbeContainerName          = "probe"
+	PodFinalizerName            = "pie.topolvm.io/pod"
+	PieProbeFinalizerName       = "pie.topolvm.io/pieprobe"
+	PVCNamePrefix               = "pie-pvc"
 
 	ProbeNodeLabelKey         = "node"
 	ProbeStorageClassLabelKey = "storage-class"
 	ProbePieProbeLabelKey     = "pie-probe"
+	ProbeTopologyLabelKey     = "topology"
+	ProbeVolumeModeLabelKey   = "volume-mode"
+
+	// StorageClassIgnoreAnnotationKey is the annotation to exclude a StorageClass from PieProbeTemplates.
+	StorageClassIgnoreAnnotationKey = "pie.topolvm.io/ignore"
+
+	// FailoverSourceNodeAnnotationKey and FailoverDestinationNodeAnnotationKey are the annotations
+	// of the PVC of the failover probe recording the nodes between which it is moved.
+	FailoverSourceNodeAnnotationKey      = "pie.topolvm.io/failover-source-node"
+	FailoverDestinationNodeAnnotationKey = "pie.topolvm.io/failover-destination-node"
+
+	// ResultRecordedAnnotationKey is the annotation of the mount probe Pod recording the run ID of
+	// the result read from its termination message, so that the result is never recorded twice.
+	ResultRecordedAnnotationKey = "pie.topolvm.io/result-recorded"
+
+	// MountProbeSuspendersAnnotationKey is the annotation of the mount-probe CronJob listing the reasons,
+	// separated by commas, why the controller suspended it. The controller resumes only the CronJobs it suspended.
+	MountProbeSuspendersAnnotationKey = "pie.topolvm.io/suspended-by"
+
+	// PVCRotationDeletedAtAnnotationKey and PVCRotationRebindingAnnotationKey are the annotations
+	// of the mount-probe CronJob recording the time when its PVC was deleted on the rotation
+	// and that the recreated PVC is not bound yet.
+	PVCRotationDeletedAtAnnotationKey = "pie.topolvm.io/pvc-rotation-deleted-at"
+	PVCRotationRebindingAnnotationKey = "pie.topolvm.io/pvc-rotation-rebinding"
 )
@@ -0,0 +1,240 @@
+package pie
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	"github.com/topolvm/pie/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	cloneProbeStageCloneBound   = "clone_bound"
+	cloneProbeStageDataVerified = "data_verified"
+)
+
+// getCloneProbeName returns the name of the cloned PVC and the verification Job of the clone probe.
+// They share the same name.
+func getCloneProbeName(nodeName string, pieProbe *piev1alpha1.PieProbe, storageClass string) (string, error) {
+	return getStagedProbeName(constants.CloneProbeNamePrefix, nodeName, pieProbe, storageClass)
+}
+
+// reconcileCloneProbe advances the clone probe of the node by a step.
+// It returns the duration after which the probe should be checked again.
+func (r *PieProbeReconciler) reconcileCloneProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+) (time.Duration, error) {
+	logger := log.FromContext(ctx)
+
+	name, err := getCloneProbeName(nodeName, pieProbe, storageClass)
+	if err != nil {
+		return 0, err
+	}
+	key := types.NamespacedName{Namespace: pieProbe.GetNamespace(), Name: name}
+	period := time.Duration(pieProbe.Spec.CloneProbe.Period) * time.Minute
+
+	run, lastRunTime, hasRun := r.getStagedProbeRun(key)
+
+	if run == nil {
+		if hasRun && time.Since(lastRunTime) < period {
+			return period - time.Since(lastRunTime), nil
+		}
+		return r.startCloneProbe(ctx, pieProbe, storageClass, nodeName, key)
+	}
+
+	if time.Since(run.stageStartTime) >= pieProbe.Spec.CloneProbe.Timeout.Duration {
+		logger.Info("clone probe timed out", "name", name, "stage", run.stage)
+		return r.finishCloneProbe(ctx, pieProbe, storageClass, nodeName, key, run, false)
+	}
+
+	switch run.stage {
+	case cloneProbeStageCloneBound:
+		var pvc corev1.PersistentVolumeClaim
+		err := r.client.Get(ctx, key, &pvc)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return r.finishCloneProbe(ctx, pieProbe, storageClass, nodeName, key, run, false)
+			}
+			return 0, err
+		}
+		if pvc.Status.Phase != corev1.ClaimBound {
+			return stagedProbePollInterval, nil
+		}
+		// The clone is provisioned, so the mount probes may write to the source PVC again.
+		err = r.releaseMountProbe(ctx, pieProbe, storageClass, nodeName, mountProbeSuspenderCloneProbe)
+		if err != nil {
+			return 0, err
+		}
+		r.passCloneProbeStage(pieProbe, storageClass, nodeName, run, cloneProbeStageDataVerified)
+
+	case cloneProbeStageDataVerified:
+		var job batchv1.Job
+		err := r.client.Get(ctx, key, &job)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return r.finishCloneProbe(ctx, pieProbe, storageClass, nodeName, key, run, false)
+			}
+			return 0, err
+		}
+		if finished, succeeded := getJobResult(&job); finished {
+			return r.finishCloneProbe(ctx, pieProbe, storageClass, nodeName, key, run, succeeded)
+		}
+	}
+
+	return stagedProbePollInterval, nil
+}
+
+// startCloneProbe clones the mount-probe PVC and creates a Job to verify the clone.
+// The probe is not started until a mount probe succeeds on the PVC and writes the known file.
+// The mount-probe CronJob is suspended until the clone is bound, and the PVC is cloned
+// only after the running mount probe finishes, so that the PVC is not written during the cloning.
+func (r *PieProbeReconciler) startCloneProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+	key types.NamespacedName,
+) (time.Duration, error) {
+	source, err := r.getWrittenMountProbePVC(ctx, pieProbe, storageClass, nodeName)
+	if err != nil {
+		return 0, err
+	}
+	if source == nil {
+		return stagedProbePollInterval, nil
+	}
+
+	// Clean up the resources left by the previous run, e.g. before the controller restarted.
+	deleted, err := r.cleanupCloneProbe(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if deleted {
+		return stagedProbePollInterval, nil
+	}
+
+	quiesced, err := r.quiesceMountProbe(ctx, pieProbe, storageClass, nodeName, mountProbeSuspenderCloneProbe)
+	if err != nil {
+		return 0, err
+	}
+	if !quiesced {
+		return stagedProbePollInterval, nil
+	}
+
+	// A clone must not be smaller than the source.
+	capacity := *pieProbe.Spec.PVCCapacity
+	if sourceCapacity, ok := source.Status.Capacity[corev1.ResourceStorage]; ok && sourceCapacity.Cmp(capacity) > 0 {
+		capacity = sourceCapacity
+	}
+
+	volumeMode := getVolumeMode(pieProbe)
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+			Labels: map[string]string{
+				constants.ProbeStorageClassLabelKey: storageClass,
+				constants.ProbeNodeLabelKey:         nodeName,
+				constants.ProbePieProbeLabelKey:     pieProbe.GetName(),
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: &storageClass,
+			VolumeMode:       &volumeMode,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceStorage: capacity,
+				},
+			},
+			DataSource: &corev1.TypedLocalObjectReference{
+				Kind: "PersistentVolumeClaim",
+				Name: source.GetName(),
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(pieProbe, pvc, r.client.Scheme()); err != nil {
+		return 0, err
+	}
+	err = r.client.Create(ctx, pvc)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return 0, fmt.Errorf("failed to create PVC '%s' cloned from '%s': %w", key.Name, source.GetName(), err)
+	}
+
+	// The Job is created with the PVC because the PVC may not be bound until it is used by a Pod.
+	err = r.createStagedProbeJob(ctx, pieProbe, storageClass, nodeName, key.Name, key.Name,
+		makeVerifyArgs(pieProbe, storageClass, nodeName))
+	if err != nil {
+		return 0, err
+	}
+
+	r.beginStagedProbe(key, cloneProbeStageCloneBound)
+	return stagedProbePollInterval, nil
+}
+
+// passCloneProbeStage exports the result of the current stage and moves on to the next stage.
+func (r *PieProbeReconciler) passCloneProbeStage(
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+	run *stagedProbeRun,
+	nextStage string,
+) {
+	stage := run.stage
+	duration := r.advanceStagedProbe(run, nextStage)
+	r.exporter.SetCloneProbeDuration(
+		pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClass, stage, duration)
+	r.exporter.IncrementCloneProbeCount(
+		pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClass, stage, true)
+}
+
+// finishCloneProbe exports the result of the last stage and cleans up the resources.
+func (r *PieProbeReconciler) finishCloneProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+	key types.NamespacedName,
+	run *stagedProbeRun,
+	succeed bool,
+) (time.Duration, error) {
+	if succeed {
+		duration := time.Since(run.stageStartTime).Seconds()
+		r.exporter.SetCloneProbeDuration(
+			pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClass, run.stage, duration)
+	}
+	r.exporter.IncrementCloneProbeCount(
+		pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClass, run.stage, succeed)
+
+	// The CronJob is still suspended if the clone failed to be bound.
+	err := r.releaseMountProbe(ctx, pieProbe, storageClass, nodeName, mountProbeSuspenderCloneProbe)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := r.cleanupCloneProbe(ctx, key); err != nil {
+		return 0, err
+	}
+
+	r.endStagedProbe(key)
+	return time.Duration(pieProbe.Spec.CloneProbe.Period) * time.Minute, nil
+}
+
+// cleanupCloneProbe deletes the resources of the clone probe.
+// It returns true if any of them still exists.
+func (r *PieProbeReconciler) cleanupCloneProbe(ctx context.Context, key types.NamespacedName) (bool, error) {
+	return r.deleteStagedProbeObjects(ctx, key,
+		&batchv1.Job{},
+		&corev1.PersistentVolumeClaim{},
+	)
+}
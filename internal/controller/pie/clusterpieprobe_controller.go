@@ -0,0 +1,136 @@
+package pie
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ClusterPieProbeReconciler reconciles a ClusterPieProbe object
+type ClusterPieProbeReconciler struct {
+	client client.Client
+	// watchedNamespaces is nil if the controller watches all namespaces.
+	watchedNamespaces []string
+}
+
+//+kubebuilder:rbac:groups=pie.topolvm.io,resources=clusterpieprobes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=pie.topolvm.io,resources=clusterpieprobes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=pie.topolvm.io,resources=clusterpieprobes/finalizers,verbs=update
+
+// Reconcile creates a PieProbe in the target namespace of the ClusterPieProbe
+// and copies its conditions to the ClusterPieProbe.
+// If the target namespace is not watched, it only reports the condition, because retrying does not help.
+func (r *ClusterPieProbeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	clusterPieProbe := piev1alpha1.ClusterPieProbe{}
+	err := r.client.Get(ctx, client.ObjectKey{Name: req.Name}, &clusterPieProbe)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if clusterPieProbe.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	targetNamespace := clusterPieProbe.Spec.TargetNamespace
+	if r.watchedNamespaces != nil && !slices.Contains(r.watchedNamespaces, targetNamespace) {
+		logger.Info("the target namespace is not watched", "namespace", targetNamespace)
+		return ctrl.Result{}, r.reportTargetNamespaceNotWatched(ctx, &clusterPieProbe)
+	}
+
+	pieProbe, err := r.createOrUpdatePieProbe(ctx, &clusterPieProbe)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if equality.Semantic.DeepEqual(clusterPieProbe.Status.Conditions, pieProbe.Status.Conditions) {
+		return ctrl.Result{}, nil
+	}
+	clusterPieProbe.Status.Conditions = pieProbe.Status.Conditions
+	err = r.client.Status().Update(ctx, &clusterPieProbe)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *ClusterPieProbeReconciler) reportTargetNamespaceNotWatched(
+	ctx context.Context,
+	clusterPieProbe *piev1alpha1.ClusterPieProbe,
+) error {
+	condition := metav1.Condition{
+		Type:   piev1alpha1.ClusterPieProbeConditionTargetNamespaceNotWatched,
+		Status: metav1.ConditionTrue,
+		Reason: "TargetNamespaceNotWatched",
+		Message: fmt.Sprintf("namespace %s is not watched by the controller",
+			clusterPieProbe.Spec.TargetNamespace),
+		ObservedGeneration: clusterPieProbe.GetGeneration(),
+	}
+	if !meta.SetStatusCondition(&clusterPieProbe.Status.Conditions, condition) {
+		return nil
+	}
+	return r.client.Status().Update(ctx, clusterPieProbe)
+}
+
+func (r *ClusterPieProbeReconciler) createOrUpdatePieProbe(
+	ctx context.Context,
+	clusterPieProbe *piev1alpha1.ClusterPieProbe,
+) (*piev1alpha1.PieProbe, error) {
+	logger := log.FromContext(ctx)
+
+	pieProbe := &piev1alpha1.PieProbe{}
+	pieProbe.SetNamespace(clusterPieProbe.Spec.TargetNamespace)
+	pieProbe.SetName(clusterPieProbe.GetName())
+
+	op, err := ctrl.CreateOrUpdate(ctx, r.client, pieProbe, func() error {
+		if !pieProbe.CreationTimestamp.IsZero() && !metav1.IsControlledBy(pieProbe, clusterPieProbe) {
+			return fmt.Errorf("PieProbe %s/%s is not controlled by the ClusterPieProbe",
+				pieProbe.GetNamespace(), pieProbe.GetName())
+		}
+		clusterPieProbe.Spec.PieProbeSpec.DeepCopyInto(&pieProbe.Spec)
+
+		return ctrl.SetControllerReference(clusterPieProbe, pieProbe, r.client.Scheme())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PieProbe '%s/%s': %w",
+			pieProbe.GetNamespace(), pieProbe.GetName(), err)
+	}
+	if op != controllerutil.OperationResultNone {
+		logger.Info(fmt.Sprintf("PieProbe '%s/%s' successfully created: %s",
+			pieProbe.GetNamespace(), pieProbe.GetName(), op))
+	}
+
+	return pieProbe, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterPieProbeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&piev1alpha1.ClusterPieProbe{}).
+		Owns(&piev1alpha1.PieProbe{}).
+		Complete(r)
+}
+
+func NewClusterPieProbeController(
+	client client.Client,
+	watchedNamespaces []string,
+) *ClusterPieProbeReconciler {
+	return &ClusterPieProbeReconciler{
+		client:            client,
+		watchedNamespaces: watchedNamespaces,
+	}
+}
@@ -0,0 +1,157 @@
+package pie
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+var _ = Describe("ClusterPieProbe controller", func() {
+	ctx := context.Background()
+	var stopFunc func()
+
+	newClusterPieProbe := func(name, targetNamespace string) *piev1alpha1.ClusterPieProbe {
+		return &piev1alpha1.ClusterPieProbe{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Spec: piev1alpha1.ClusterPieProbeSpec{
+				TargetNamespace: targetNamespace,
+				PieProbeSpec: piev1alpha1.PieProbeSpec{
+					MonitoringStorageClass: "sc",
+					NodeSelector: corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{
+										Key:      "key1",
+										Operator: corev1.NodeSelectorOpIn,
+										Values:   []string{"value1"},
+									},
+								},
+							},
+						},
+					},
+					ProbePeriod: 1,
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		skipNameValidation := true
+		mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+			Scheme:  scheme,
+			Metrics: metricsserver.Options{BindAddress: "0"},
+			Controller: config.Controller{
+				SkipNameValidation: &skipNameValidation,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, name := range []string{"tenant", "unwatched"} {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+			_, err = ctrl.CreateOrUpdate(ctx, k8sClient, namespace, func() error { return nil })
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		clusterPieProbeReconciler := NewClusterPieProbeController(k8sClient, []string{"default", "tenant"})
+		err = clusterPieProbeReconciler.SetupWithManager(mgr)
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(ctx)
+		stopFunc = cancel
+		go func() {
+			err := mgr.Start(ctx)
+			if err != nil {
+				panic(err)
+			}
+		}()
+		time.Sleep(100 * time.Millisecond)
+	})
+
+	AfterEach(func() {
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+	})
+
+	It("should create a PieProbe in the target namespace", func() {
+		By("creating a ClusterPieProbe")
+		clusterPieProbe := newClusterPieProbe("cluster-pie-probe", "tenant")
+		err := k8sClient.Create(ctx, clusterPieProbe)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the PieProbe is created in the target namespace")
+		Eventually(func(g Gomega) {
+			var pieProbe piev1alpha1.PieProbe
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "cluster-pie-probe"}, &pieProbe)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(metav1.IsControlledBy(&pieProbe, clusterPieProbe)).To(BeTrue())
+			g.Expect(pieProbe.Spec.MonitoringStorageClass).To(Equal("sc"))
+		}).Should(Succeed())
+
+		By("cleaning up")
+		err = k8sClient.Delete(ctx, clusterPieProbe)
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.Delete(ctx, &piev1alpha1.PieProbe{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "cluster-pie-probe"},
+		})
+		Expect(client.IgnoreNotFound(err)).NotTo(HaveOccurred())
+	})
+
+	It("should not create a PieProbe in a namespace not watched", func() {
+		By("creating a ClusterPieProbe targeting a namespace not watched")
+		clusterPieProbe := newClusterPieProbe("cluster-pie-probe-unwatched", "unwatched")
+		err := k8sClient.Create(ctx, clusterPieProbe)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the PieProbe is not created")
+		Consistently(func(g Gomega) {
+			var pieProbe piev1alpha1.PieProbe
+			err := k8sClient.Get(ctx,
+				client.ObjectKey{Namespace: "unwatched", Name: "cluster-pie-probe-unwatched"}, &pieProbe)
+			g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		}, 3*time.Second).Should(Succeed())
+
+		By("checking the ClusterPieProbe reports the namespace is not watched")
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterPieProbe), clusterPieProbe)
+			g.Expect(err).NotTo(HaveOccurred())
+			condition := meta.FindStatusCondition(clusterPieProbe.Status.Conditions,
+				piev1alpha1.ClusterPieProbeConditionTargetNamespaceNotWatched)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			g.Expect(condition.Message).To(ContainSubstring("unwatched"))
+		}).Should(Succeed())
+
+		By("cleaning up")
+		err = k8sClient.Delete(ctx, clusterPieProbe)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject to edit targetNamespace", func() {
+		clusterPieProbe := newClusterPieProbe("cluster-pie-probe-immutable", "tenant")
+		err := k8sClient.Create(ctx, clusterPieProbe)
+		Expect(err).NotTo(HaveOccurred())
+
+		clusterPieProbe.Spec.TargetNamespace = "default"
+		err = k8sClient.Update(ctx, clusterPieProbe)
+		Expect(err).To(HaveOccurred())
+
+		err = k8sClient.Delete(ctx, &piev1alpha1.ClusterPieProbe{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-pie-probe-immutable"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
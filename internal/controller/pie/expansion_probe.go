@@ -0,0 +1,314 @@
+package pie
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	"github.com/topolvm/pie/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	expansionProbeStageMounted            = "mounted"
+	expansionProbeStageVolumeExpanded     = "volume_expanded"
+	expansionProbeStageFilesystemExpanded = "filesystem_expanded"
+)
+
+// expansionProbeReadyFile is created by the expansion probe after it records the initial size of the volume.
+// The Pod of the probe becomes ready only then, so that the PVC is not expanded before.
+const expansionProbeReadyFile = "/tmp/pie-expansion-ready"
+
+// getExpansionProbeName returns the name of the PVC and the Job of the expansion probe.
+// They share the same name.
+func getExpansionProbeName(nodeName string, pieProbe *piev1alpha1.PieProbe, storageClass string) (string, error) {
+	return getStagedProbeName(constants.ExpansionProbeNamePrefix, nodeName, pieProbe, storageClass)
+}
+
+// reconcileExpansionProbes advances the expansion probes of the StorageClass by a step.
+// It returns the duration after which the probes should be checked again.
+func (r *PieProbeReconciler) reconcileExpansionProbes(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	availableNodes map[string]corev1.Node,
+) (time.Duration, error) {
+	logger := log.FromContext(ctx)
+
+	var sc storagev1.StorageClass
+	err := r.client.Get(ctx, client.ObjectKey{Name: storageClass}, &sc)
+	if err != nil {
+		return 0, client.IgnoreNotFound(err)
+	}
+	canExpand := sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion
+
+	expandedCapacity := pieProbe.Spec.ExpansionProbe.ExpandedCapacity
+	if expandedCapacity.Cmp(*pieProbe.Spec.PVCCapacity) <= 0 {
+		logger.Info("skip the expansion probe because expandedCapacity is not larger than pvcCapacity",
+			"expandedCapacity", expandedCapacity.String(), "pvcCapacity", pieProbe.Spec.PVCCapacity.String())
+		return 0, nil
+	}
+
+	var requeueAfter time.Duration
+	for nodeName := range availableNodes {
+		after, err := r.reconcileExpansionProbe(ctx, pieProbe, storageClass, nodeName, canExpand)
+		if err != nil {
+			return 0, err
+		}
+		requeueAfter = minRequeueAfter(requeueAfter, after)
+	}
+	return requeueAfter, nil
+}
+
+// reconcileExpansionProbe advances the expansion probe of the node by a step.
+// A new probe is not started if the StorageClass does not allow volume expansion.
+func (r *PieProbeReconciler) reconcileExpansionProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+	canExpand bool,
+) (time.Duration, error) {
+	logger := log.FromContext(ctx)
+
+	name, err := getExpansionProbeName(nodeName, pieProbe, storageClass)
+	if err != nil {
+		return 0, err
+	}
+	key := types.NamespacedName{Namespace: pieProbe.GetNamespace(), Name: name}
+	period := time.Duration(pieProbe.Spec.ExpansionProbe.Period) * time.Minute
+
+	run, lastRunTime, hasRun := r.getStagedProbeRun(key)
+
+	if run == nil {
+		if !canExpand {
+			logger.Info("skip the expansion probe because the StorageClass does not allow volume expansion",
+				"storageClass", storageClass)
+			return 0, nil
+		}
+		if hasRun && time.Since(lastRunTime) < period {
+			return period - time.Since(lastRunTime), nil
+		}
+		return r.startExpansionProbe(ctx, pieProbe, storageClass, nodeName, key)
+	}
+
+	if time.Since(run.stageStartTime) >= pieProbe.Spec.ExpansionProbe.Timeout.Duration {
+		logger.Info("expansion probe timed out", "name", name, "stage", run.stage)
+		return r.finishExpansionProbe(ctx, pieProbe, storageClass, nodeName, key, run, false)
+	}
+
+	switch run.stage {
+	case expansionProbeStageMounted:
+		var job batchv1.Job
+		err := r.client.Get(ctx, key, &job)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return r.finishExpansionProbe(ctx, pieProbe, storageClass, nodeName, key, run, false)
+			}
+			return 0, err
+		}
+		if finished, _ := getJobResult(&job); finished {
+			// The Job must not finish before the PVC is expanded.
+			return r.finishExpansionProbe(ctx, pieProbe, storageClass, nodeName, key, run, false)
+		}
+		if job.Status.Ready == nil || *job.Status.Ready == 0 {
+			return stagedProbePollInterval, nil
+		}
+
+		var pvc corev1.PersistentVolumeClaim
+		err = r.client.Get(ctx, key, &pvc)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return r.finishExpansionProbe(ctx, pieProbe, storageClass, nodeName, key, run, false)
+			}
+			return 0, err
+		}
+		// The requested size of a PVC can be changed only after it is bound.
+		if pvc.Status.Phase != corev1.ClaimBound {
+			return stagedProbePollInterval, nil
+		}
+		patch := client.MergeFrom(pvc.DeepCopy())
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = getExpansionRequest(pieProbe, &pvc)
+		err = r.client.Patch(ctx, &pvc, patch)
+		if err != nil {
+			return 0, fmt.Errorf("failed to expand PVC '%s': %w", name, err)
+		}
+		r.passExpansionProbeStage(pieProbe, storageClass, nodeName, run, expansionProbeStageVolumeExpanded)
+
+	case expansionProbeStageVolumeExpanded:
+		var pvc corev1.PersistentVolumeClaim
+		err := r.client.Get(ctx, key, &pvc)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return r.finishExpansionProbe(ctx, pieProbe, storageClass, nodeName, key, run, false)
+			}
+			return 0, err
+		}
+		capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]
+		if !ok || capacity.Cmp(pvc.Spec.Resources.Requests[corev1.ResourceStorage]) < 0 {
+			return stagedProbePollInterval, nil
+		}
+		r.passExpansionProbeStage(pieProbe, storageClass, nodeName, run, expansionProbeStageFilesystemExpanded)
+
+	case expansionProbeStageFilesystemExpanded:
+		var job batchv1.Job
+		err := r.client.Get(ctx, key, &job)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return r.finishExpansionProbe(ctx, pieProbe, storageClass, nodeName, key, run, false)
+			}
+			return 0, err
+		}
+		if finished, succeeded := getJobResult(&job); finished {
+			return r.finishExpansionProbe(ctx, pieProbe, storageClass, nodeName, key, run, succeeded)
+		}
+	}
+
+	return stagedProbePollInterval, nil
+}
+
+// startExpansionProbe creates a PVC of pvcCapacity and a Job waiting for its filesystem to be expanded.
+func (r *PieProbeReconciler) startExpansionProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+	key types.NamespacedName,
+) (time.Duration, error) {
+	// Clean up the resources left by the previous run, e.g. before the controller restarted.
+	deleted, err := r.cleanupExpansionProbe(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if deleted {
+		return stagedProbePollInterval, nil
+	}
+
+	volumeMode := getVolumeMode(pieProbe)
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+			Labels: map[string]string{
+				constants.ProbeStorageClassLabelKey: storageClass,
+				constants.ProbeNodeLabelKey:         nodeName,
+				constants.ProbePieProbeLabelKey:     pieProbe.GetName(),
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: &storageClass,
+			VolumeMode:       &volumeMode,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceStorage: *pieProbe.Spec.PVCCapacity,
+				},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(pieProbe, pvc, r.client.Scheme()); err != nil {
+		return 0, err
+	}
+	err = r.client.Create(ctx, pvc)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return 0, fmt.Errorf("failed to create PVC '%s' for the expansion probe: %w", key.Name, err)
+	}
+
+	// The Job waits for the volume to grow beyond its size when the Job started.
+	// The PVC is expanded only after the Pod of the Job becomes ready.
+	job := r.makeStagedProbeJob(pieProbe, storageClass, nodeName, key.Name, key.Name, []string{
+		"wait-expansion",
+		makeTargetArg(pieProbe),
+		fmt.Sprintf("--ready-file=%s", expansionProbeReadyFile),
+	})
+	job.Spec.Template.Spec.Containers[0].ReadinessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"cat", expansionProbeReadyFile},
+			},
+		},
+		PeriodSeconds: 2,
+	}
+	if err := r.createJob(ctx, pieProbe, job); err != nil {
+		return 0, err
+	}
+
+	r.beginStagedProbe(key, expansionProbeStageMounted)
+	return stagedProbePollInterval, nil
+}
+
+// getExpansionRequest returns the capacity to which the PVC of the expansion probe is expanded.
+// The provisioners may round the capacity of the PVC up beyond expandedCapacity,
+// and the PVC is expanded by the difference between expandedCapacity and pvcCapacity beyond its capacity then.
+func getExpansionRequest(pieProbe *piev1alpha1.PieProbe, pvc *corev1.PersistentVolumeClaim) resource.Quantity {
+	capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]
+	if !ok || capacity.Cmp(*pieProbe.Spec.PVCCapacity) <= 0 {
+		return pieProbe.Spec.ExpansionProbe.ExpandedCapacity.DeepCopy()
+	}
+
+	request := capacity.DeepCopy()
+	request.Add(*pieProbe.Spec.ExpansionProbe.ExpandedCapacity)
+	request.Sub(*pieProbe.Spec.PVCCapacity)
+	return request
+}
+
+// passExpansionProbeStage exports the result of the current stage and moves on to the next stage.
+func (r *PieProbeReconciler) passExpansionProbeStage(
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+	run *stagedProbeRun,
+	nextStage string,
+) {
+	stage := run.stage
+	duration := r.advanceStagedProbe(run, nextStage)
+	r.exporter.SetExpansionProbeDuration(
+		pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClass, stage, duration)
+	r.exporter.IncrementExpansionProbeCount(
+		pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClass, stage, true)
+}
+
+// finishExpansionProbe exports the result of the last stage and cleans up the resources.
+func (r *PieProbeReconciler) finishExpansionProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+	key types.NamespacedName,
+	run *stagedProbeRun,
+	succeed bool,
+) (time.Duration, error) {
+	if succeed {
+		duration := time.Since(run.stageStartTime).Seconds()
+		r.exporter.SetExpansionProbeDuration(
+			pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClass, run.stage, duration)
+	}
+	r.exporter.IncrementExpansionProbeCount(
+		pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClass, run.stage, succeed)
+
+	if _, err := r.cleanupExpansionProbe(ctx, key); err != nil {
+		return 0, err
+	}
+
+	r.endStagedProbe(key)
+	return time.Duration(pieProbe.Spec.ExpansionProbe.Period) * time.Minute, nil
+}
+
+// cleanupExpansionProbe deletes the resources of the expansion probe.
+// It returns true if any of them still exists.
+func (r *PieProbeReconciler) cleanupExpansionProbe(ctx context.Context, key types.NamespacedName) (bool, error) {
+	return r.deleteStagedProbeObjects(ctx, key,
+		&batchv1.Job{},
+		&corev1.PersistentVolumeClaim{},
+	)
+}
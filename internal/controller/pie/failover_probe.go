@@ -0,0 +1,336 @@
+package pie
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	"github.com/topolvm/pie/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=volumeattachments,verbs=get;list;watch
+
+const (
+	failoverProbeStageWritten  = "written"
+	failoverProbeStageDetached = "detached"
+	failoverProbeStageAttached = "attached"
+	failoverProbeStageMounted  = "mounted"
+)
+
+// getFailoverProbeName returns the name of the PVC of the failover probe.
+// The Jobs on the source and the destination nodes are named after it.
+func getFailoverProbeName(pieProbe *piev1alpha1.PieProbe, storageClass string) (string, error) {
+	return getStagedProbeName(constants.FailoverProbeNamePrefix, "", pieProbe, storageClass)
+}
+
+func getFailoverSourceJobKey(key types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{Namespace: key.Namespace, Name: key.Name + "-source"}
+}
+
+func getFailoverDestinationJobKey(key types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{Namespace: key.Namespace, Name: key.Name + "-destination"}
+}
+
+// reconcileFailoverProbe advances the failover probe of the StorageClass by a step.
+// It returns the duration after which the probe should be checked again.
+func (r *PieProbeReconciler) reconcileFailoverProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	availableNodes map[string]corev1.Node,
+) (time.Duration, error) {
+	logger := log.FromContext(ctx)
+
+	name, err := getFailoverProbeName(pieProbe, storageClass)
+	if err != nil {
+		return 0, err
+	}
+	key := types.NamespacedName{Namespace: pieProbe.GetNamespace(), Name: name}
+	period := time.Duration(pieProbe.Spec.FailoverProbe.Period) * time.Minute
+
+	run, lastRunTime, hasRun := r.getStagedProbeRun(key)
+
+	if run == nil {
+		nodeNames, err := selectNodes(pieProbe.Spec.FailoverProbe.NodeSelector, availableNodes)
+		if err != nil {
+			return 0, err
+		}
+		if len(nodeNames) < 2 {
+			logger.Info("skip the failover probe because less than two nodes are selected",
+				"storageClass", storageClass)
+			return 0, nil
+		}
+		if hasRun && time.Since(lastRunTime) < period {
+			return period - time.Since(lastRunTime), nil
+		}
+		// Move the volume between different nodes every time.
+		perm := rand.Perm(len(nodeNames))
+		return r.startFailoverProbe(ctx, pieProbe, storageClass, nodeNames[perm[0]], nodeNames[perm[1]], key)
+	}
+
+	if time.Since(run.stageStartTime) >= pieProbe.Spec.FailoverProbe.Timeout.Duration {
+		logger.Info("failover probe timed out", "name", name, "stage", run.stage)
+		return r.finishFailoverProbe(ctx, pieProbe, storageClass, key, run, false)
+	}
+
+	var pvc corev1.PersistentVolumeClaim
+	err = r.client.Get(ctx, key, &pvc)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.finishFailoverProbe(ctx, pieProbe, storageClass, key, run, false)
+		}
+		return 0, err
+	}
+	sourceNode := pvc.GetAnnotations()[constants.FailoverSourceNodeAnnotationKey]
+	destinationNode := pvc.GetAnnotations()[constants.FailoverDestinationNodeAnnotationKey]
+
+	switch run.stage {
+	case failoverProbeStageWritten:
+		var job batchv1.Job
+		err := r.client.Get(ctx, getFailoverSourceJobKey(key), &job)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return r.finishFailoverProbe(ctx, pieProbe, storageClass, key, run, false)
+			}
+			return 0, err
+		}
+		if finished, _ := getJobResult(&job); finished {
+			// The Job must keep the volume in use until it is deleted.
+			return r.finishFailoverProbe(ctx, pieProbe, storageClass, key, run, false)
+		}
+		// The Pod becomes ready after it writes the known data.
+		if job.Status.Ready == nil || *job.Status.Ready == 0 || pvc.Spec.VolumeName == "" {
+			return stagedProbePollInterval, nil
+		}
+
+		va, err := r.getVolumeAttachment(ctx, pvc.Spec.VolumeName, sourceNode)
+		if err != nil {
+			return 0, err
+		}
+		if va == nil {
+			// The volume is mounted without being attached, so there is nothing to measure.
+			logger.Info("skip the failover probe because the volume is not attached with a VolumeAttachment",
+				"storageClass", storageClass, "persistentVolume", pvc.Spec.VolumeName)
+			if _, err := r.cleanupFailoverProbe(ctx, key); err != nil {
+				return 0, err
+			}
+			r.endStagedProbe(key)
+			return period, nil
+		}
+
+		if _, err := r.deleteStagedProbeObjects(ctx, getFailoverSourceJobKey(key), &batchv1.Job{}); err != nil {
+			return 0, err
+		}
+		r.passFailoverProbeStage(pieProbe, storageClass, run, failoverProbeStageDetached)
+
+	case failoverProbeStageDetached:
+		va, err := r.getVolumeAttachment(ctx, pvc.Spec.VolumeName, sourceNode)
+		if err != nil {
+			return 0, err
+		}
+		if va != nil && va.Status.Attached {
+			return stagedProbePollInterval, nil
+		}
+
+		// The known data was written on the source node.
+		err = r.createStagedProbeJob(ctx, pieProbe, storageClass, destinationNode,
+			getFailoverDestinationJobKey(key).Name, key.Name, makeVerifyArgs(pieProbe, storageClass, sourceNode))
+		if err != nil {
+			return 0, err
+		}
+		r.passFailoverProbeStage(pieProbe, storageClass, run, failoverProbeStageAttached)
+
+	case failoverProbeStageAttached:
+		va, err := r.getVolumeAttachment(ctx, pvc.Spec.VolumeName, destinationNode)
+		if err != nil {
+			return 0, err
+		}
+		if va == nil || !va.Status.Attached {
+			return stagedProbePollInterval, nil
+		}
+		r.passFailoverProbeStage(pieProbe, storageClass, run, failoverProbeStageMounted)
+
+	case failoverProbeStageMounted:
+		var job batchv1.Job
+		err := r.client.Get(ctx, getFailoverDestinationJobKey(key), &job)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return r.finishFailoverProbe(ctx, pieProbe, storageClass, key, run, false)
+			}
+			return 0, err
+		}
+		if finished, succeeded := getJobResult(&job); finished {
+			return r.finishFailoverProbe(ctx, pieProbe, storageClass, key, run, succeeded)
+		}
+	}
+
+	return stagedProbePollInterval, nil
+}
+
+// startFailoverProbe creates a PVC and a Job writing the known data to it on the source node.
+// The Job keeps the volume in use until it is deleted.
+func (r *PieProbeReconciler) startFailoverProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	sourceNode string,
+	destinationNode string,
+	key types.NamespacedName,
+) (time.Duration, error) {
+	// Clean up the resources left by the previous run, e.g. before the controller restarted.
+	deleted, err := r.cleanupFailoverProbe(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if deleted {
+		return stagedProbePollInterval, nil
+	}
+
+	volumeMode := getVolumeMode(pieProbe)
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+			Labels: map[string]string{
+				constants.ProbeStorageClassLabelKey: storageClass,
+				constants.ProbePieProbeLabelKey:     pieProbe.GetName(),
+			},
+			Annotations: map[string]string{
+				constants.FailoverSourceNodeAnnotationKey:      sourceNode,
+				constants.FailoverDestinationNodeAnnotationKey: destinationNode,
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: &storageClass,
+			VolumeMode:       &volumeMode,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceStorage: *pieProbe.Spec.PVCCapacity,
+				},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(pieProbe, pvc, r.client.Scheme()); err != nil {
+		return 0, err
+	}
+	err = r.client.Create(ctx, pvc)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return 0, fmt.Errorf("failed to create PVC '%s' for the failover probe: %w", key.Name, err)
+	}
+
+	job := r.makeStagedProbeJob(pieProbe, storageClass, sourceNode, getFailoverSourceJobKey(key).Name, key.Name,
+		[]string{
+			"write",
+			makeTargetArg(pieProbe),
+			fmt.Sprintf("--node-name=%s", sourceNode),
+			fmt.Sprintf("--storage-class=%s", storageClass),
+			fmt.Sprintf("--pie-probe-name=%s", pieProbe.GetName()),
+			"--hold",
+		})
+	job.Spec.Template.Spec.Containers[0].ReadinessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{
+				Command: append([]string{"/pie"}, makeVerifyArgs(pieProbe, storageClass, sourceNode)...),
+			},
+		},
+		PeriodSeconds: 2,
+	}
+	if err := r.createJob(ctx, pieProbe, job); err != nil {
+		return 0, err
+	}
+
+	r.beginStagedProbe(key, failoverProbeStageWritten)
+	return stagedProbePollInterval, nil
+}
+
+// passFailoverProbeStage exports the result of the current stage and moves on to the next stage.
+// The stage is on time if it finishes within .spec.probeThreshold.
+func (r *PieProbeReconciler) passFailoverProbeStage(
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	run *stagedProbeRun,
+	nextStage string,
+) {
+	stage := run.stage
+	duration := r.advanceStagedProbe(run, nextStage)
+	onTime := duration < pieProbe.Spec.ProbeThreshold.Seconds()
+	r.exporter.SetFailoverProbeDuration(pieProbe.GetNamespace(), pieProbe.GetName(), storageClass, stage, duration)
+	r.exporter.IncrementFailoverProbeCount(pieProbe.GetNamespace(), pieProbe.GetName(), storageClass, stage, onTime)
+}
+
+// finishFailoverProbe exports the result of the last stage and cleans up the resources.
+// The stage failed is counted as not on time.
+func (r *PieProbeReconciler) finishFailoverProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	key types.NamespacedName,
+	run *stagedProbeRun,
+	succeed bool,
+) (time.Duration, error) {
+	onTime := false
+	if succeed {
+		duration := time.Since(run.stageStartTime).Seconds()
+		r.exporter.SetFailoverProbeDuration(
+			pieProbe.GetNamespace(), pieProbe.GetName(), storageClass, run.stage, duration)
+		onTime = duration < pieProbe.Spec.ProbeThreshold.Seconds()
+	}
+	r.exporter.IncrementFailoverProbeCount(pieProbe.GetNamespace(), pieProbe.GetName(), storageClass, run.stage, onTime)
+
+	if _, err := r.cleanupFailoverProbe(ctx, key); err != nil {
+		return 0, err
+	}
+
+	r.endStagedProbe(key)
+	return time.Duration(pieProbe.Spec.FailoverProbe.Period) * time.Minute, nil
+}
+
+// getVolumeAttachment returns the VolumeAttachment of the PV to the node, or nil if it does not exist.
+func (r *PieProbeReconciler) getVolumeAttachment(
+	ctx context.Context,
+	pvName string,
+	nodeName string,
+) (*storagev1.VolumeAttachment, error) {
+	var vaList storagev1.VolumeAttachmentList
+	err := r.client.List(ctx, &vaList)
+	if err != nil {
+		return nil, err
+	}
+	for _, va := range vaList.Items {
+		source := va.Spec.Source.PersistentVolumeName
+		if source != nil && *source == pvName && va.Spec.NodeName == nodeName {
+			return &va, nil
+		}
+	}
+	return nil, nil
+}
+
+// cleanupFailoverProbe deletes the resources of the failover probe.
+// It returns true if any of them still exists.
+func (r *PieProbeReconciler) cleanupFailoverProbe(ctx context.Context, key types.NamespacedName) (bool, error) {
+	found := false
+	for _, jobKey := range []types.NamespacedName{getFailoverSourceJobKey(key), getFailoverDestinationJobKey(key)} {
+		jobFound, err := r.deleteStagedProbeObjects(ctx, jobKey, &batchv1.Job{})
+		if err != nil {
+			return false, err
+		}
+		found = found || jobFound
+	}
+	pvcFound, err := r.deleteStagedProbeObjects(ctx, key, &corev1.PersistentVolumeClaim{})
+	if err != nil {
+		return false, err
+	}
+	return found || pvcFound, nil
+}
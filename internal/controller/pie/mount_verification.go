@@ -0,0 +1,181 @@
+package pie
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	pietypes "github.com/topolvm/pie/types"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// The fields of the mounts verified against the StorageClasses.
+const (
+	mountFieldFilesystemType = "fstype"
+	mountFieldMountOptions   = "mount_options"
+	mountFieldReadOnly       = "read_only"
+)
+
+// fstypeParameterKey is the parameter of the StorageClass specifying the filesystem type of the CSI volumes.
+const fstypeParameterKey = "csi.storage.k8s.io/fstype"
+
+// invisibleMountOptions are the mount options not shown in /proc/self/mountinfo,
+// because they are the defaults or they are consumed by mount(8) and the kubelet.
+var invisibleMountOptions = []string{
+	"defaults", "async", "auto", "noauto", "nofail", "_netdev", "user", "nouser", "suid", "dev", "exec",
+}
+
+// mountReportKey identifies the mount probe reporting a mount.
+type mountReportKey struct {
+	namespace    string
+	pieProbeName string
+	node         string
+	storageClass string
+}
+
+// ObserveMount records the mount reported by the mount probe,
+// and triggers the reconciliation of the PieProbe to verify it.
+// It implements metrics.MountObserver.
+func (r *PieProbeReconciler) ObserveMount(
+	namespace, pieProbeName, node, storageClass string,
+	mount *pietypes.MountInfo,
+) {
+	r.mu.Lock()
+	r.mountReports[mountReportKey{namespace, pieProbeName, node, storageClass}] = mount
+	r.mu.Unlock()
+
+	// Don't block the receiver even if the events are not consumed.
+	select {
+	case r.mountEvents <- event.GenericEvent{
+		Object: &piev1alpha1.PieProbe{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: pieProbeName}},
+	}:
+	default:
+	}
+}
+
+// getMountReport returns the mount last reported by the mount probe, or nil if it has not reported yet.
+func (r *PieProbeReconciler) getMountReport(namespace, pieProbeName, node, storageClass string) *pietypes.MountInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.mountReports[mountReportKey{namespace, pieProbeName, node, storageClass}]
+}
+
+// forgetMountReports stops tracking the mounts reported by the mount probes of the StorageClass.
+func (r *PieProbeReconciler) forgetMountReports(namespace, pieProbeName, storageClass string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key := range r.mountReports {
+		if key.namespace == namespace && key.pieProbeName == pieProbeName && key.storageClass == storageClass {
+			delete(r.mountReports, key)
+		}
+	}
+}
+
+// getMountMismatches returns the fields of the mount not matching the StorageClass.
+// The fstype is verified only if the StorageClass specifies it.
+// All the mountOptions of the StorageClass are expected to be found in the mount except the invisible ones.
+func getMountMismatches(mount *pietypes.MountInfo, storageClass *storagev1.StorageClass) []string {
+	mismatches := []string{}
+
+	fstype := storageClass.Parameters[fstypeParameterKey]
+	if fstype != "" && fstype != mount.FilesystemType {
+		mismatches = append(mismatches, mountFieldFilesystemType)
+	}
+
+	expectedOptions := []string{}
+	for _, entry := range storageClass.MountOptions {
+		for _, option := range strings.Split(entry, ",") {
+			option = strings.TrimSpace(option)
+			if option == "" || slices.Contains(invisibleMountOptions, option) {
+				continue
+			}
+			expectedOptions = append(expectedOptions, option)
+		}
+	}
+	for _, option := range expectedOptions {
+		if !slices.Contains(mount.MountOptions, option) {
+			mismatches = append(mismatches, mountFieldMountOptions)
+			break
+		}
+	}
+
+	if mount.ReadOnly && !slices.Contains(expectedOptions, "ro") {
+		mismatches = append(mismatches, mountFieldReadOnly)
+	}
+
+	return mismatches
+}
+
+// verifyMountProbeMounts verifies the mounts reported by the mount probes,
+// and reports the mismatches as the metrics and the condition of the PieProbe.
+func (r *PieProbeReconciler) verifyMountProbeMounts(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClasses []string,
+	availableNodes map[string]corev1.Node,
+) error {
+	mismatchedMounts := []string{}
+	for _, storageClassName := range storageClasses {
+		var storageClass storagev1.StorageClass
+		err := r.client.Get(ctx, client.ObjectKey{Name: storageClassName}, &storageClass)
+		if err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			continue
+		}
+
+		for nodeName := range availableNodes {
+			mount := r.getMountReport(pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClassName)
+			if mount == nil {
+				continue
+			}
+			mismatches := getMountMismatches(mount, &storageClass)
+			for _, field := range []string{mountFieldFilesystemType, mountFieldMountOptions, mountFieldReadOnly} {
+				r.exporter.SetMountMismatched(
+					pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClassName, field,
+					slices.Contains(mismatches, field))
+			}
+			if len(mismatches) != 0 {
+				mismatchedMounts = append(mismatchedMounts,
+					fmt.Sprintf("%s on %s (%s)", storageClassName, nodeName, strings.Join(mismatches, ", ")))
+			}
+		}
+	}
+	return r.updateMountMismatchCondition(ctx, pieProbe, mismatchedMounts)
+}
+
+func (r *PieProbeReconciler) updateMountMismatchCondition(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	mismatchedMounts []string,
+) error {
+	condition := metav1.Condition{
+		Type:    piev1alpha1.PieProbeConditionMountMismatched,
+		Status:  metav1.ConditionFalse,
+		Reason:  "MountMatched",
+		Message: "the filesystems mounted by the mount probes match the StorageClasses",
+	}
+	if len(mismatchedMounts) != 0 {
+		sort.Strings(mismatchedMounts)
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "MountMismatched"
+		condition.Message = fmt.Sprintf("%d filesystems mounted by the mount probes mismatch: %s",
+			len(mismatchedMounts), strings.Join(mismatchedMounts, "; "))
+	}
+	condition.ObservedGeneration = pieProbe.GetGeneration()
+
+	patch := client.MergeFromWithOptions(pieProbe.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	if !meta.SetStatusCondition(&pieProbe.Status.Conditions, condition) {
+		return nil
+	}
+	return r.client.Status().Patch(ctx, pieProbe, patch)
+}
@@ -8,25 +8,35 @@ import (
 	"fmt"
 	"hash/crc32"
 	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
 	"github.com/topolvm/pie/constants"
+	"github.com/topolvm/pie/metrics"
+	pietypes "github.com/topolvm/pie/types"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
@@ -34,11 +44,58 @@ const (
 	MountProbe
 )
 
+// pvcRotationPollInterval is the interval to check the progress of the rotations of the mount-probe PVCs.
+const pvcRotationPollInterval = 10 * time.Second
+
+// mountEventBufferSize is the number of the mount reports waiting for the reconciliations.
+const mountEventBufferSize = 100
+
+// blockDevicePath is the path where a Block volume is attached in the probe container.
+const blockDevicePath = "/dev/pie-block"
+
+// probePodNameEnv is the environment variable holding the name of the mount-probe Pod.
+const probePodNameEnv = "POD_NAME"
+
+// mountProbeDeadlineMargin is how long the mount-probe Jobs are allowed to run over the timeout of the probes.
+// The probes still running then are terminated and report the timeouts on SIGTERM.
+const mountProbeDeadlineMargin = 10 * time.Second
+
+// The suspenders of the mount-probe CronJobs recorded in constants.MountProbeSuspendersAnnotationKey.
+const (
+	// mountProbeSuspenderPVCRotation suspends the CronJob during the rotation of its PVC.
+	mountProbeSuspenderPVCRotation = "pvc-rotation"
+	// mountProbeSuspenderSnapshotProbe suspends the CronJob while the snapshot of its PVC is taken.
+	mountProbeSuspenderSnapshotProbe = "snapshot-probe"
+	// mountProbeSuspenderCloneProbe suspends the CronJob while its PVC is cloned.
+	mountProbeSuspenderCloneProbe = "clone-probe"
+)
+
 // PieProbeReconciler reconciles a PieProbe object
 type PieProbeReconciler struct {
 	client         client.Client
 	containerImage string
 	controllerUrl  string
+	exporter       metrics.MetricsExporter
+	// controllerGRPCAddress is the address of the gRPC receiver, which is empty if it is not exposed.
+	controllerGRPCAddress string
+
+	// mu protects the maps below, which track the staged probes and the mount reports.
+	mu sync.Mutex
+	// stagedProbeRuns holds the running staged probes, e.g. the snapshot probes.
+	stagedProbeRuns map[types.NamespacedName]*stagedProbeRun
+	// stagedProbeLastRunTimes holds the times when the last staged probes finished.
+	stagedProbeLastRunTimes map[types.NamespacedName]time.Time
+	// mountReports holds the mounts last reported by the mount probes.
+	mountReports map[mountReportKey]*pietypes.MountInfo
+	// monitoredStorageClasses holds the StorageClasses monitored by the PieProbes
+	// to delete their metrics after they are no longer monitored.
+	monitoredStorageClasses map[types.NamespacedName]map[string]struct{}
+
+	// mountEvents triggers the reconciliations to verify the reported mounts.
+	mountEvents chan event.GenericEvent
+
+	// snapshotAPIAvailable is true if the VolumeSnapshot API is served.
+	snapshotAPIAvailable bool
 }
 
 //+kubebuilder:rbac:groups=pie.topolvm.io,resources=pieprobes,verbs=get;list;watch;create;update;patch;delete
@@ -46,8 +103,8 @@ type PieProbeReconciler struct {
 //+kubebuilder:rbac:groups=pie.topolvm.io,resources=pieprobes/finalizers,verbs=update
 //+kubebuilder:rbac:groups=pie.topolvm.io,resources=pieprobes,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;create;update;patch
-//+kubebuilder:rbac:namespace=default,groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:namespace=default,groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -71,78 +128,702 @@ func (r *PieProbeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, err
 	}
 
+	if !pieProbe.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.finalizePieProbe(ctx, &pieProbe)
+	}
+	if !controllerutil.ContainsFinalizer(&pieProbe, constants.PieProbeFinalizerName) {
+		controllerutil.AddFinalizer(&pieProbe, constants.PieProbeFinalizerName)
+		err := r.client.Update(ctx, &pieProbe)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	if time.Duration(pieProbe.Spec.ProbePeriod)*time.Minute <= pieProbe.Spec.ProbeThreshold.Duration {
 		return ctrl.Result{}, errors.New("probe period should be larger than probe threshold")
 	}
 
-	var storageClassForGet storagev1.StorageClass
-	err = r.client.Get(ctx, client.ObjectKey{Name: pieProbe.Spec.MonitoringStorageClass}, &storageClassForGet)
+	storageClasses, err := r.getMonitoringStorageClasses(ctx, &pieProbe)
 	if err != nil {
-		if apierrors.IsNotFound(err) {
-			return ctrl.Result{}, nil
+		return ctrl.Result{}, err
+	}
+	err = r.updateStorageClassCondition(ctx, &pieProbe, len(storageClasses) != 0)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	availableNodes, err := r.getAvailableNodes(ctx, &pieProbe)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	topologyValues := getTopologyValues(&pieProbe, availableNodes)
+	err = r.updateTopologyCondition(ctx, &pieProbe, topologyValues)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var requeueAfter time.Duration
+	for _, storageClass := range storageClasses {
+		if !pieProbe.Spec.DisableProvisionProbe {
+			err := r.reconcileProvisionProbe(ctx, &pieProbe, storageClass, availableNodes, topologyValues)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		if !pieProbe.Spec.DisableMountProbes {
+			after, err := r.reconcileMountProbes(ctx, &pieProbe, storageClass, availableNodes)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			requeueAfter = minRequeueAfter(requeueAfter, after)
+		}
+
+		if pieProbe.Spec.ExpansionProbe != nil {
+			after, err := r.reconcileExpansionProbes(ctx, &pieProbe, storageClass, availableNodes)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			requeueAfter = minRequeueAfter(requeueAfter, after)
 		}
+
+		if pieProbe.Spec.SharedAccessProbe != nil {
+			after, err := r.reconcileSharedAccessProbe(ctx, &pieProbe, storageClass, availableNodes)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			requeueAfter = minRequeueAfter(requeueAfter, after)
+		}
+
+		if pieProbe.Spec.FailoverProbe != nil {
+			after, err := r.reconcileFailoverProbe(ctx, &pieProbe, storageClass, availableNodes)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			requeueAfter = minRequeueAfter(requeueAfter, after)
+		}
+	}
+
+	err = r.verifyMountProbeVolumes(ctx, &pieProbe, storageClasses, availableNodes)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.verifyMountProbeMounts(ctx, &pieProbe, storageClasses, availableNodes)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
-	if storageClassForGet.DeletionTimestamp != nil {
-		return ctrl.Result{}, nil
+
+	err = r.deleteUnnecessaryResources(ctx, &pieProbe, storageClasses, availableNodes, topologyValues)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
-	if !pieProbe.Spec.DisableProvisionProbe {
-		if err := r.reconcileProvisionProbe(ctx, &pieProbe); err != nil {
-			return ctrl.Result{}, err
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// finalizePieProbe deletes the metrics of the PieProbe being deleted and removes its finalizer.
+// The CronJobs, the Jobs and the PVCs of the probes are deleted by the garbage collector.
+func (r *PieProbeReconciler) finalizePieProbe(ctx context.Context, pieProbe *piev1alpha1.PieProbe) error {
+	if !controllerutil.ContainsFinalizer(pieProbe, constants.PieProbeFinalizerName) {
+		return nil
+	}
+
+	for storageClass := range r.updateMonitoredStorageClasses(client.ObjectKeyFromObject(pieProbe), nil) {
+		r.forgetMountReports(pieProbe.GetNamespace(), pieProbe.GetName(), storageClass)
+	}
+	r.exporter.DeleteMetricsOfPieProbe(pieProbe.GetNamespace(), pieProbe.GetName())
+
+	controllerutil.RemoveFinalizer(pieProbe, constants.PieProbeFinalizerName)
+	return r.client.Update(ctx, pieProbe)
+}
+
+// updateMonitoredStorageClasses remembers the StorageClasses monitored by the PieProbe.
+// It returns the StorageClasses monitored previously but no longer monitored.
+func (r *PieProbeReconciler) updateMonitoredStorageClasses(
+	key types.NamespacedName,
+	storageClasses []string,
+) map[string]struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unmonitored := r.monitoredStorageClasses[key]
+	if unmonitored == nil {
+		unmonitored = map[string]struct{}{}
+	}
+	if len(storageClasses) == 0 {
+		delete(r.monitoredStorageClasses, key)
+		return unmonitored
+	}
+	monitored := map[string]struct{}{}
+	for _, storageClass := range storageClasses {
+		monitored[storageClass] = struct{}{}
+		delete(unmonitored, storageClass)
+	}
+	r.monitoredStorageClasses[key] = monitored
+	return unmonitored
+}
+
+// minRequeueAfter returns the shorter one of the non-zero durations.
+func minRequeueAfter(a, b time.Duration) time.Duration {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// getMonitoringStorageClasses returns the names of the StorageClasses monitored by the PieProbe.
+// StorageClasses that are being deleted are excluded.
+func (r *PieProbeReconciler) getMonitoringStorageClasses(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+) ([]string, error) {
+	if pieProbe.Spec.StorageClassSelector == nil {
+		var storageClass storagev1.StorageClass
+		err := r.client.Get(ctx, client.ObjectKey{Name: pieProbe.Spec.MonitoringStorageClass}, &storageClass)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
 		}
+		if storageClass.DeletionTimestamp != nil {
+			return nil, nil
+		}
+		return []string{storageClass.GetName()}, nil
 	}
 
-	if !pieProbe.Spec.DisableMountProbes {
-		if err := r.reconcileMountProbes(ctx, &pieProbe); err != nil {
-			return ctrl.Result{}, err
+	selector, err := metav1.LabelSelectorAsSelector(pieProbe.Spec.StorageClassSelector)
+	if err != nil {
+		return nil, err
+	}
+	storageClassList := storagev1.StorageClassList{}
+	err = r.client.List(ctx, &storageClassList, &client.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	storageClasses := []string{}
+	for _, storageClass := range storageClassList.Items {
+		if storageClass.DeletionTimestamp != nil {
+			continue
 		}
+		storageClasses = append(storageClasses, storageClass.GetName())
 	}
+	sort.Strings(storageClasses)
+	return storageClasses, nil
+}
 
-	return ctrl.Result{}, nil
+func (r *PieProbeReconciler) updateStorageClassCondition(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	found bool,
+) error {
+	condition := metav1.Condition{
+		Type:    piev1alpha1.PieProbeConditionStorageClassNotFound,
+		Status:  metav1.ConditionFalse,
+		Reason:  "StorageClassFound",
+		Message: "the monitored StorageClasses exist",
+	}
+	if !found {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "StorageClassNotFound"
+		condition.Message = "no monitored StorageClass exists"
+	}
+	condition.ObservedGeneration = pieProbe.GetGeneration()
+	if !meta.SetStatusCondition(&pieProbe.Status.Conditions, condition) {
+		return nil
+	}
+	return r.client.Status().Update(ctx, pieProbe)
 }
 
-func (r *PieProbeReconciler) reconcileProvisionProbe(ctx context.Context, pieProbe *piev1alpha1.PieProbe) error {
-	// Create a provision-probe CronJob for each sc
-	return r.createOrUpdateJob(ctx, ProvisionProbe, pieProbe, nil)
+// updateTopologyCondition reports that no provision probe is created
+// because none of the selected nodes has the label of provisionProbeTopologyKey.
+func (r *PieProbeReconciler) updateTopologyCondition(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	topologyValues map[string]struct{},
+) error {
+	condition := metav1.Condition{
+		Type:    piev1alpha1.PieProbeConditionTopologyNotFound,
+		Status:  metav1.ConditionFalse,
+		Reason:  "TopologyFound",
+		Message: "the provision probes are created for the topology values",
+	}
+	if pieProbe.Spec.ProvisionProbeTopologyKey != "" && !pieProbe.Spec.DisableProvisionProbe &&
+		!pieProbe.Spec.ProvisionProbePerNode && len(topologyValues) == 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "TopologyNotFound"
+		condition.Message = fmt.Sprintf("none of the selected nodes has the label %s",
+			pieProbe.Spec.ProvisionProbeTopologyKey)
+	}
+	condition.ObservedGeneration = pieProbe.GetGeneration()
+	if !meta.SetStatusCondition(&pieProbe.Status.Conditions, condition) {
+		return nil
+	}
+	return r.client.Status().Update(ctx, pieProbe)
 }
 
-func (r *PieProbeReconciler) reconcileMountProbes(ctx context.Context, pieProbe *piev1alpha1.PieProbe) error {
-	// Get a node list and create a PVC and a mount-probe CronJob for each node and sc.
+func (r *PieProbeReconciler) getAvailableNodes(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+) (map[string]corev1.Node, error) {
 	nodeSelector, err := nodeaffinity.NewNodeSelector(&pieProbe.Spec.NodeSelector)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	allNodeList := corev1.NodeList{}
 	err = r.client.List(ctx, &allNodeList)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	availableNodeList := []corev1.Node{}
+	availableNodes := map[string]corev1.Node{}
 	for _, node := range allNodeList.Items {
 		if !nodeSelector.Match(&node) {
 			continue
 		}
-		availableNodeList = append(availableNodeList, node)
+		availableNodes[node.GetName()] = node
+	}
+	return availableNodes, nil
+}
+
+// getTopologyValues returns the values of the provision probe topology key among the available nodes.
+// It returns nil if the topology key is not specified.
+func getTopologyValues(pieProbe *piev1alpha1.PieProbe, availableNodes map[string]corev1.Node) map[string]struct{} {
+	topologyKey := pieProbe.Spec.ProvisionProbeTopologyKey
+	if topologyKey == "" {
+		return nil
+	}
+	topologyValues := map[string]struct{}{}
+	for _, node := range availableNodes {
+		if value, ok := node.GetLabels()[topologyKey]; ok {
+			topologyValues[value] = struct{}{}
+		}
+	}
+	return topologyValues
+}
+
+func (r *PieProbeReconciler) reconcileProvisionProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	availableNodes map[string]corev1.Node,
+	topologyValues map[string]struct{},
+) error {
+	if pieProbe.Spec.ProvisionProbePerNode {
+		// Create a provision-probe CronJob for each node and sc
+		for nodeName := range availableNodes {
+			err := r.createOrUpdateJob(ctx, ProvisionProbe, pieProbe, storageClass, &nodeName, "")
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if topologyValues == nil {
+		// Create a provision-probe CronJob for each sc
+		return r.createOrUpdateJob(ctx, ProvisionProbe, pieProbe, storageClass, nil, "")
+	}
 
-		err = r.createOrUpdatePVC(ctx, node.Name, pieProbe)
+	// Create a provision-probe CronJob for each topology value and sc
+	for topologyValue := range topologyValues {
+		err := r.createOrUpdateJob(ctx, ProvisionProbe, pieProbe, storageClass, nil, topologyValue)
 		if err != nil {
 			return err
 		}
-		err = r.createOrUpdateJob(ctx, MountProbe, pieProbe, &node.Name)
+	}
+	return nil
+}
+
+func (r *PieProbeReconciler) reconcileMountProbes(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	availableNodes map[string]corev1.Node,
+) (time.Duration, error) {
+	// Create a PVC and a mount-probe CronJob for each node and sc.
+	var requeueAfter time.Duration
+	for nodeName := range availableNodes {
+		rotating, after, err := r.rotatePVC(ctx, pieProbe, storageClass, nodeName)
 		if err != nil {
-			return err
+			return 0, err
+		}
+		requeueAfter = minRequeueAfter(requeueAfter, after)
+		if rotating {
+			// The PVC is recreated and the CronJob is resumed after the old PVC is deleted.
+			continue
+		}
+
+		err = r.createOrUpdatePVC(ctx, nodeName, pieProbe, storageClass)
+		if err != nil {
+			return 0, err
+		}
+		err = r.createOrUpdateJob(ctx, MountProbe, pieProbe, storageClass, &nodeName, "")
+		if err != nil {
+			return 0, err
+		}
+		// Resume the CronJob suspended on the rotation of the PVC now that the PVC is recreated.
+		err = r.releaseMountProbe(ctx, pieProbe, storageClass, nodeName, mountProbeSuspenderPVCRotation)
+		if err != nil {
+			return 0, err
+		}
+
+		if pieProbe.Spec.SnapshotProbe != nil {
+			after, err := r.reconcileSnapshotProbe(ctx, pieProbe, storageClass, nodeName)
+			if err != nil {
+				return 0, err
+			}
+			requeueAfter = minRequeueAfter(requeueAfter, after)
+		} else {
+			// Resume the CronJob suspended by the snapshot probe disabled during its run.
+			err = r.releaseMountProbe(ctx, pieProbe, storageClass, nodeName, mountProbeSuspenderSnapshotProbe)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		if pieProbe.Spec.CloneProbe != nil {
+			after, err := r.reconcileCloneProbe(ctx, pieProbe, storageClass, nodeName)
+			if err != nil {
+				return 0, err
+			}
+			requeueAfter = minRequeueAfter(requeueAfter, after)
+		} else {
+			// Resume the CronJob suspended by the clone probe disabled during its run.
+			err = r.releaseMountProbe(ctx, pieProbe, storageClass, nodeName, mountProbeSuspenderCloneProbe)
+			if err != nil {
+				return 0, err
+			}
 		}
 	}
+	return requeueAfter, nil
+}
+
+// rotatePVC deletes the mount-probe PVC of the node if it is older than the rotation period.
+// The mount-probe CronJob is suspended before the deletion and the PVC is deleted
+// only after the running mount probe finishes.
+// The progress of the rotation is recorded in the annotations of the CronJob,
+// so that the durations are exported even if the controller restarts during the rotation.
+// It returns true while the PVC is being rotated, and the duration after which the PVC should be checked again.
+func (r *PieProbeReconciler) rotatePVC(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+) (bool, time.Duration, error) {
+	logger := log.FromContext(ctx)
+
+	if pieProbe.Spec.PVCRotationPeriod == nil {
+		return false, 0, nil
+	}
+
+	pvcName, err := getPVCName(nodeName, pieProbe, storageClass)
+	if err != nil {
+		return false, 0, err
+	}
+	cronJob, err := r.getMountProbeCronJob(ctx, pieProbe, storageClass, nodeName)
+	if err != nil {
+		return false, 0, err
+	}
 
-	availableNodes := map[string]struct{}{}
-	for _, node := range availableNodeList {
-		availableNodes[node.GetName()] = struct{}{}
+	var pvc corev1.PersistentVolumeClaim
+	err = r.client.Get(ctx, types.NamespacedName{Namespace: pieProbe.GetNamespace(), Name: pvcName}, &pvc)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, 0, r.observePVCDeleted(ctx, cronJob, pieProbe, nodeName, storageClass)
+		}
+		return false, 0, err
+	}
+	if pvc.DeletionTimestamp != nil {
+		return true, pvcRotationPollInterval, nil
+	}
+	err = r.observePVCRebound(ctx, cronJob, &pvc, pieProbe, nodeName, storageClass)
+	if err != nil {
+		return false, 0, err
 	}
 
-	// Delete unnecessary mount-probe CronJobs
+	age := time.Since(pvc.CreationTimestamp.Time)
+	if age < pieProbe.Spec.PVCRotationPeriod.Duration {
+		return false, pieProbe.Spec.PVCRotationPeriod.Duration - age, nil
+	}
+
+	// Suspend the CronJob not to start a new mount probe during the rotation.
+	err = r.suspendMountProbe(ctx, cronJob, mountProbeSuspenderPVCRotation)
+	if err != nil {
+		return false, 0, err
+	}
+	running, err := r.isMountProbeRunning(ctx, cronJob, pieProbe, storageClass, nodeName)
+	if err != nil {
+		return false, 0, err
+	}
+	if running {
+		return true, pvcRotationPollInterval, nil
+	}
+
+	err = r.deletePVC(ctx, &pvc)
+	if err != nil {
+		return false, 0, err
+	}
+	logger.Info("rotating PVC", "pvcName", pvcName)
+
+	if cronJob != nil {
+		patch := client.MergeFrom(cronJob.DeepCopy())
+		metav1.SetMetaDataAnnotation(&cronJob.ObjectMeta,
+			constants.PVCRotationDeletedAtAnnotationKey, time.Now().UTC().Format(time.RFC3339Nano))
+		delete(cronJob.Annotations, constants.PVCRotationRebindingAnnotationKey)
+		err = r.client.Patch(ctx, cronJob, patch)
+		if err != nil {
+			return false, 0, fmt.Errorf("failed to record the rotation on CronJob '%s': %w", cronJob.GetName(), err)
+		}
+	}
+	return true, pvcRotationPollInterval, nil
+}
+
+// getMountProbeCronJob returns the mount-probe CronJob of the node, or nil if it does not exist.
+func (r *PieProbeReconciler) getMountProbeCronJob(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+) (*batchv1.CronJob, error) {
+	cronJobName, err := getCronJobName(MountProbe, &nodeName, pieProbe, storageClass, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var cronJob batchv1.CronJob
+	err = r.client.Get(ctx, client.ObjectKey{Namespace: pieProbe.GetNamespace(), Name: cronJobName}, &cronJob)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cronJob, nil
+}
+
+// getMountProbeSuspenders returns the reasons why the controller suspended the mount-probe CronJob.
+func getMountProbeSuspenders(cronJob *batchv1.CronJob) []string {
+	value := cronJob.GetAnnotations()[constants.MountProbeSuspendersAnnotationKey]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// suspendMountProbe suspends the mount-probe CronJob and records the suspender in its annotation.
+// The CronJob suspended by others, e.g. by the users, is left as it is and is never resumed by the controller.
+// It does nothing if the CronJob does not exist.
+func (r *PieProbeReconciler) suspendMountProbe(ctx context.Context, cronJob *batchv1.CronJob, suspender string) error {
+	if cronJob == nil {
+		return nil
+	}
+	suspenders := getMountProbeSuspenders(cronJob)
+	if slices.Contains(suspenders, suspender) {
+		return nil
+	}
+	if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend && len(suspenders) == 0 {
+		return nil
+	}
+
+	patch := client.MergeFrom(cronJob.DeepCopy())
+	suspend := true
+	cronJob.Spec.Suspend = &suspend
+	metav1.SetMetaDataAnnotation(&cronJob.ObjectMeta,
+		constants.MountProbeSuspendersAnnotationKey, strings.Join(append(suspenders, suspender), ","))
+	err := r.client.Patch(ctx, cronJob, patch)
+	if err != nil {
+		return fmt.Errorf("failed to suspend CronJob '%s': %w", cronJob.GetName(), err)
+	}
+	return nil
+}
+
+// resumeMountProbe removes the suspender from the annotation of the mount-probe CronJob,
+// and resumes the CronJob if no other suspender is left.
+// It does nothing if the CronJob does not exist or was not suspended by the suspender.
+func (r *PieProbeReconciler) resumeMountProbe(ctx context.Context, cronJob *batchv1.CronJob, suspender string) error {
+	if cronJob == nil {
+		return nil
+	}
+	suspenders := getMountProbeSuspenders(cronJob)
+	if !slices.Contains(suspenders, suspender) {
+		return nil
+	}
+
+	patch := client.MergeFrom(cronJob.DeepCopy())
+	suspenders = slices.DeleteFunc(suspenders, func(s string) bool { return s == suspender })
+	if len(suspenders) == 0 {
+		suspend := false
+		cronJob.Spec.Suspend = &suspend
+		delete(cronJob.Annotations, constants.MountProbeSuspendersAnnotationKey)
+	} else {
+		cronJob.Annotations[constants.MountProbeSuspendersAnnotationKey] = strings.Join(suspenders, ",")
+	}
+	err := r.client.Patch(ctx, cronJob, patch)
+	if err != nil {
+		return fmt.Errorf("failed to resume CronJob '%s': %w", cronJob.GetName(), err)
+	}
+	return nil
+}
+
+// isMountProbeRunning checks if a mount-probe Job or Pod of the node is still running.
+// The Pods are checked as well because the active Jobs in the CronJob's status may be outdated.
+func (r *PieProbeReconciler) isMountProbeRunning(
+	ctx context.Context,
+	cronJob *batchv1.CronJob,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+) (bool, error) {
+	if cronJob != nil && len(cronJob.Status.Active) != 0 {
+		return true, nil
+	}
+
+	var podList corev1.PodList
+	err := r.client.List(ctx, &podList, client.InNamespace(pieProbe.GetNamespace()), client.MatchingLabels{
+		constants.ProbePieProbeLabelKey:     pieProbe.GetName(),
+		constants.ProbeStorageClassLabelKey: storageClass,
+		constants.ProbeNodeLabelKey:         nodeName,
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, pod := range podList.Items {
+		if !strings.HasPrefix(pod.GetName(), constants.MountProbeNamePrefix) {
+			continue
+		}
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// observePVCDeleted exports the duration to delete the PVC if it was deleted on its rotation,
+// and marks the CronJob as waiting for the recreated PVC to be bound.
+func (r *PieProbeReconciler) observePVCDeleted(
+	ctx context.Context,
+	cronJob *batchv1.CronJob,
+	pieProbe *piev1alpha1.PieProbe,
+	nodeName, storageClass string,
+) error {
+	if cronJob == nil {
+		return nil
+	}
+	value, ok := cronJob.GetAnnotations()[constants.PVCRotationDeletedAtAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	patch := client.MergeFrom(cronJob.DeepCopy())
+	delete(cronJob.Annotations, constants.PVCRotationDeletedAtAnnotationKey)
+	cronJob.Annotations[constants.PVCRotationRebindingAnnotationKey] = "true"
+	err := r.client.Patch(ctx, cronJob, patch)
+	if err != nil {
+		return fmt.Errorf("failed to record the rotation on CronJob '%s': %w", cronJob.GetName(), err)
+	}
+
+	startTime, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		log.FromContext(ctx).Info("ignoring the malformed deletion time", "cronJob", cronJob.GetName(), "value", value)
+		return nil
+	}
+	r.exporter.SetPVCRotationDeleteDuration(pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClass,
+		time.Since(startTime).Seconds())
+	return nil
+}
+
+// observePVCRebound exports the duration to bind the PVC if it was recreated on its rotation and is now bound.
+func (r *PieProbeReconciler) observePVCRebound(
+	ctx context.Context,
+	cronJob *batchv1.CronJob,
+	pvc *corev1.PersistentVolumeClaim,
+	pieProbe *piev1alpha1.PieProbe,
+	nodeName, storageClass string,
+) error {
+	if cronJob == nil || pvc.Status.Phase != corev1.ClaimBound {
+		return nil
+	}
+	if _, ok := cronJob.GetAnnotations()[constants.PVCRotationRebindingAnnotationKey]; !ok {
+		return nil
+	}
+
+	patch := client.MergeFrom(cronJob.DeepCopy())
+	delete(cronJob.Annotations, constants.PVCRotationRebindingAnnotationKey)
+	err := r.client.Patch(ctx, cronJob, patch)
+	if err != nil {
+		return fmt.Errorf("failed to record the rotation on CronJob '%s': %w", cronJob.GetName(), err)
+	}
+	r.exporter.SetPVCRotationRebindDuration(pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClass,
+		time.Since(pvc.CreationTimestamp.Time).Seconds())
+	return nil
+}
+
+// deleteUnnecessaryResources deletes the CronJobs and PVCs owned by the PieProbe
+// whose StorageClasses are no longer monitored or whose nodes or topology values are no longer selected.
+func (r *PieProbeReconciler) deleteUnnecessaryResources(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClasses []string,
+	availableNodes map[string]corev1.Node,
+	topologyValues map[string]struct{},
+) error {
+	monitoredStorageClasses := map[string]struct{}{}
+	for _, storageClass := range storageClasses {
+		monitoredStorageClasses[storageClass] = struct{}{}
+	}
+	// The metrics of the StorageClasses monitored before are deleted even if no resources of them are left,
+	// e.g. when the results of their mount probes are received after their resources are deleted.
+	unmonitoredStorageClasses := r.updateMonitoredStorageClasses(client.ObjectKeyFromObject(pieProbe), storageClasses)
+	isNecessary := func(obj client.Object) bool {
+		storageClass := obj.GetLabels()[constants.ProbeStorageClassLabelKey]
+		if _, ok := monitoredStorageClasses[storageClass]; !ok {
+			return false
+		}
+		nodeName, hasNodeName := obj.GetLabels()[constants.ProbeNodeLabelKey]
+		if hasNodeName {
+			if _, ok := availableNodes[nodeName]; !ok {
+				return false
+			}
+		}
+		if strings.HasPrefix(obj.GetName(), constants.SnapshotProbeNamePrefix) {
+			// resources of the snapshot probes
+			return pieProbe.Spec.SnapshotProbe != nil
+		}
+		if strings.HasPrefix(obj.GetName(), constants.ExpansionProbeNamePrefix) {
+			// resources of the expansion probes
+			return pieProbe.Spec.ExpansionProbe != nil
+		}
+		if strings.HasPrefix(obj.GetName(), constants.CloneProbeNamePrefix) {
+			// resources of the clone probes
+			return pieProbe.Spec.CloneProbe != nil
+		}
+		if strings.HasPrefix(obj.GetName(), constants.SharedAccessProbeNamePrefix) {
+			// resources of the shared-access probes
+			return pieProbe.Spec.SharedAccessProbe != nil
+		}
+		if strings.HasPrefix(obj.GetName(), constants.FailoverProbeNamePrefix) {
+			// resources of the failover probes
+			return pieProbe.Spec.FailoverProbe != nil
+		}
+		if !strings.HasPrefix(obj.GetName(), constants.ProvisionProbeNamePrefix) {
+			// mount-probe CronJobs and PVCs
+			return true
+		}
+		if pieProbe.Spec.ProvisionProbePerNode || hasNodeName {
+			return pieProbe.Spec.ProvisionProbePerNode && hasNodeName
+		}
+		topologyValue, ok := obj.GetLabels()[constants.ProbeTopologyLabelKey]
+		if topologyValues == nil || !ok {
+			return topologyValues == nil && !ok
+		}
+		_, ok = topologyValues[topologyValue]
+		return ok
+	}
+
+	// Delete unnecessary CronJobs
 	cronJobList := batchv1.CronJobList{}
-	err = r.client.List(ctx, &cronJobList, &client.ListOptions{
+	err := r.client.List(ctx, &cronJobList, &client.ListOptions{
 		LabelSelector: labels.SelectorFromSet(map[string]string{
 			constants.ProbePieProbeLabelKey: pieProbe.GetName(),
 		}),
@@ -151,12 +832,13 @@ func (r *PieProbeReconciler) reconcileMountProbes(ctx context.Context, pieProbe
 		return err
 	}
 	for _, cronJob := range cronJobList.Items {
-		nodeName := cronJob.GetLabels()[constants.ProbeNodeLabelKey]
-		if nodeName == "" {
+		if isNecessary(&cronJob) {
 			continue
 		}
-		if _, ok := availableNodes[nodeName]; ok {
-			continue
+		// The StorageClasses monitored before the controller restarted are found by the resources left.
+		storageClass := cronJob.GetLabels()[constants.ProbeStorageClassLabelKey]
+		if _, ok := monitoredStorageClasses[storageClass]; !ok {
+			unmonitoredStorageClasses[storageClass] = struct{}{}
 		}
 		err := r.deleteCronJob(ctx, &cronJob)
 		if client.IgnoreNotFound(err) != nil {
@@ -175,19 +857,83 @@ func (r *PieProbeReconciler) reconcileMountProbes(ctx context.Context, pieProbe
 		return err
 	}
 	for _, pvc := range pvcList.Items {
-		nodeName := pvc.GetLabels()[constants.ProbeNodeLabelKey]
-		if _, ok := availableNodes[nodeName]; ok {
+		if isNecessary(&pvc) {
 			continue
 		}
+		storageClass := pvc.GetLabels()[constants.ProbeStorageClassLabelKey]
+		if _, ok := monitoredStorageClasses[storageClass]; !ok {
+			unmonitoredStorageClasses[storageClass] = struct{}{}
+		}
 		err = r.deletePVC(ctx, &pvc)
 		if client.IgnoreNotFound(err) != nil {
 			return err
 		}
+		r.forgetStagedProbe(client.ObjectKeyFromObject(&pvc))
+	}
+
+	// Delete unnecessary resources of the snapshot, expansion, clone, shared-access and failover probes
+	err = r.deleteUnnecessaryStagedProbes(ctx, pieProbe, isNecessary)
+	if err != nil {
+		return err
+	}
+
+	// Stop exporting the metrics of the StorageClasses no longer monitored
+	for storageClass := range unmonitoredStorageClasses {
+		r.exporter.DeleteMetricsOfStorageClass(pieProbe.GetNamespace(), pieProbe.GetName(), storageClass)
+		r.forgetMountReports(pieProbe.GetNamespace(), pieProbe.GetName(), storageClass)
 	}
 
 	return nil
 }
 
+func (r *PieProbeReconciler) deleteUnnecessaryStagedProbes(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	isNecessary func(obj client.Object) bool,
+) error {
+	selector := client.MatchingLabels{constants.ProbePieProbeLabelKey: pieProbe.GetName()}
+
+	jobList := batchv1.JobList{}
+	err := r.client.List(ctx, &jobList, client.InNamespace(pieProbe.GetNamespace()), selector)
+	if err != nil {
+		return err
+	}
+	objects := []client.Object{}
+	for i := range jobList.Items {
+		objects = append(objects, &jobList.Items[i])
+	}
+
+	if r.snapshotAPIAvailable {
+		vsList := &unstructured.UnstructuredList{}
+		vsList.SetGroupVersionKind(volumeSnapshotGVK.GroupVersion().WithKind(volumeSnapshotGVK.Kind + "List"))
+		err = r.client.List(ctx, vsList, client.InNamespace(pieProbe.GetNamespace()), selector)
+		if err != nil {
+			return err
+		}
+		for i := range vsList.Items {
+			objects = append(objects, &vsList.Items[i])
+		}
+	}
+
+	for _, obj := range objects {
+		isStagedProbe := strings.HasPrefix(obj.GetName(), constants.SnapshotProbeNamePrefix) ||
+			strings.HasPrefix(obj.GetName(), constants.ExpansionProbeNamePrefix) ||
+			strings.HasPrefix(obj.GetName(), constants.CloneProbeNamePrefix) ||
+			strings.HasPrefix(obj.GetName(), constants.SharedAccessProbeNamePrefix) ||
+			strings.HasPrefix(obj.GetName(), constants.FailoverProbeNamePrefix)
+		if !isStagedProbe || isNecessary(obj) {
+			continue
+		}
+		policy := metav1.DeletePropagationBackground
+		err := r.client.Delete(ctx, obj, &client.DeleteOptions{PropagationPolicy: &policy})
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		r.forgetStagedProbe(client.ObjectKeyFromObject(obj))
+	}
+	return nil
+}
+
 func (r *PieProbeReconciler) deletePVC(ctx context.Context, pvc *corev1.PersistentVolumeClaim) error {
 	logger := log.FromContext(ctx)
 
@@ -250,20 +996,66 @@ func (r *PieProbeReconciler) findPieProbesForNode(ctx context.Context, node clie
 	return requests
 }
 
+// findPieProbesForStorageClass enqueues the PieProbes referencing the StorageClass by name
+// and all PieProbes selecting StorageClasses by labels.
+// The latter are enqueued even if the StorageClass does not match the selector
+// because its labels may have been changed and the probes for it may have to be deleted.
+func (r *PieProbeReconciler) findPieProbesForStorageClass(
+	ctx context.Context,
+	storageClass client.Object,
+) []reconcile.Request {
+	pieProbeList := piev1alpha1.PieProbeList{}
+	err := r.client.List(ctx, &pieProbeList)
+	if err != nil {
+		return []reconcile.Request{}
+	}
+	requests := []reconcile.Request{}
+	for _, item := range pieProbeList.Items {
+		if item.Spec.StorageClassSelector == nil && item.Spec.MonitoringStorageClass != storageClass.GetName() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      item.GetName(),
+				Namespace: item.GetNamespace(),
+			},
+		})
+	}
+	return requests
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *PieProbeReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	_, err := mgr.GetRESTMapper().RESTMapping(volumeSnapshotGVK.GroupKind(), volumeSnapshotGVK.Version)
+	if err != nil && !meta.IsNoMatchError(err) {
+		return err
+	}
+	r.snapshotAPIAvailable = err == nil
+
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&piev1alpha1.PieProbe{}).
 		Watches(
 			&corev1.Node{},
 			handler.EnqueueRequestsFromMapFunc(r.findPieProbesForNode),
 		).
-		Complete(r)
+		Watches(
+			&storagev1.StorageClass{},
+			handler.EnqueueRequestsFromMapFunc(r.findPieProbesForStorageClass),
+		).
+		WatchesRawSource(source.Channel(
+			r.mountEvents,
+			&handler.EnqueueRequestForObject{},
+		)).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&batchv1.Job{})
+	if r.snapshotAPIAvailable {
+		builder = builder.Owns(newVolumeSnapshot())
+	}
+	return builder.Complete(r)
 }
 
-func getPVCName(nodeName string, pieProbe *piev1alpha1.PieProbe) (string, error) {
+func getPVCName(nodeName string, pieProbe *piev1alpha1.PieProbe, storageClass string) (string, error) {
 	pieProbeName := pieProbe.Name
-	storageClass := pieProbe.Spec.MonitoringStorageClass
 
 	sha1Hash := sha1.New()
 	_, err := io.WriteString(sha1Hash, pieProbeName+"\000"+nodeName+"\000"+storageClass)
@@ -288,14 +1080,14 @@ func (r *PieProbeReconciler) createOrUpdatePVC(
 	ctx context.Context,
 	nodeName string,
 	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
 ) error {
 	logger := log.FromContext(ctx)
 
-	pvcName, err := getPVCName(nodeName, pieProbe)
+	pvcName, err := getPVCName(nodeName, pieProbe, storageClass)
 	if err != nil {
 		return err
 	}
-	storageClass := pieProbe.Spec.MonitoringStorageClass
 
 	pvc := &corev1.PersistentVolumeClaim{}
 	pvc.SetNamespace(pieProbe.GetNamespace())
@@ -311,6 +1103,8 @@ func (r *PieProbeReconciler) createOrUpdatePVC(
 
 		pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
 		pvc.Spec.StorageClassName = &storageClass
+		volumeMode := getVolumeMode(pieProbe)
+		pvc.Spec.VolumeMode = &volumeMode
 
 		if pvc.Spec.Resources.Requests == nil {
 			pvc.Spec.Resources.Requests = map[corev1.ResourceName]resource.Quantity{}
@@ -334,7 +1128,7 @@ func (r *PieProbeReconciler) createOrUpdatePVC(
 	return nil
 }
 
-func makeCronSchedule(pieProbeName string, storageClass string, nodeNamePtr *string, period int) string {
+func makeCronSchedule(pieProbeName string, storageClass string, nodeNamePtr *string, topologyValue string, period int) string {
 	nodeName := ""
 	if nodeNamePtr != nil {
 		nodeName = *nodeNamePtr
@@ -344,6 +1138,7 @@ func makeCronSchedule(pieProbeName string, storageClass string, nodeNamePtr *str
 	h.Write([]byte(pieProbeName))
 	h.Write([]byte(storageClass))
 	h.Write([]byte(nodeName))
+	h.Write([]byte(topologyValue))
 
 	return fmt.Sprintf("%d-59/%d * * * *", h.Sum32()%uint32(period), period)
 }
@@ -362,10 +1157,12 @@ func (r *PieProbeReconciler) createOrUpdateJob(
 	ctx context.Context,
 	kind int,
 	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
 	nodeName *string,
+	topologyValue string,
 ) error {
 	_ = log.FromContext(ctx)
-	cronJobName, err := getCronJobName(kind, nodeName, pieProbe)
+	cronJobName, err := getCronJobName(kind, nodeName, pieProbe, storageClass, topologyValue)
 	if err != nil {
 		return err
 	}
@@ -374,20 +1171,24 @@ func (r *PieProbeReconciler) createOrUpdateJob(
 	cronjob.SetNamespace(pieProbe.GetNamespace())
 	cronjob.SetName(cronJobName)
 
-	storageClass := pieProbe.Spec.MonitoringStorageClass
-
 	_, err = ctrl.CreateOrUpdate(ctx, r.client, cronjob, func() error {
+		volumeMode := getVolumeMode(pieProbe)
 		label := map[string]string{
 			constants.ProbeStorageClassLabelKey: storageClass,
 			constants.ProbePieProbeLabelKey:     pieProbe.GetName(),
+			constants.ProbeVolumeModeLabelKey:   string(volumeMode),
 		}
 		if nodeName != nil {
 			label[constants.ProbeNodeLabelKey] = *nodeName
 		}
+		if topologyValue != "" {
+			label[constants.ProbeTopologyLabelKey] = topologyValue
+		}
 		cronjob.SetLabels(label)
 
 		cronjob.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
-		cronjob.Spec.Schedule = makeCronSchedule(pieProbe.GetName(), storageClass, nodeName, pieProbe.Spec.ProbePeriod)
+		cronjob.Spec.Schedule = makeCronSchedule(
+			pieProbe.GetName(), storageClass, nodeName, topologyValue, pieProbe.Spec.ProbePeriod)
 
 		var successfulJobsHistoryLimit = int32(0)
 		cronjob.Spec.SuccessfulJobsHistoryLimit = &successfulJobsHistoryLimit
@@ -427,10 +1228,15 @@ func (r *PieProbeReconciler) createOrUpdateJob(
 				"provision-probe",
 			}
 
-			cronjob.Spec.JobTemplate.Spec.Template.Spec.Affinity = &corev1.Affinity{
-				NodeAffinity: &corev1.NodeAffinity{
-					RequiredDuringSchedulingIgnoredDuringExecution: &pieProbe.Spec.NodeSelector,
-				},
+			if nodeName != nil {
+				cronjob.Spec.JobTemplate.Spec.Template.Spec.Affinity = makeNodeAffinity(*nodeName)
+			} else {
+				cronjob.Spec.JobTemplate.Spec.Template.Spec.Affinity = &corev1.Affinity{
+					NodeAffinity: &corev1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: makeProvisionProbeNodeSelector(
+							pieProbe, topologyValue),
+					},
+				}
 			}
 
 			cronjob.Spec.JobTemplate.Spec.Template.Spec.Volumes = []corev1.Volume{
@@ -439,9 +1245,14 @@ func (r *PieProbeReconciler) createOrUpdateJob(
 					VolumeSource: corev1.VolumeSource{
 						Ephemeral: &corev1.EphemeralVolumeSource{
 							VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+								// The labels are used to find the PVs of the probes.
+								ObjectMeta: metav1.ObjectMeta{
+									Labels: label,
+								},
 								Spec: corev1.PersistentVolumeClaimSpec{
 									AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
 									StorageClassName: &storageClass,
+									VolumeMode:       &volumeMode,
 									Resources: corev1.VolumeResourceRequirements{
 										Requests: map[corev1.ResourceName]resource.Quantity{
 											corev1.ResourceStorage: *pieProbe.Spec.PVCCapacity,
@@ -454,41 +1265,63 @@ func (r *PieProbeReconciler) createOrUpdateJob(
 				},
 			}
 		case MountProbe:
-			container.VolumeMounts = []corev1.VolumeMount{
+			setProbeVolume(container, pieProbe, volumeName)
+			pvcName, err := getPVCName(*nodeName, pieProbe, storageClass)
+			if err != nil {
+				return err
+			}
+			// The Pod name is reported with the results to identify the run.
+			container.Env = []corev1.EnvVar{
 				{
-					Name:      volumeName,
-					MountPath: "/mounted",
+					Name: probePodNameEnv,
+					ValueFrom: &corev1.EnvVarSource{
+						FieldRef: &corev1.ObjectFieldSelector{
+							APIVersion: "v1",
+							FieldPath:  "metadata.name",
+						},
+					},
 				},
 			}
 			container.Args = []string{
 				"probe",
 				fmt.Sprintf("--destination-address=%s", r.controllerUrl),
-				"--path=/mounted/",
+				makeTargetArg(pieProbe),
 				fmt.Sprintf("--node-name=%s", *nodeName),
 				fmt.Sprintf("--storage-class=%s", storageClass),
 				fmt.Sprintf("--pie-probe-name=%s", pieProbe.GetName()),
+				fmt.Sprintf("--namespace=%s", pieProbe.GetNamespace()),
+				fmt.Sprintf("--pod-name=$(%s)", probePodNameEnv),
+				fmt.Sprintf("--pvc-name=%s", pvcName),
+				fmt.Sprintf("--timeout=%s", getMountProbeTimeout(pieProbe)),
 			}
-			cronjob.Spec.JobTemplate.Spec.Template.Spec.Affinity = &corev1.Affinity{
-				NodeAffinity: &corev1.NodeAffinity{
-					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
-						NodeSelectorTerms: []corev1.NodeSelectorTerm{
-							{
-								MatchExpressions: []corev1.NodeSelectorRequirement{
-									{
-										Key:      corev1.LabelHostname,
-										Operator: corev1.NodeSelectorOpIn,
-										Values:   []string{*nodeName},
-									},
-								},
-							},
-						},
-					},
-				},
-			}
-			pvcName, err := getPVCName(*nodeName, pieProbe)
+			pvName, err := r.getBoundPVName(ctx, pieProbe.GetNamespace(), pvcName)
 			if err != nil {
 				return err
 			}
+			if pvName != "" {
+				container.Args = append(container.Args, fmt.Sprintf("--pv-name=%s", pvName))
+			}
+			switch {
+			case pieProbe.Spec.ResultTransport == piev1alpha1.ResultTransportTerminationMessage:
+				// The results are read by ProbePodReconciler from the terminated states of the containers.
+				container.Args = append(container.Args,
+					fmt.Sprintf("--termination-message-path=%s", corev1.TerminationMessagePathDefault))
+			case pieProbe.Spec.ResultTransport == piev1alpha1.ResultTransportGRPC && r.controllerGRPCAddress != "":
+				// The results are posted to --destination-address if the gRPC receiver is not exposed.
+				container.Args = append(container.Args,
+					fmt.Sprintf("--grpc-destination-address=%s", r.controllerGRPCAddress))
+			}
+			if pieProbe.Spec.MetadataOperations != 0 && getVolumeMode(pieProbe) != corev1.PersistentVolumeBlock {
+				container.Args = append(container.Args,
+					fmt.Sprintf("--metadata-operations=%d", pieProbe.Spec.MetadataOperations))
+			}
+			if pieProbe.Spec.ResultTransport != piev1alpha1.ResultTransportTerminationMessage &&
+				pieProbe.Spec.SpoolResults && getVolumeMode(pieProbe) != corev1.PersistentVolumeBlock {
+				container.Args = append(container.Args, "--spool")
+			}
+			activeDeadlineSeconds := int64((getMountProbeTimeout(pieProbe) + mountProbeDeadlineMargin).Seconds())
+			cronjob.Spec.JobTemplate.Spec.ActiveDeadlineSeconds = &activeDeadlineSeconds
+			cronjob.Spec.JobTemplate.Spec.Template.Spec.Affinity = makeNodeAffinity(*nodeName)
 			cronjob.Spec.JobTemplate.Spec.Template.Spec.Volumes = []corev1.Volume{
 				{
 					Name: volumeName,
@@ -515,6 +1348,104 @@ func (r *PieProbeReconciler) createOrUpdateJob(
 	return nil
 }
 
+// getBoundPVName returns the name of the PV bound to the PVC, which is empty if the PVC is not bound yet.
+func (r *PieProbeReconciler) getBoundPVName(ctx context.Context, namespace, pvcName string) (string, error) {
+	var pvc corev1.PersistentVolumeClaim
+	err := r.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: pvcName}, &pvc)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return pvc.Spec.VolumeName, nil
+}
+
+// getMountProbeTimeout returns the time limit of the mount probes.
+// The Pod of a mount probe is started within probeThreshold, and the probe must finish before the next run.
+func getMountProbeTimeout(pieProbe *piev1alpha1.PieProbe) time.Duration {
+	return time.Duration(pieProbe.Spec.ProbePeriod)*time.Minute - pieProbe.Spec.ProbeThreshold.Duration
+}
+
+// getVolumeMode returns the volumeMode of the PVCs of the probes.
+func getVolumeMode(pieProbe *piev1alpha1.PieProbe) corev1.PersistentVolumeMode {
+	if pieProbe.Spec.VolumeMode == "" {
+		return corev1.PersistentVolumeFilesystem
+	}
+	return pieProbe.Spec.VolumeMode
+}
+
+// setProbeVolume makes the volume available to the probe container.
+// A Filesystem volume is mounted at /mounted, and a Block volume is attached at blockDevicePath.
+func setProbeVolume(container *corev1.Container, pieProbe *piev1alpha1.PieProbe, volumeName string) {
+	if getVolumeMode(pieProbe) == corev1.PersistentVolumeBlock {
+		container.VolumeMounts = nil
+		container.VolumeDevices = []corev1.VolumeDevice{
+			{
+				Name:       volumeName,
+				DevicePath: blockDevicePath,
+			},
+		}
+		return
+	}
+	container.VolumeDevices = nil
+	container.VolumeMounts = []corev1.VolumeMount{
+		{
+			Name:      volumeName,
+			MountPath: "/mounted",
+		},
+	}
+}
+
+// makeTargetArg returns the arg telling the probe where the volume is available.
+func makeTargetArg(pieProbe *piev1alpha1.PieProbe) string {
+	if getVolumeMode(pieProbe) == corev1.PersistentVolumeBlock {
+		return "--device-path=" + blockDevicePath
+	}
+	return "--path=/mounted/"
+}
+
+// makeNodeAffinity returns the affinity to schedule a Pod on the node.
+func makeNodeAffinity(nodeName string) *corev1.Affinity {
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{
+								Key:      corev1.LabelHostname,
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{nodeName},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// makeProvisionProbeNodeSelector returns the node selector of the PieProbe.
+// If topologyValue is specified, the nodes are also restricted to the topology.
+func makeProvisionProbeNodeSelector(pieProbe *piev1alpha1.PieProbe, topologyValue string) *corev1.NodeSelector {
+	if topologyValue == "" {
+		return &pieProbe.Spec.NodeSelector
+	}
+
+	nodeSelector := pieProbe.Spec.NodeSelector.DeepCopy()
+	requirement := corev1.NodeSelectorRequirement{
+		Key:      pieProbe.Spec.ProvisionProbeTopologyKey,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{topologyValue},
+	}
+	for i := range nodeSelector.NodeSelectorTerms {
+		term := &nodeSelector.NodeSelectorTerms[i]
+		term.MatchExpressions = append(term.MatchExpressions, requirement)
+	}
+	return nodeSelector
+}
+
 // CronJob name should be less than or equal to 52 characters.
 // cf. https://kubernetes.io/docs/concepts/workloads/controllers/cron-jobs/
 // One CronJob is created per node and a StorageClass.
@@ -522,17 +1453,26 @@ func (r *PieProbeReconciler) createOrUpdateJob(
 // However, if the node and StorageClass names are too long, the CronJob name will not fit in 52 characters.
 // So we cut off the node and StorageClass names to an appropriate length and added a hash value at the end
 // to balance readability and uniqueness.
-func getCronJobName(kind int, nodeNamePtr *string, pieProbe *piev1alpha1.PieProbe) (string, error) {
+func getCronJobName(
+	kind int,
+	nodeNamePtr *string,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	topologyValue string,
+) (string, error) {
 	nodeName := ""
 	if nodeNamePtr != nil {
 		nodeName = *nodeNamePtr
 	}
 
 	pieProbeName := pieProbe.Name
-	storageClass := pieProbe.Spec.MonitoringStorageClass
 
+	hashSource := pieProbeName + "\000" + nodeName + "\000" + storageClass
+	if topologyValue != "" {
+		hashSource += "\000" + topologyValue
+	}
 	sha1Hash := sha1.New()
-	_, err := io.WriteString(sha1Hash, pieProbeName+"\000"+nodeName+"\000"+storageClass)
+	_, err := io.WriteString(sha1Hash, hashSource)
 	if err != nil {
 		return "", fmt.Errorf("failed to hash cronjob name: %w", err)
 	}
@@ -548,7 +1488,22 @@ func getCronJobName(kind int, nodeNamePtr *string, pieProbe *piev1alpha1.PieProb
 		storageClass = storageClass[:12]
 	}
 
+	if len(topologyValue) > 11 {
+		topologyValue = topologyValue[:11]
+	}
+
 	if kind == ProvisionProbe {
+		if len(nodeName) > 11 {
+			nodeName = nodeName[:11]
+		}
+		if nodeName != "" {
+			return fmt.Sprintf("%s-%s-%s-%s-%s", constants.ProvisionProbeNamePrefix, pieProbeName, nodeName,
+				storageClass, hashedName[:6]), nil
+		}
+		if topologyValue != "" {
+			return fmt.Sprintf("%s-%s-%s-%s-%s", constants.ProvisionProbeNamePrefix, pieProbeName, topologyValue,
+				storageClass, hashedName[:6]), nil
+		}
 		return fmt.Sprintf("%s-%s-%s-%s", constants.ProvisionProbeNamePrefix, pieProbeName, storageClass, hashedName[:6]), nil
 	} else { // kind == MountProbe
 		return fmt.Sprintf("%s-%s-%s-%s-%s", constants.MountProbeNamePrefix, pieProbeName, nodeName, storageClass, hashedName[:6]), nil
@@ -559,10 +1514,23 @@ func NewPieProbeController(
 	client client.Client,
 	containerImage string,
 	controllerUrl string,
+	controllerGRPCAddress string,
+	exporter metrics.MetricsExporter,
 ) *PieProbeReconciler {
 	return &PieProbeReconciler{
 		client:         client,
 		containerImage: containerImage,
 		controllerUrl:  controllerUrl,
+		exporter:       exporter,
+
+		controllerGRPCAddress: controllerGRPCAddress,
+
+		stagedProbeRuns:         map[types.NamespacedName]*stagedProbeRun{},
+		stagedProbeLastRunTimes: map[types.NamespacedName]time.Time{},
+
+		mountReports: map[mountReportKey]*pietypes.MountInfo{},
+		mountEvents:  make(chan event.GenericEvent, mountEventBufferSize),
+
+		monitoredStorageClasses: map[types.NamespacedName]map[string]struct{}{},
 	}
 }
@@ -4,18 +4,25 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	"github.com/topolvm/pie/constants"
+	"github.com/topolvm/pie/metrics"
+	pietypes "github.com/topolvm/pie/types"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -24,6 +31,145 @@ import (
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 )
 
+// recordedCall is a call of a method of fakeMetricsExporter.
+// key identifies the series updated by the call, e.g. "<node>/<field>" for the mismatches
+// and the stage for the staged probes, and value is the value set to the series.
+type recordedCall struct {
+	method string
+	key    string
+	value  string
+}
+
+// fakeMetricsExporter records the calls of the methods called by PieProbeReconciler.
+// The other methods are not called by PieProbeReconciler.
+type fakeMetricsExporter struct {
+	metrics.MetricsExporter
+
+	mu    sync.Mutex
+	calls []recordedCall
+}
+
+func (f *fakeMetricsExporter) record(method, key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, recordedCall{method: method, key: key, value: value})
+}
+
+// getValues returns the values of the calls of the method for the key in the order they were called.
+func (f *fakeMetricsExporter) getValues(method, key string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	values := []string{}
+	for _, call := range f.calls {
+		if call.method == method && call.key == key {
+			values = append(values, call.value)
+		}
+	}
+	return values
+}
+
+// getLatestValue returns the value of the latest call of the method for the key,
+// or "" if the method has not been called for the key.
+func (f *fakeMetricsExporter) getLatestValue(method, key string) string {
+	values := f.getValues(method, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+func (f *fakeMetricsExporter) DeleteMetricsOfStorageClass(namespace, pieProbeName, storageClass string) {
+	f.record("DeleteMetricsOfStorageClass", storageClass, "")
+}
+
+func (f *fakeMetricsExporter) DeleteMetricsOfPieProbe(namespace, pieProbeName string) {
+	f.record("DeleteMetricsOfPieProbe", pieProbeName, "")
+}
+
+func (f *fakeMetricsExporter) SetPVCRotationDeleteDuration(
+	namespace, pieProbeName, node, storageClass string,
+	duration float64,
+) {
+	f.record("SetPVCRotationDeleteDuration", node, fmt.Sprint(duration))
+}
+
+func (f *fakeMetricsExporter) SetPVCRotationRebindDuration(
+	namespace, pieProbeName, node, storageClass string,
+	duration float64,
+) {
+	f.record("SetPVCRotationRebindDuration", node, fmt.Sprint(duration))
+}
+
+func (f *fakeMetricsExporter) IncrementSnapshotProbeCount(
+	namespace, pieProbeName, node, storageClass, stage string,
+	succeed bool,
+) {
+	f.record("IncrementSnapshotProbeCount", stage, strconv.FormatBool(succeed))
+}
+
+func (f *fakeMetricsExporter) SetSnapshotProbeDuration(
+	namespace, pieProbeName, node, storageClass, stage string,
+	duration float64,
+) {
+	f.record("SetSnapshotProbeDuration", stage, fmt.Sprint(duration))
+}
+
+func (f *fakeMetricsExporter) IncrementExpansionProbeCount(
+	namespace, pieProbeName, node, storageClass, stage string,
+	succeed bool,
+) {
+	f.record("IncrementExpansionProbeCount", stage, strconv.FormatBool(succeed))
+}
+
+func (f *fakeMetricsExporter) SetExpansionProbeDuration(
+	namespace, pieProbeName, node, storageClass, stage string,
+	duration float64,
+) {
+	f.record("SetExpansionProbeDuration", stage, fmt.Sprint(duration))
+}
+
+func (f *fakeMetricsExporter) IncrementCloneProbeCount(
+	namespace, pieProbeName, node, storageClass, stage string,
+	succeed bool,
+) {
+	f.record("IncrementCloneProbeCount", stage, strconv.FormatBool(succeed))
+}
+
+func (f *fakeMetricsExporter) SetCloneProbeDuration(
+	namespace, pieProbeName, node, storageClass, stage string,
+	duration float64,
+) {
+	f.record("SetCloneProbeDuration", stage, fmt.Sprint(duration))
+}
+
+func (f *fakeMetricsExporter) IncrementFailoverProbeCount(
+	namespace, pieProbeName, storageClass, stage string,
+	onTime bool,
+) {
+	f.record("IncrementFailoverProbeCount", stage, strconv.FormatBool(onTime))
+}
+
+func (f *fakeMetricsExporter) SetFailoverProbeDuration(
+	namespace, pieProbeName, storageClass, stage string,
+	duration float64,
+) {
+	f.record("SetFailoverProbeDuration", stage, fmt.Sprint(duration))
+}
+
+func (f *fakeMetricsExporter) SetPersistentVolumeMismatched(
+	namespace, pieProbeName, node, storageClass, field string,
+	mismatched bool,
+) {
+	f.record("SetPersistentVolumeMismatched", node+"/"+field, strconv.FormatBool(mismatched))
+}
+
+func (f *fakeMetricsExporter) SetMountMismatched(
+	namespace, pieProbeName, node, storageClass, field string,
+	mismatched bool,
+) {
+	f.record("SetMountMismatched", node+"/"+field, strconv.FormatBool(mismatched))
+}
+
 func prepareObjects(ctx context.Context) error {
 	_ = log.FromContext(ctx)
 
@@ -38,11 +184,13 @@ func prepareObjects(ctx context.Context) error {
 		return err
 	}
 
+	allowVolumeExpansion := true
 	storageClass2 := &storagev1.StorageClass{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "sc2",
 		},
-		Provisioner: "sc-provisioner",
+		Provisioner:          "sc-provisioner",
+		AllowVolumeExpansion: &allowVolumeExpansion,
 	}
 	_, err = ctrl.CreateOrUpdate(ctx, k8sClient, storageClass2, func() error { return nil })
 	if err != nil {
@@ -97,14 +245,67 @@ func prepareObjects(ctx context.Context) error {
 	return nil
 }
 
+// newPieProbe returns a PieProbe in the default namespace probing the StorageClass every minute
+// on the nodes created by prepareObjects. The tests set the other fields they need.
+func newPieProbe(name, storageClass string) *piev1alpha1.PieProbe {
+	return &piev1alpha1.PieProbe{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+		},
+		Spec: piev1alpha1.PieProbeSpec{
+			MonitoringStorageClass: storageClass,
+			NodeSelector: corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{
+								Key:      "key1",
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{"value1"},
+							},
+						},
+					},
+				},
+			},
+			ProbePeriod: 1,
+		},
+	}
+}
+
+// listCronJobs returns the CronJobs matching the labels.
+func listCronJobs(ctx context.Context, g Gomega, labels client.MatchingLabels) batchv1.CronJobList {
+	var cronjobList batchv1.CronJobList
+	err := k8sClient.List(ctx, &cronjobList, labels)
+	g.Expect(err).NotTo(HaveOccurred())
+	return cronjobList
+}
+
+// listPVCs returns the PVCs matching the labels.
+func listPVCs(ctx context.Context, g Gomega, labels client.MatchingLabels) corev1.PersistentVolumeClaimList {
+	var pvcList corev1.PersistentVolumeClaimList
+	err := k8sClient.List(ctx, &pvcList, labels)
+	g.Expect(err).NotTo(HaveOccurred())
+	return pvcList
+}
+
 func deletePieProbeAndReferencingResources(ctx context.Context, pieProbe *piev1alpha1.PieProbe) error {
-	if err := k8sClient.Delete(ctx, pieProbe); err != nil {
+	if err := k8sClient.Delete(ctx, pieProbe); err != nil && !apierrors.IsNotFound(err) {
 		return fmt.Errorf("failed to delete PieProbe %s: %w", pieProbe.Name, err)
 	}
+	// The PieProbe is deleted without waiting for the controller to remove the finalizer.
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pieProbe), pieProbe); err == nil {
+		pieProbe.Finalizers = []string{}
+		if err := k8sClient.Update(ctx, pieProbe); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to update PieProbe %s: %w", pieProbe.Name, err)
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get PieProbe %s: %w", pieProbe.Name, err)
+	}
 
 	var pvcList corev1.PersistentVolumeClaimList
 	if err := k8sClient.List(ctx, &pvcList, client.MatchingLabels(map[string]string{
-		"storage-class": pieProbe.Spec.MonitoringStorageClass,
+		"pie-probe": pieProbe.Name,
 	})); err != nil {
 		return fmt.Errorf("failed to list PVCs: %w", err)
 	}
@@ -121,7 +322,7 @@ func deletePieProbeAndReferencingResources(ctx context.Context, pieProbe *piev1a
 
 	var cronjobList batchv1.CronJobList
 	if err := k8sClient.List(ctx, &cronjobList, client.MatchingLabels(map[string]string{
-		"storage-class": pieProbe.Spec.MonitoringStorageClass,
+		"pie-probe": pieProbe.Name,
 	})); err != nil {
 		return fmt.Errorf("failed to list CronJobs: %w", err)
 	}
@@ -171,6 +372,8 @@ var _ = Describe("PieProbe controller for specifying resources", func() {
 			k8sClient,
 			"dummy.image",
 			"http://localhost:8082",
+			"localhost:8083",
+			&fakeMetricsExporter{},
 		)
 		err = pieProbeReconciler.SetupWithManager(mgr)
 		Expect(err).NotTo(HaveOccurred())
@@ -242,6 +445,8 @@ var _ = Describe("PieProbe controller for specifying resources", func() {
 var _ = Describe("PieProbe controller", func() {
 	ctx := context.Background()
 	var stopFunc func()
+	var exporter *fakeMetricsExporter
+	var reconciler *PieProbeReconciler
 
 	nodeSelector := corev1.NodeSelector{
 		NodeSelectorTerms: []corev1.NodeSelectorTerm{
@@ -271,12 +476,15 @@ var _ = Describe("PieProbe controller", func() {
 		err = prepareObjects(ctx)
 		Expect(err).NotTo(HaveOccurred())
 
-		pieProbeReconciler := NewPieProbeController(
+		exporter = &fakeMetricsExporter{}
+		reconciler = NewPieProbeController(
 			k8sClient,
 			"dummy.image",
 			"http://localhost:8082",
+			"localhost:8083",
+			exporter,
 		)
-		err = pieProbeReconciler.SetupWithManager(mgr)
+		err = reconciler.SetupWithManager(mgr)
 		Expect(err).NotTo(HaveOccurred())
 
 		pieProbe := &piev1alpha1.PieProbe{
@@ -389,6 +597,171 @@ var _ = Describe("PieProbe controller", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	It("should report the PVs of the mount probes not matching the nodes, the StorageClass or pvcCapacity", func() {
+		By("creating a new PieProbe")
+		nodeName := "192.168.0.1"
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.DisableProvisionProbe = true
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+
+		By("creating a PV for another node, another driver and a smaller capacity")
+		pv := &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pv-mismatched",
+			},
+			Spec: corev1.PersistentVolumeSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Capacity: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse("50Mi"),
+				},
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{
+						Driver:       "other-provisioner",
+						VolumeHandle: "pv-mismatched",
+					},
+				},
+				NodeAffinity: &corev1.VolumeNodeAffinity{
+					Required: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{
+										Key:      "key1",
+										Operator: corev1.NodeSelectorOpDoesNotExist,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pv)).To(Succeed())
+		defer func() {
+			err := deletePieProbeAndReferencingResources(ctx, pieProbe2)
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Delete(ctx, pv)
+			Expect(client.IgnoreNotFound(err)).NotTo(HaveOccurred())
+		}()
+
+		By("binding the mount-probe PVC of the node to the PV")
+		Eventually(func(g Gomega) {
+			pvcList := listPVCs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+				"node":          nodeName,
+			})
+			g.Expect(pvcList.Items).To(HaveLen(1))
+			pvc := pvcList.Items[0]
+			pvc.Spec.VolumeName = pv.GetName()
+			g.Expect(k8sClient.Update(ctx, &pvc)).To(Succeed())
+			pvc.Status.Phase = corev1.ClaimBound
+			g.Expect(k8sClient.Status().Update(ctx, &pvc)).To(Succeed())
+		}).Should(Succeed())
+
+		By("checking the mismatches are reported")
+		Eventually(func(g Gomega) {
+			g.Expect(exporter.getLatestValue("SetPersistentVolumeMismatched", nodeName+"/node_affinity")).To(Equal("true"))
+			g.Expect(exporter.getLatestValue("SetPersistentVolumeMismatched", nodeName+"/driver")).To(Equal("true"))
+			g.Expect(exporter.getLatestValue("SetPersistentVolumeMismatched", nodeName+"/capacity")).To(Equal("true"))
+
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pieProbe2), pieProbe2)
+			g.Expect(err).NotTo(HaveOccurred())
+			condition := meta.FindStatusCondition(pieProbe2.Status.Conditions,
+				piev1alpha1.PieProbeConditionPersistentVolumeMismatched)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			g.Expect(condition.Message).To(ContainSubstring("pv-mismatched (node_affinity, driver, capacity)"))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+	})
+
+	It("should report the mounts of the mount probes not matching the fstype or mountOptions of the StorageClass", func() {
+		nodeName := "192.168.0.1"
+
+		By("creating a StorageClass specifying the fstype and mountOptions")
+		storageClass := &storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "sc-xfs",
+			},
+			Provisioner:  "sc-provisioner",
+			Parameters:   map[string]string{"csi.storage.k8s.io/fstype": "xfs"},
+			MountOptions: []string{"noatime", "discard"},
+		}
+		Expect(k8sClient.Create(ctx, storageClass)).To(Succeed())
+
+		By("creating a new PieProbe")
+		pieProbe2 := newPieProbe("pie-probe-xfs", "sc-xfs")
+		pieProbe2.Spec.DisableProvisionProbe = true
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			err := deletePieProbeAndReferencingResources(ctx, pieProbe2)
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Delete(ctx, storageClass)
+			Expect(client.IgnoreNotFound(err)).NotTo(HaveOccurred())
+		}()
+
+		By("reporting a read-only ext4 mount without the mountOptions")
+		reconciler.ObserveMount(pieProbe2.GetNamespace(), pieProbe2.GetName(), nodeName, "sc-xfs", &pietypes.MountInfo{
+			FilesystemType: "ext4",
+			MountOptions:   []string{"ro", "relatime"},
+			ReadOnly:       true,
+		})
+
+		By("checking the mismatches are reported")
+		Eventually(func(g Gomega) {
+			g.Expect(exporter.getLatestValue("SetMountMismatched", nodeName+"/fstype")).To(Equal("true"))
+			g.Expect(exporter.getLatestValue("SetMountMismatched", nodeName+"/mount_options")).To(Equal("true"))
+			g.Expect(exporter.getLatestValue("SetMountMismatched", nodeName+"/read_only")).To(Equal("true"))
+
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pieProbe2), pieProbe2)
+			g.Expect(err).NotTo(HaveOccurred())
+			condition := meta.FindStatusCondition(pieProbe2.Status.Conditions,
+				piev1alpha1.PieProbeConditionMountMismatched)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			g.Expect(condition.Message).To(ContainSubstring(
+				"sc-xfs on " + nodeName + " (fstype, mount_options, read_only)"))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+
+		By("reporting the mount matching the StorageClass")
+		reconciler.ObserveMount(pieProbe2.GetNamespace(), pieProbe2.GetName(), nodeName, "sc-xfs", &pietypes.MountInfo{
+			FilesystemType: "xfs",
+			MountOptions:   []string{"rw", "noatime", "attr2", "discard"},
+		})
+
+		By("checking the mismatches are resolved")
+		Eventually(func(g Gomega) {
+			g.Expect(exporter.getLatestValue("SetMountMismatched", nodeName+"/fstype")).To(Equal("false"))
+			g.Expect(exporter.getLatestValue("SetMountMismatched", nodeName+"/mount_options")).To(Equal("false"))
+			g.Expect(exporter.getLatestValue("SetMountMismatched", nodeName+"/read_only")).To(Equal("false"))
+
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pieProbe2), pieProbe2)
+			g.Expect(err).NotTo(HaveOccurred())
+			condition := meta.FindStatusCondition(pieProbe2.Status.Conditions,
+				piev1alpha1.PieProbeConditionMountMismatched)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+
+		By("reporting a mismatched mount of the PieProbe with the same name in another namespace")
+		reconciler.ObserveMount("other", pieProbe2.GetName(), nodeName, "sc-xfs", &pietypes.MountInfo{
+			FilesystemType: "ext4",
+		})
+
+		By("checking the mount of the PieProbe is still verified as matched")
+		Consistently(func(g Gomega) {
+			g.Expect(exporter.getLatestValue("SetMountMismatched", nodeName+"/fstype")).To(Equal("false"))
+
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pieProbe2), pieProbe2)
+			g.Expect(err).NotTo(HaveOccurred())
+			condition := meta.FindStatusCondition(pieProbe2.Status.Conditions,
+				piev1alpha1.PieProbeConditionMountMismatched)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		}).WithTimeout(3 * time.Second).Should(Succeed())
+	})
+
 	It("should create only mount probes if .spec.disableProvisionProbes is true", func() {
 		By("creating a new PieProbe with .spec.disableProvisionProbes true")
 		pieProbe2 := &piev1alpha1.PieProbe{
@@ -424,6 +797,250 @@ var _ = Describe("PieProbe controller", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	It("should attach raw block devices to the probes if .spec.volumeMode is Block", func() {
+		By("creating a new PieProbe with .spec.volumeMode Block")
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.VolumeMode = corev1.PersistentVolumeBlock
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the PVCs and the CronJobs use the Block volumeMode")
+		Eventually(func(g Gomega) {
+			pvcList := listPVCs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(pvcList.Items).To(HaveLen(2))
+			for _, pvc := range pvcList.Items {
+				g.Expect(pvc.Spec.VolumeMode).To(HaveValue(Equal(corev1.PersistentVolumeBlock)))
+			}
+
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(4))
+			for _, cronjob := range cronjobList.Items {
+				template := cronjob.Spec.JobTemplate.Spec.Template
+				g.Expect(template.GetLabels()).To(HaveKeyWithValue("volume-mode", "Block"))
+				if strings.HasPrefix(cronjob.GetName(), "provision-") {
+					g.Expect(template.Spec.Volumes[0].Ephemeral.VolumeClaimTemplate.Spec.VolumeMode).
+						To(HaveValue(Equal(corev1.PersistentVolumeBlock)))
+					continue
+				}
+				container := template.Spec.Containers[0]
+				g.Expect(container.VolumeMounts).To(BeEmpty())
+				g.Expect(container.VolumeDevices).To(ConsistOf(corev1.VolumeDevice{
+					Name:       "genericvol",
+					DevicePath: "/dev/pie-block",
+				}))
+				g.Expect(container.Args).To(ContainElement("--device-path=/dev/pie-block"))
+				g.Expect(container.Args).NotTo(ContainElement("--path=/mounted/"))
+			}
+		}).Should(Succeed())
+
+		By("checking .spec.volumeMode is immutable")
+		pieProbe2.Spec.VolumeMode = corev1.PersistentVolumeFilesystem
+		err = k8sClient.Update(ctx, pieProbe2)
+		Expect(err).To(HaveOccurred())
+
+		By("cleaning up PVCs and CronJobs for sc2")
+		err = deletePieProbeAndReferencingResources(ctx, pieProbe2)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should make the mount probes spool their results if .spec.spoolResults is true", func() {
+		By("creating a new PieProbe with .spec.spoolResults")
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.DisableProvisionProbe = true
+		pieProbe2.Spec.SpoolResults = true
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the mount probes are run with --spool")
+		Eventually(func(g Gomega) {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(2))
+			for _, cronjob := range cronjobList.Items {
+				g.Expect(cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--spool"))
+			}
+		}).Should(Succeed())
+
+		By("disabling .spec.spoolResults")
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pieProbe2), pieProbe2)
+			g.Expect(err).NotTo(HaveOccurred())
+			pieProbe2.Spec.SpoolResults = false
+			g.Expect(k8sClient.Update(ctx, pieProbe2)).To(Succeed())
+		}).Should(Succeed())
+
+		By("checking the mount probes are run without --spool")
+		Eventually(func(g Gomega) {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(2))
+			for _, cronjob := range cronjobList.Items {
+				g.Expect(cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args).NotTo(ContainElement("--spool"))
+			}
+		}).Should(Succeed())
+
+		By("cleaning up PVCs and CronJobs for sc2")
+		err = deletePieProbeAndReferencingResources(ctx, pieProbe2)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should pass the result transports of .spec.resultTransport to the mount probes", func() {
+		By("creating a new PieProbe with .spec.resultTransport TerminationMessage and .spec.spoolResults")
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.DisableProvisionProbe = true
+		pieProbe2.Spec.SpoolResults = true
+		pieProbe2.Spec.ResultTransport = piev1alpha1.ResultTransportTerminationMessage
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the mount probes are run with --termination-message-path and without --spool")
+		Eventually(func(g Gomega) {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(2))
+			for _, cronjob := range cronjobList.Items {
+				args := cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args
+				g.Expect(args).To(ContainElement("--termination-message-path=/dev/termination-log"))
+				g.Expect(args).NotTo(ContainElement("--spool"))
+			}
+		}).Should(Succeed())
+
+		By("switching .spec.resultTransport to HTTP")
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pieProbe2), pieProbe2)
+			g.Expect(err).NotTo(HaveOccurred())
+			pieProbe2.Spec.ResultTransport = piev1alpha1.ResultTransportHTTP
+			g.Expect(k8sClient.Update(ctx, pieProbe2)).To(Succeed())
+		}).Should(Succeed())
+
+		By("checking the mount probes are run with --spool and without --termination-message-path")
+		Eventually(func(g Gomega) {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(2))
+			for _, cronjob := range cronjobList.Items {
+				args := cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args
+				g.Expect(args).To(ContainElement("--spool"))
+				g.Expect(args).NotTo(ContainElement(HavePrefix("--termination-message-path")))
+				g.Expect(args).NotTo(ContainElement(HavePrefix("--grpc-destination-address")))
+			}
+		}).Should(Succeed())
+
+		By("switching .spec.resultTransport to GRPC")
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pieProbe2), pieProbe2)
+			g.Expect(err).NotTo(HaveOccurred())
+			pieProbe2.Spec.ResultTransport = piev1alpha1.ResultTransportGRPC
+			g.Expect(k8sClient.Update(ctx, pieProbe2)).To(Succeed())
+		}).Should(Succeed())
+
+		By("checking the mount probes are run with --grpc-destination-address and --spool")
+		Eventually(func(g Gomega) {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(2))
+			for _, cronjob := range cronjobList.Items {
+				args := cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args
+				g.Expect(args).To(ContainElements("--grpc-destination-address=localhost:8083", "--spool"))
+				g.Expect(args).NotTo(ContainElement(HavePrefix("--termination-message-path")))
+			}
+		}).Should(Succeed())
+
+		By("cleaning up PVCs and CronJobs for sc2")
+		err = deletePieProbeAndReferencingResources(ctx, pieProbe2)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should pass the identities of the Pods and the PVCs to the mount probes", func() {
+		By("creating a new PieProbe")
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.DisableProvisionProbe = true
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the mount probes are run with the names of their Pods and PVCs")
+		Eventually(func(g Gomega) {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(2))
+			for _, cronjob := range cronjobList.Items {
+				podSpec := cronjob.Spec.JobTemplate.Spec.Template.Spec
+				container := podSpec.Containers[0]
+				g.Expect(container.Args).To(ContainElements(
+					"--namespace=default",
+					"--pod-name=$(POD_NAME)",
+					"--pvc-name="+podSpec.Volumes[0].PersistentVolumeClaim.ClaimName,
+				))
+				g.Expect(container.Env).To(ContainElement(HaveField("ValueFrom.FieldRef.FieldPath", "metadata.name")))
+			}
+		}).Should(Succeed())
+
+		By("cleaning up PVCs and CronJobs for sc2")
+		err = deletePieProbeAndReferencingResources(ctx, pieProbe2)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should make the mount probes benchmark the metadata if .spec.metadataOperations is set", func() {
+		By("creating a new PieProbe")
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.DisableProvisionProbe = true
+		pieProbe2.Spec.MetadataOperations = 50
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the mount probes are run with --metadata-operations")
+		Eventually(func(g Gomega) {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(2))
+			for _, cronjob := range cronjobList.Items {
+				g.Expect(cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args).
+					To(ContainElement("--metadata-operations=50"))
+			}
+		}).Should(Succeed())
+
+		By("cleaning up PVCs and CronJobs for sc2")
+		err = deletePieProbeAndReferencingResources(ctx, pieProbe2)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should bound the mount probes by the timeout derived from .spec.probePeriod and .spec.probeThreshold", func() {
+		By("creating a new PieProbe")
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.ProbePeriod = 3
+		pieProbe2.Spec.ProbeThreshold = metav1.Duration{Duration: time.Minute}
+		pieProbe2.Spec.DisableProvisionProbe = true
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the mount probes time out before the Jobs are terminated")
+		Eventually(func(g Gomega) {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(2))
+			for _, cronjob := range cronjobList.Items {
+				g.Expect(cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--timeout=2m0s"))
+				g.Expect(cronjob.Spec.JobTemplate.Spec.ActiveDeadlineSeconds).To(HaveValue(BeEquivalentTo(130)))
+			}
+		}).Should(Succeed())
+
+		By("cleaning up PVCs and CronJobs for sc2")
+		err = deletePieProbeAndReferencingResources(ctx, pieProbe2)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
 	It("should create only provision probes if .spec.disableMountProbes is true", func() {
 		By("creating a new PieProbe with .spec.disableMountProbes true")
 		pieProbe2 := &piev1alpha1.PieProbe{
@@ -459,6 +1076,1055 @@ var _ = Describe("PieProbe controller", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	It("should create probes for each StorageClass selected by .spec.storageClassSelector", func() {
+		By("creating StorageClasses with labels")
+		for _, name := range []string{"sc-selected1", "sc-selected2"} {
+			storageClass := &storagev1.StorageClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name,
+					Labels: map[string]string{"pie.topolvm.io/monitored": "true"},
+				},
+				Provisioner: "sc-provisioner",
+			}
+			_, err := ctrl.CreateOrUpdate(ctx, k8sClient, storageClass, func() error { return nil })
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		By("creating a new PieProbe with .spec.storageClassSelector")
+		pieProbe2 := newPieProbe("pie-probe-selector", "")
+		pieProbe2.Spec.StorageClassSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"pie.topolvm.io/monitored": "true"},
+		}
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking probes exist for each selected StorageClass")
+		for _, name := range []string{"sc-selected1", "sc-selected2"} {
+			Eventually(func(g Gomega) {
+				cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+					"storage-class": name,
+					"pie-probe":     "pie-probe-selector",
+				})
+				g.Expect(cronjobList.Items).To(HaveLen(3))
+
+				pvcList := listPVCs(ctx, g, client.MatchingLabels{
+					"storage-class": name,
+					"pie-probe":     "pie-probe-selector",
+				})
+				g.Expect(pvcList.Items).To(HaveLen(2))
+			}).Should(Succeed())
+		}
+
+		By("removing the label from a StorageClass")
+		var storageClass storagev1.StorageClass
+		err = k8sClient.Get(ctx, client.ObjectKey{Name: "sc-selected2"}, &storageClass)
+		Expect(err).NotTo(HaveOccurred())
+		storageClass.Labels = nil
+		err = k8sClient.Update(ctx, &storageClass)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the probes for the unselected StorageClass are deleted")
+		Eventually(func(g Gomega) {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc-selected2",
+			})
+			g.Expect(cronjobList.Items).To(BeEmpty())
+
+			// Note that the PVCs are not deleted because the finalizer won't be removed in envtest.
+			pvcList := listPVCs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc-selected2",
+			})
+			for _, pvc := range pvcList.Items {
+				g.Expect(pvc.DeletionTimestamp).NotTo(BeNil())
+			}
+
+			err = k8sClient.List(ctx, &cronjobList, client.MatchingLabels(map[string]string{
+				"storage-class": "sc-selected1",
+			}))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cronjobList.Items).To(HaveLen(3))
+		}).Should(Succeed())
+
+		By("cleaning up PVCs, CronJobs and StorageClasses")
+		err = deletePieProbeAndReferencingResources(ctx, pieProbe2)
+		Expect(err).NotTo(HaveOccurred())
+		for _, name := range []string{"sc-selected1", "sc-selected2"} {
+			err = k8sClient.Delete(ctx, &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: name}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	It("should reject a PieProbe specifying both monitoringStorageClass and storageClassSelector", func() {
+		pieProbe2 := newPieProbe("pie-probe-invalid", "sc")
+		pieProbe2.Spec.StorageClassSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"pie.topolvm.io/monitored": "true"},
+		}
+		err := k8sClient.Create(ctx, pieProbe2)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject a PieProbe specifying sharedAccessProbe with a resultTransport other than HTTP", func() {
+		for _, transport := range []piev1alpha1.ResultTransport{
+			piev1alpha1.ResultTransportGRPC,
+			piev1alpha1.ResultTransportTerminationMessage,
+		} {
+			pieProbe2 := newPieProbe("pie-probe-invalid", "sc")
+			pieProbe2.Spec.ResultTransport = transport
+			pieProbe2.Spec.SharedAccessProbe = &piev1alpha1.SharedAccessProbeSpec{
+				Period:   1,
+				Deadline: metav1.Duration{Duration: 30 * time.Second},
+				Timeout:  metav1.Duration{Duration: 5 * time.Minute},
+			}
+			err := k8sClient.Create(ctx, pieProbe2)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("sharedAccessProbe requires the HTTP resultTransport"))
+		}
+	})
+
+	It("should follow the creation and deletion of the StorageClass", func() {
+		getCondition := func(g Gomega) *metav1.Condition {
+			var pieProbe piev1alpha1.PieProbe
+			err := k8sClient.Get(ctx, client.ObjectKey{Name: "pie-probe-sc3", Namespace: "default"}, &pieProbe)
+			g.Expect(err).NotTo(HaveOccurred())
+			return meta.FindStatusCondition(pieProbe.Status.Conditions, piev1alpha1.PieProbeConditionStorageClassNotFound)
+		}
+		listCronJobs := func(g Gomega) []batchv1.CronJob {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc3",
+			})
+			return cronjobList.Items
+		}
+
+		By("creating a new PieProbe for a StorageClass not existing")
+		pieProbe2 := newPieProbe("pie-probe-sc3", "sc3")
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the StorageClassNotFound condition is true")
+		Eventually(func(g Gomega) {
+			condition := getCondition(g)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			g.Expect(listCronJobs(g)).To(BeEmpty())
+		}).Should(Succeed())
+
+		By("creating the StorageClass")
+		storageClass := &storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "sc3",
+			},
+			Provisioner: "sc-provisioner",
+		}
+		err = k8sClient.Create(ctx, storageClass)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the probes are created")
+		Eventually(func(g Gomega) {
+			condition := getCondition(g)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			g.Expect(listCronJobs(g)).To(HaveLen(3))
+		}).Should(Succeed())
+
+		By("deleting the StorageClass")
+		err = k8sClient.Delete(ctx, storageClass)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the probes and the metrics are deleted")
+		Eventually(func(g Gomega) {
+			condition := getCondition(g)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			g.Expect(listCronJobs(g)).To(BeEmpty())
+			g.Expect(exporter.getValues("DeleteMetricsOfStorageClass", "sc3")).NotTo(BeEmpty())
+		}).Should(Succeed())
+
+		By("cleaning up PVCs and CronJobs for sc3")
+		err = deletePieProbeAndReferencingResources(ctx, pieProbe2)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should delete the metrics of the PieProbe when it is deleted", func() {
+		By("creating a new PieProbe")
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the finalizer is added")
+		Eventually(func(g Gomega) {
+			var pieProbe piev1alpha1.PieProbe
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pieProbe2), &pieProbe)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(pieProbe.Finalizers).To(ContainElement("pie.topolvm.io/pieprobe"))
+		}).Should(Succeed())
+
+		By("deleting the PieProbe")
+		err = k8sClient.Delete(ctx, pieProbe2)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the metrics are deleted before the PieProbe is gone")
+		Eventually(func(g Gomega) {
+			var pieProbe piev1alpha1.PieProbe
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pieProbe2), &pieProbe)
+			g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			g.Expect(exporter.getValues("DeleteMetricsOfPieProbe", "pie-probe-sc2")).NotTo(BeEmpty())
+		}).Should(Succeed())
+
+		By("cleaning up PVCs and CronJobs for sc2")
+		err = deletePieProbeAndReferencingResources(ctx, pieProbe2)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should create a provision probe for each topology value if .spec.provisionProbeTopologyKey is set", func() {
+		By("creating nodes in multiple zones")
+		zones := map[string]string{
+			"zone-node-a1": "zone-a",
+			"zone-node-b1": "zone-b",
+			"zone-node-b2": "zone-b",
+		}
+		for name, zone := range zones {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: name,
+					Labels: map[string]string{
+						"zone-test":                   "true",
+						"topology.kubernetes.io/zone": zone,
+					},
+				},
+			}
+			_, err := ctrl.CreateOrUpdate(ctx, k8sClient, node, func() error { return nil })
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		By("creating a new PieProbe with .spec.provisionProbeTopologyKey")
+		zoneNodeSelector := corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{
+							Key:      "zone-test",
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   []string{"true"},
+						},
+					},
+				},
+			},
+		}
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.NodeSelector = zoneNodeSelector
+		pieProbe2.Spec.DisableMountProbes = true
+		pieProbe2.Spec.ProvisionProbeTopologyKey = "topology.kubernetes.io/zone"
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking a provision probe exists for each zone")
+		Eventually(func(g Gomega) {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(2))
+			for _, cronjob := range cronjobList.Items {
+				g.Expect(cronjob.GetName()).To(HavePrefix("provision-"))
+				zone := cronjob.GetLabels()["topology"]
+				g.Expect(zone).To(BeElementOf("zone-a", "zone-b"))
+
+				terms := cronjob.Spec.JobTemplate.Spec.Template.Spec.Affinity.NodeAffinity.
+					RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+				g.Expect(terms).To(HaveLen(1))
+				g.Expect(terms[0].MatchExpressions).To(ConsistOf(
+					zoneNodeSelector.NodeSelectorTerms[0].MatchExpressions[0],
+					corev1.NodeSelectorRequirement{
+						Key:      "topology.kubernetes.io/zone",
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   []string{zone},
+					},
+				))
+			}
+		}).Should(Succeed())
+
+		By("deleting the node in zone-a")
+		err = k8sClient.Delete(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "zone-node-a1"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the provision probe for zone-a is deleted")
+		Eventually(func(g Gomega) {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(1))
+			g.Expect(cronjobList.Items[0].GetLabels()).To(HaveKeyWithValue("topology", "zone-b"))
+		}).Should(Succeed())
+
+		By("cleaning up CronJobs for sc2 and nodes")
+		err = deletePieProbeAndReferencingResources(ctx, pieProbe2)
+		Expect(err).NotTo(HaveOccurred())
+		for name := range zones {
+			err = k8sClient.Delete(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}})
+			Expect(client.IgnoreNotFound(err)).NotTo(HaveOccurred())
+		}
+	})
+
+	It("should report the topology key matching no node", func() {
+		By("creating a new PieProbe with .spec.provisionProbeTopologyKey no node has")
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.DisableMountProbes = true
+		pieProbe2.Spec.ProvisionProbeTopologyKey = "topology.kubernetes.io/no-such-zone"
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the TopologyNotFound condition is true and no provision probe is created")
+		Eventually(func(g Gomega) {
+			var pieProbe piev1alpha1.PieProbe
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pieProbe2), &pieProbe)
+			g.Expect(err).NotTo(HaveOccurred())
+			condition := meta.FindStatusCondition(pieProbe.Status.Conditions,
+				piev1alpha1.PieProbeConditionTopologyNotFound)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).To(BeEmpty())
+		}).Should(Succeed())
+
+		By("labeling a node with the topology key")
+		var node corev1.Node
+		err = k8sClient.Get(ctx, client.ObjectKey{Name: "192.168.0.1"}, &node)
+		Expect(err).NotTo(HaveOccurred())
+		node.Labels["topology.kubernetes.io/no-such-zone"] = "zone-a"
+		err = k8sClient.Update(ctx, &node)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			err := k8sClient.Get(ctx, client.ObjectKey{Name: "192.168.0.1"}, &node)
+			Expect(err).NotTo(HaveOccurred())
+			delete(node.Labels, "topology.kubernetes.io/no-such-zone")
+			err = k8sClient.Update(ctx, &node)
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		By("checking the TopologyNotFound condition is false and the provision probe is created")
+		Eventually(func(g Gomega) {
+			var pieProbe piev1alpha1.PieProbe
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pieProbe2), &pieProbe)
+			g.Expect(err).NotTo(HaveOccurred())
+			condition := meta.FindStatusCondition(pieProbe.Status.Conditions,
+				piev1alpha1.PieProbeConditionTopologyNotFound)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(1))
+			g.Expect(cronjobList.Items[0].GetLabels()).To(HaveKeyWithValue("topology", "zone-a"))
+		}).Should(Succeed())
+
+		By("cleaning up CronJobs for sc2")
+		err = deletePieProbeAndReferencingResources(ctx, pieProbe2)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should create a provision probe for each node if .spec.provisionProbePerNode is true", func() {
+		By("creating a new PieProbe with .spec.provisionProbePerNode true")
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.DisableMountProbes = true
+		pieProbe2.Spec.ProvisionProbePerNode = true
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking a provision probe exists for each node")
+		Eventually(func(g Gomega) {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(2))
+			for _, cronjob := range cronjobList.Items {
+				g.Expect(cronjob.GetName()).To(HavePrefix("provision-"))
+				nodeName := cronjob.GetLabels()["node"]
+				g.Expect(nodeName).To(BeElementOf("192.168.0.1", "192.168.0.2"))
+				g.Expect(cronjob.Spec.JobTemplate.Spec.Template.Spec.Affinity.NodeAffinity.
+					RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions).
+					To(Equal([]corev1.NodeSelectorRequirement{{
+						Key:      corev1.LabelHostname,
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   []string{nodeName},
+					}}))
+				g.Expect(cronjob.Spec.JobTemplate.Spec.Template.Spec.Volumes[0].Ephemeral).NotTo(BeNil())
+				g.Expect(cronjob.Spec.JobTemplate.Spec.Template.Spec.Volumes[0].Ephemeral.VolumeClaimTemplate.Labels).
+					To(HaveKeyWithValue("pie-probe", "pie-probe-sc2"))
+			}
+		}).Should(Succeed())
+
+		By("cleaning up CronJobs for sc2")
+		err = deletePieProbeAndReferencingResources(ctx, pieProbe2)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should rotate the PVCs of the mount probes if .spec.pvcRotationPeriod is set", func() {
+		By("creating a new PieProbe with .spec.pvcRotationPeriod")
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.DisableProvisionProbe = true
+		pieProbe2.Spec.PVCRotationPeriod = &metav1.Duration{Duration: 5 * time.Second}
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+
+		var oldPVCs corev1.PersistentVolumeClaimList
+		Eventually(func(g Gomega) {
+			err := k8sClient.List(ctx, &oldPVCs, client.MatchingLabels(map[string]string{
+				"storage-class": "sc2",
+			}))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(oldPVCs.Items).To(HaveLen(2))
+		}).Should(Succeed())
+
+		By("checking the CronJobs are suspended and the PVCs are deleted after the rotation period")
+		Eventually(func(g Gomega) {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(2))
+			for _, cronjob := range cronjobList.Items {
+				g.Expect(cronjob.Spec.Suspend).To(HaveValue(BeTrue()))
+				g.Expect(cronjob.Annotations).To(HaveKeyWithValue(constants.MountProbeSuspendersAnnotationKey, "pvc-rotation"))
+				g.Expect(cronjob.Annotations).To(HaveKey(constants.PVCRotationDeletedAtAnnotationKey))
+			}
+
+			// Note that the PVCs are not deleted because the finalizer won't be removed in envtest.
+			pvcList := listPVCs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(pvcList.Items).To(HaveLen(2))
+			for _, pvc := range pvcList.Items {
+				g.Expect(pvc.DeletionTimestamp).NotTo(BeNil())
+			}
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+
+		By("removing the finalizers of the PVCs")
+		for _, pvc := range oldPVCs.Items {
+			Eventually(func(g Gomega) {
+				var current corev1.PersistentVolumeClaim
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&pvc), &current)
+				g.Expect(err).NotTo(HaveOccurred())
+				current.Finalizers = []string{}
+				g.Expect(k8sClient.Update(ctx, &current)).To(Succeed())
+			}).Should(Succeed())
+		}
+
+		By("checking the PVCs are recreated and the CronJobs are resumed")
+		Eventually(func(g Gomega) {
+			pvcList := listPVCs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(pvcList.Items).To(HaveLen(2))
+			for _, pvc := range pvcList.Items {
+				g.Expect(pvc.DeletionTimestamp).To(BeNil())
+			}
+
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(2))
+			for _, cronjob := range cronjobList.Items {
+				g.Expect(cronjob.Spec.Suspend).To(HaveValue(BeFalse()))
+				g.Expect(cronjob.Annotations).NotTo(HaveKey(constants.MountProbeSuspendersAnnotationKey))
+				g.Expect(cronjob.Annotations).NotTo(HaveKey(constants.PVCRotationDeletedAtAnnotationKey))
+			}
+
+			g.Expect(exporter.getValues("SetPVCRotationDeleteDuration", "192.168.0.1")).NotTo(BeEmpty())
+			g.Expect(exporter.getValues("SetPVCRotationDeleteDuration", "192.168.0.2")).NotTo(BeEmpty())
+		}).Should(Succeed())
+
+		By("suspending a CronJob by hand")
+		var suspended batchv1.CronJob
+		Eventually(func(g Gomega) {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+			})
+			g.Expect(cronjobList.Items).NotTo(BeEmpty())
+			suspended = cronjobList.Items[0]
+			suspend := true
+			suspended.Spec.Suspend = &suspend
+			g.Expect(k8sClient.Update(ctx, &suspended)).To(Succeed())
+		}).Should(Succeed())
+
+		By("checking the CronJob suspended by hand is not resumed")
+		Consistently(func(g Gomega) {
+			var current batchv1.CronJob
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&suspended), &current)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(current.Spec.Suspend).To(HaveValue(BeTrue()))
+		}, 5*time.Second).Should(Succeed())
+
+		By("cleaning up CronJobs and PVCs for sc2")
+		err = deletePieProbeAndReferencingResources(ctx, pieProbe2)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should take a snapshot of the mount-probe PVC and restore it if .spec.snapshotProbe is set", func() {
+		By("creating a new PieProbe with .spec.snapshotProbe")
+		nodeName := "192.168.0.1"
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.DisableProvisionProbe = true
+		pieProbe2.Spec.SnapshotProbe = &piev1alpha1.SnapshotProbeSpec{
+			VolumeSnapshotClassName: "vsc",
+			Period:                  1,
+			Timeout:                 metav1.Duration{Duration: 5 * time.Minute},
+		}
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			err := deletePieProbeAndReferencingResources(ctx, pieProbe2)
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		By("making the mount-probe PVC bound and the mount probe succeeded")
+		var pvcName string
+		Eventually(func(g Gomega) {
+			pvcList := listPVCs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+				"node":          nodeName,
+			})
+			g.Expect(pvcList.Items).To(HaveLen(1))
+			pvc := pvcList.Items[0]
+			pvcName = pvc.GetName()
+			pvc.Status.Phase = corev1.ClaimBound
+			g.Expect(k8sClient.Status().Update(ctx, &pvc)).To(Succeed())
+
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+				"node":          nodeName,
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(1))
+			cronjob := cronjobList.Items[0]
+			now := metav1.Now()
+			cronjob.Status.LastSuccessfulTime = &now
+			g.Expect(k8sClient.Status().Update(ctx, &cronjob)).To(Succeed())
+		}).Should(Succeed())
+
+		By("checking the VolumeSnapshot of the PVC is created")
+		vs := newVolumeSnapshot()
+		Eventually(func(g Gomega) {
+			vsList := &unstructured.UnstructuredList{}
+			vsList.SetGroupVersionKind(volumeSnapshotGVK.GroupVersion().WithKind("VolumeSnapshotList"))
+			err := k8sClient.List(ctx, vsList, client.MatchingLabels(map[string]string{
+				"storage-class": "sc2",
+				"node":          nodeName,
+			}))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(vsList.Items).To(HaveLen(1))
+			vs = &vsList.Items[0]
+			g.Expect(vs.GetName()).To(HavePrefix("snapshot-"))
+			source, _, _ := unstructured.NestedString(vs.Object, "spec", "source", "persistentVolumeClaimName")
+			g.Expect(source).To(Equal(pvcName))
+			className, _, _ := unstructured.NestedString(vs.Object, "spec", "volumeSnapshotClassName")
+			g.Expect(className).To(Equal("vsc"))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+
+		By("checking the mount-probe CronJob is suspended during the snapshot")
+		getMountProbeCronJob := func(g Gomega) batchv1.CronJob {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+				"node":          nodeName,
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(1))
+			return cronjobList.Items[0]
+		}
+		Eventually(func(g Gomega) {
+			cronjob := getMountProbeCronJob(g)
+			g.Expect(cronjob.Spec.Suspend).To(HaveValue(BeTrue()))
+			g.Expect(cronjob.Annotations).To(HaveKeyWithValue(constants.MountProbeSuspendersAnnotationKey, "snapshot-probe"))
+		}).Should(Succeed())
+
+		By("making the VolumeSnapshot ready")
+		Expect(unstructured.SetNestedField(vs.Object, true, "status", "readyToUse")).To(Succeed())
+		Expect(k8sClient.Status().Update(ctx, vs)).To(Succeed())
+
+		By("checking the mount-probe CronJob is resumed after the snapshot is ready")
+		Eventually(func(g Gomega) {
+			cronjob := getMountProbeCronJob(g)
+			g.Expect(cronjob.Spec.Suspend).To(HaveValue(BeFalse()))
+			g.Expect(cronjob.Annotations).NotTo(HaveKey(constants.MountProbeSuspendersAnnotationKey))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+
+		By("checking the PVC restored from the VolumeSnapshot and the Job verifying it are created")
+		Eventually(func(g Gomega) {
+			var pvc corev1.PersistentVolumeClaim
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(vs), &pvc)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(pvc.Spec.DataSource).NotTo(BeNil())
+			g.Expect(pvc.Spec.DataSource.Kind).To(Equal("VolumeSnapshot"))
+			g.Expect(pvc.Spec.DataSource.Name).To(Equal(vs.GetName()))
+
+			var job batchv1.Job
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(vs), &job)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(job.Spec.Template.Spec.Containers[0].Args).To(ContainElement("verify"))
+			g.Expect(job.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal(pvc.GetName()))
+
+			g.Expect(exporter.getValues("IncrementSnapshotProbeCount", "snapshot_ready")).To(ContainElement("true"))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+
+		By("making the restored PVC bound")
+		Eventually(func(g Gomega) {
+			var pvc corev1.PersistentVolumeClaim
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(vs), &pvc)
+			g.Expect(err).NotTo(HaveOccurred())
+			pvc.Status.Phase = corev1.ClaimBound
+			g.Expect(k8sClient.Status().Update(ctx, &pvc)).To(Succeed())
+		}).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			g.Expect(exporter.getValues("IncrementSnapshotProbeCount", "restore_bound")).To(ContainElement("true"))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+	})
+
+	It("should clone the mount-probe PVC and verify the clone if .spec.cloneProbe is set", func() {
+		By("creating a new PieProbe with .spec.cloneProbe")
+		nodeName := "192.168.0.1"
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.DisableProvisionProbe = true
+		pieProbe2.Spec.CloneProbe = &piev1alpha1.CloneProbeSpec{
+			Period:  1,
+			Timeout: metav1.Duration{Duration: 5 * time.Minute},
+		}
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			err := deletePieProbeAndReferencingResources(ctx, pieProbe2)
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		By("making the mount-probe PVC bound and the mount probe succeeded")
+		var pvcName string
+		Eventually(func(g Gomega) {
+			pvcList := listPVCs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+				"node":          nodeName,
+			})
+			g.Expect(pvcList.Items).To(HaveLen(1))
+			pvc := pvcList.Items[0]
+			pvcName = pvc.GetName()
+			pvc.Status.Phase = corev1.ClaimBound
+			g.Expect(k8sClient.Status().Update(ctx, &pvc)).To(Succeed())
+
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+				"node":          nodeName,
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(1))
+			cronjob := cronjobList.Items[0]
+			now := metav1.Now()
+			cronjob.Status.LastSuccessfulTime = &now
+			g.Expect(k8sClient.Status().Update(ctx, &cronjob)).To(Succeed())
+		}).Should(Succeed())
+
+		By("checking the clone of the PVC and the Job verifying it are created")
+		var clone corev1.PersistentVolumeClaim
+		Eventually(func(g Gomega) {
+			pvcList := listPVCs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+				"node":          nodeName,
+			})
+			var found bool
+			for _, item := range pvcList.Items {
+				if strings.HasPrefix(item.GetName(), "clone-") {
+					clone = item
+					found = true
+				}
+			}
+			g.Expect(found).To(BeTrue())
+			g.Expect(clone.Spec.DataSource).NotTo(BeNil())
+			g.Expect(clone.Spec.DataSource.Kind).To(Equal("PersistentVolumeClaim"))
+			g.Expect(clone.Spec.DataSource.Name).To(Equal(pvcName))
+
+			var job batchv1.Job
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&clone), &job)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(job.Spec.Template.Spec.Containers[0].Args).To(ContainElement("verify"))
+			g.Expect(job.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal(clone.GetName()))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+
+		By("checking the mount-probe CronJob is suspended during the cloning")
+		getMountProbeCronJob := func(g Gomega) batchv1.CronJob {
+			cronjobList := listCronJobs(ctx, g, client.MatchingLabels{
+				"storage-class": "sc2",
+				"node":          nodeName,
+			})
+			g.Expect(cronjobList.Items).To(HaveLen(1))
+			return cronjobList.Items[0]
+		}
+		Eventually(func(g Gomega) {
+			cronjob := getMountProbeCronJob(g)
+			g.Expect(cronjob.Spec.Suspend).To(HaveValue(BeTrue()))
+			g.Expect(cronjob.Annotations).To(HaveKeyWithValue(constants.MountProbeSuspendersAnnotationKey, "clone-probe"))
+		}).Should(Succeed())
+
+		By("making the clone bound")
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&clone), &clone)
+			g.Expect(err).NotTo(HaveOccurred())
+			clone.Status.Phase = corev1.ClaimBound
+			g.Expect(k8sClient.Status().Update(ctx, &clone)).To(Succeed())
+		}).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			g.Expect(exporter.getValues("IncrementCloneProbeCount", "clone_bound")).To(ContainElement("true"))
+
+			cronjob := getMountProbeCronJob(g)
+			g.Expect(cronjob.Spec.Suspend).To(HaveValue(BeFalse()))
+			g.Expect(cronjob.Annotations).NotTo(HaveKey(constants.MountProbeSuspendersAnnotationKey))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+	})
+
+	It("should mount a ReadWriteMany PVC on the nodes if .spec.sharedAccessProbe is set", func() {
+		By("creating a new PieProbe with .spec.sharedAccessProbe")
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.DisableProvisionProbe = true
+		pieProbe2.Spec.DisableMountProbes = true
+		pieProbe2.Spec.SharedAccessProbe = &piev1alpha1.SharedAccessProbeSpec{
+			Period:   1,
+			Deadline: metav1.Duration{Duration: 30 * time.Second},
+			Timeout:  metav1.Duration{Duration: 5 * time.Minute},
+		}
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			err := deletePieProbeAndReferencingResources(ctx, pieProbe2)
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		By("checking the ReadWriteMany PVC and the Jobs mounting it on both nodes are created")
+		Eventually(func(g Gomega) {
+			pvcList := listPVCs(ctx, g, client.MatchingLabels{
+				"pie-probe":     "pie-probe-sc2",
+				"storage-class": "sc2",
+			})
+			g.Expect(pvcList.Items).To(HaveLen(1))
+			pvc := pvcList.Items[0]
+			g.Expect(pvc.GetName()).To(HavePrefix("shared-access-"))
+			g.Expect(pvc.Spec.AccessModes).To(Equal([]corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}))
+
+			var jobList batchv1.JobList
+			err = k8sClient.List(ctx, &jobList, client.MatchingLabels(map[string]string{
+				"pie-probe":     "pie-probe-sc2",
+				"storage-class": "sc2",
+			}))
+			g.Expect(err).NotTo(HaveOccurred())
+			nodes := []string{}
+			for _, job := range jobList.Items {
+				if !strings.HasPrefix(job.GetName(), "shared-access-") {
+					continue
+				}
+				nodes = append(nodes, job.GetLabels()["node"])
+				g.Expect(job.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal(pvc.GetName()))
+				args := job.Spec.Template.Spec.Containers[0].Args
+				g.Expect(args).To(ContainElement("shared-access"))
+				g.Expect(args).To(ContainElement("--nodes=192.168.0.1,192.168.0.2"))
+				g.Expect(args).To(ContainElement("--deadline=30s"))
+			}
+			g.Expect(nodes).To(ConsistOf("192.168.0.1", "192.168.0.2"))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+	})
+
+	It("should move a PVC to another node and watch its VolumeAttachments if .spec.failoverProbe is set", func() {
+		By("creating a new PieProbe with .spec.failoverProbe")
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.DisableProvisionProbe = true
+		pieProbe2.Spec.DisableMountProbes = true
+		pieProbe2.Spec.FailoverProbe = &piev1alpha1.FailoverProbeSpec{
+			Period:  1,
+			Timeout: metav1.Duration{Duration: 5 * time.Minute},
+		}
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+		pvName := "pv-failover"
+		defer func() {
+			err := deletePieProbeAndReferencingResources(ctx, pieProbe2)
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.DeleteAllOf(ctx, &storagev1.VolumeAttachment{})
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		By("checking the PVC and the Job writing the known data on the source node are created")
+		var pvc corev1.PersistentVolumeClaim
+		var sourceJob batchv1.Job
+		Eventually(func(g Gomega) {
+			pvcList := listPVCs(ctx, g, client.MatchingLabels{
+				"pie-probe":     "pie-probe-sc2",
+				"storage-class": "sc2",
+			})
+			g.Expect(pvcList.Items).To(HaveLen(1))
+			pvc = pvcList.Items[0]
+			g.Expect(pvc.GetName()).To(HavePrefix("failover-"))
+
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: pvc.GetName() + "-source"}, &sourceJob)
+			g.Expect(err).NotTo(HaveOccurred())
+			container := sourceJob.Spec.Template.Spec.Containers[0]
+			g.Expect(container.Args).To(ContainElements("write", "--hold"))
+			g.Expect(container.ReadinessProbe.Exec.Command).To(ContainElement("verify"))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+		sourceNode := pvc.GetAnnotations()["pie.topolvm.io/failover-source-node"]
+		destinationNode := pvc.GetAnnotations()["pie.topolvm.io/failover-destination-node"]
+		Expect([]string{sourceNode, destinationNode}).To(ConsistOf("192.168.0.1", "192.168.0.2"))
+		Expect(sourceJob.GetLabels()).To(HaveKeyWithValue("node", sourceNode))
+
+		attachVolume := func(nodeName string) {
+			va := &storagev1.VolumeAttachment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "va-" + nodeName,
+				},
+				Spec: storagev1.VolumeAttachmentSpec{
+					Attacher: "dummy.csi.example.com",
+					NodeName: nodeName,
+					Source: storagev1.VolumeAttachmentSource{
+						PersistentVolumeName: &pvName,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, va)).To(Succeed())
+			va.Status.Attached = true
+			Expect(k8sClient.Status().Update(ctx, va)).To(Succeed())
+		}
+
+		By("making the PVC bound, the volume attached and the Pod of the Job ready")
+		attachVolume(sourceNode)
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&pvc), &pvc)
+			g.Expect(err).NotTo(HaveOccurred())
+			pvc.Spec.VolumeName = pvName
+			g.Expect(k8sClient.Update(ctx, &pvc)).To(Succeed())
+			pvc.Status.Phase = corev1.ClaimBound
+			g.Expect(k8sClient.Status().Update(ctx, &pvc)).To(Succeed())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&sourceJob), &sourceJob)
+			g.Expect(err).NotTo(HaveOccurred())
+			var ready int32 = 1
+			now := metav1.Now()
+			sourceJob.Status.StartTime = &now
+			sourceJob.Status.Active = 1
+			sourceJob.Status.Ready = &ready
+			g.Expect(k8sClient.Status().Update(ctx, &sourceJob)).To(Succeed())
+		}).Should(Succeed())
+
+		By("checking the Job on the source node is deleted")
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&sourceJob), &batchv1.Job{})
+			g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			g.Expect(exporter.getValues("IncrementFailoverProbeCount", "written")).NotTo(BeEmpty())
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+
+		By("detaching the volume from the source node")
+		err = k8sClient.Delete(ctx, &storagev1.VolumeAttachment{ObjectMeta: metav1.ObjectMeta{Name: "va-" + sourceNode}})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the Job verifying the known data is created on the destination node")
+		Eventually(func(g Gomega) {
+			var job batchv1.Job
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: pvc.GetName() + "-destination"}, &job)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(job.GetLabels()).To(HaveKeyWithValue("node", destinationNode))
+			g.Expect(job.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal(pvc.GetName()))
+			g.Expect(job.Spec.Template.Spec.Containers[0].Args).
+				To(ContainElements("verify", "--node-name="+sourceNode))
+			g.Expect(exporter.getValues("IncrementFailoverProbeCount", "detached")).NotTo(BeEmpty())
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+
+		By("attaching the volume to the destination node")
+		attachVolume(destinationNode)
+		Eventually(func(g Gomega) {
+			g.Expect(exporter.getValues("IncrementFailoverProbeCount", "attached")).NotTo(BeEmpty())
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+	})
+
+	It("should expand a PVC and wait for its filesystem to grow if .spec.expansionProbe is set", func() {
+		By("creating a new PieProbe with .spec.expansionProbe")
+		nodeName := "192.168.0.1"
+		expandedCapacity := resource.MustParse("200Mi")
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.DisableProvisionProbe = true
+		pieProbe2.Spec.DisableMountProbes = true
+		pieProbe2.Spec.ExpansionProbe = &piev1alpha1.ExpansionProbeSpec{
+			ExpandedCapacity: &expandedCapacity,
+			Period:           1,
+			Timeout:          metav1.Duration{Duration: 5 * time.Minute},
+		}
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			err := deletePieProbeAndReferencingResources(ctx, pieProbe2)
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		By("checking the PVC and the Job of the expansion probe are created")
+		var job batchv1.Job
+		Eventually(func(g Gomega) {
+			var jobList batchv1.JobList
+			err := k8sClient.List(ctx, &jobList, client.MatchingLabels(map[string]string{
+				"storage-class": "sc2",
+				"node":          nodeName,
+			}))
+			g.Expect(err).NotTo(HaveOccurred())
+			var found bool
+			for _, item := range jobList.Items {
+				if strings.HasPrefix(item.GetName(), "expansion-") {
+					job = item
+					found = true
+				}
+			}
+			g.Expect(found).To(BeTrue())
+			container := job.Spec.Template.Spec.Containers[0]
+			g.Expect(container.Args).To(ContainElements("wait-expansion", "--ready-file="+expansionProbeReadyFile))
+			g.Expect(container.ReadinessProbe).NotTo(BeNil())
+			g.Expect(container.ReadinessProbe.Exec).NotTo(BeNil())
+			g.Expect(container.ReadinessProbe.Exec.Command).To(ContainElement(expansionProbeReadyFile))
+
+			var pvc corev1.PersistentVolumeClaim
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&job), &pvc)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("100Mi"))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+
+		By("making the PVC bound")
+		Eventually(func(g Gomega) {
+			var pvc corev1.PersistentVolumeClaim
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&job), &pvc)
+			g.Expect(err).NotTo(HaveOccurred())
+			pvc.Status.Phase = corev1.ClaimBound
+			pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Mi")}
+			g.Expect(k8sClient.Status().Update(ctx, &pvc)).To(Succeed())
+		}).Should(Succeed())
+
+		By("checking the PVC is not expanded until the probe records the initial size and becomes ready")
+		Consistently(func(g Gomega) {
+			var pvc corev1.PersistentVolumeClaim
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&job), &pvc)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("100Mi"))
+		}, 3*time.Second).Should(Succeed())
+
+		By("making the Pod of the Job ready")
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&job), &job)
+			g.Expect(err).NotTo(HaveOccurred())
+			var ready int32 = 1
+			now := metav1.Now()
+			job.Status.StartTime = &now
+			job.Status.Active = 1
+			job.Status.Ready = &ready
+			g.Expect(k8sClient.Status().Update(ctx, &job)).To(Succeed())
+		}).Should(Succeed())
+
+		By("checking the PVC is expanded")
+		Eventually(func(g Gomega) {
+			var pvc corev1.PersistentVolumeClaim
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&job), &pvc)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("200Mi"))
+			g.Expect(exporter.getValues("IncrementExpansionProbeCount", "mounted")).To(ContainElement("true"))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+
+		By("making the capacity of the PVC expanded")
+		Eventually(func(g Gomega) {
+			var pvc corev1.PersistentVolumeClaim
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&job), &pvc)
+			g.Expect(err).NotTo(HaveOccurred())
+			pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: expandedCapacity}
+			g.Expect(k8sClient.Status().Update(ctx, &pvc)).To(Succeed())
+		}).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			g.Expect(exporter.getValues("IncrementExpansionProbeCount", "volume_expanded")).To(ContainElement("true"))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+	})
+
+	It("should expand a PVC beyond its capacity rounded up by the provisioner", func() {
+		By("creating a new PieProbe with .spec.expansionProbe")
+		nodeName := "192.168.0.1"
+		expandedCapacity := resource.MustParse("200Mi")
+		pieProbe2 := newPieProbe("pie-probe-sc2", "sc2")
+		pieProbe2.Spec.DisableProvisionProbe = true
+		pieProbe2.Spec.DisableMountProbes = true
+		pieProbe2.Spec.ExpansionProbe = &piev1alpha1.ExpansionProbeSpec{
+			ExpandedCapacity: &expandedCapacity,
+			Period:           1,
+			Timeout:          metav1.Duration{Duration: 5 * time.Minute},
+		}
+		_, err := ctrl.CreateOrUpdate(ctx, k8sClient, pieProbe2, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			err := deletePieProbeAndReferencingResources(ctx, pieProbe2)
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		By("checking the Job of the expansion probe waits for the growth from its initial size")
+		var job batchv1.Job
+		Eventually(func(g Gomega) {
+			var jobList batchv1.JobList
+			err := k8sClient.List(ctx, &jobList, client.MatchingLabels(map[string]string{
+				"storage-class": "sc2",
+				"node":          nodeName,
+			}))
+			g.Expect(err).NotTo(HaveOccurred())
+			var found bool
+			for _, item := range jobList.Items {
+				if strings.HasPrefix(item.GetName(), "expansion-") {
+					job = item
+					found = true
+				}
+			}
+			g.Expect(found).To(BeTrue())
+			g.Expect(job.Spec.Template.Spec.Containers[0].Args).To(ContainElement("wait-expansion"))
+			g.Expect(job.Spec.Template.Spec.Containers[0].Args).NotTo(ContainElement(HavePrefix("--min-size")))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+
+		By("making the PVC bound with the capacity rounded up and the Pod of the Job ready")
+		Eventually(func(g Gomega) {
+			var pvc corev1.PersistentVolumeClaim
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&job), &pvc)
+			g.Expect(err).NotTo(HaveOccurred())
+			pvc.Status.Phase = corev1.ClaimBound
+			pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")}
+			g.Expect(k8sClient.Status().Update(ctx, &pvc)).To(Succeed())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(&job), &job)
+			g.Expect(err).NotTo(HaveOccurred())
+			var ready int32 = 1
+			now := metav1.Now()
+			job.Status.StartTime = &now
+			job.Status.Active = 1
+			job.Status.Ready = &ready
+			g.Expect(k8sClient.Status().Update(ctx, &job)).To(Succeed())
+		}).Should(Succeed())
+
+		By("checking the PVC is expanded beyond the capacity")
+		Eventually(func(g Gomega) {
+			var pvc corev1.PersistentVolumeClaim
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&job), &pvc)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("1124Mi"))
+			g.Expect(exporter.getValues("IncrementExpansionProbeCount", "mounted")).To(ContainElement("true"))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+
+		By("checking the capacity of expandedCapacity is not regarded as expanded")
+		Consistently(func(g Gomega) {
+			g.Expect(exporter.getValues("IncrementExpansionProbeCount", "volume_expanded")).NotTo(ContainElement("true"))
+		}).WithTimeout(3 * time.Second).Should(Succeed())
+
+		By("making the capacity of the PVC expanded")
+		Eventually(func(g Gomega) {
+			var pvc corev1.PersistentVolumeClaim
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&job), &pvc)
+			g.Expect(err).NotTo(HaveOccurred())
+			pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("2Gi")}
+			g.Expect(k8sClient.Status().Update(ctx, &pvc)).To(Succeed())
+		}).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			g.Expect(exporter.getValues("IncrementExpansionProbeCount", "volume_expanded")).To(ContainElement("true"))
+		}).WithTimeout(20 * time.Second).Should(Succeed())
+	})
+
 	It("should reject to edit monitoringStorageClass", func() {
 		By("trying to edit monitoringStorageClass")
 		var pieProbe piev1alpha1.PieProbe
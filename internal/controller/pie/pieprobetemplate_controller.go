@@ -0,0 +1,321 @@
+package pie
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+
+	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	"github.com/topolvm/pie/constants"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// PieProbeTemplateReconciler reconciles a PieProbeTemplate object
+type PieProbeTemplateReconciler struct {
+	client    client.Client
+	namespace string
+}
+
+//+kubebuilder:rbac:groups=pie.topolvm.io,resources=pieprobetemplates,verbs=get;list;watch
+//+kubebuilder:rbac:groups=pie.topolvm.io,resources=pieprobetemplates/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=pie.topolvm.io,resources=pieprobetemplates/finalizers,verbs=update
+
+// errPieProbeConflicted is returned if the PieProbe exists and is not controlled by the PieProbeTemplate.
+var errPieProbeConflicted = errors.New("PieProbe is not controlled by the PieProbeTemplate")
+
+// Reconcile creates a PieProbe for each StorageClass selected by the PieProbeTemplate
+// and deletes the PieProbes whose StorageClasses are no longer selected.
+// The StorageClasses and the states of their PieProbes are reported in the status.
+func (r *PieProbeTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	_ = log.FromContext(ctx)
+
+	pieProbeTemplate := piev1alpha1.PieProbeTemplate{}
+	err := r.client.Get(ctx, client.ObjectKey{Name: req.Name}, &pieProbeTemplate)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if pieProbeTemplate.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	statuses, err := r.getStorageClassStatuses(ctx, &pieProbeTemplate)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	necessaryPieProbes := map[string]struct{}{}
+	for i := range statuses {
+		status := &statuses[i]
+		if status.State != piev1alpha1.StorageClassStateManaged {
+			continue
+		}
+		status.PieProbeName, err = getPieProbeName(&pieProbeTemplate, status.Name)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		err = r.createOrUpdatePieProbe(ctx, &pieProbeTemplate, status.PieProbeName, status.Name)
+		if errors.Is(err, errPieProbeConflicted) {
+			status.State = piev1alpha1.StorageClassStateConflicted
+			status.Message = fmt.Sprintf("PieProbe %s/%s exists and is not controlled by the PieProbeTemplate",
+				r.namespace, status.PieProbeName)
+			continue
+		}
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		necessaryPieProbes[status.PieProbeName] = struct{}{}
+	}
+
+	// Delete unnecessary PieProbes
+	pieProbeList := piev1alpha1.PieProbeList{}
+	err = r.client.List(ctx, &pieProbeList, client.InNamespace(r.namespace))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	for _, pieProbe := range pieProbeList.Items {
+		if !metav1.IsControlledBy(&pieProbe, &pieProbeTemplate) {
+			continue
+		}
+		if _, ok := necessaryPieProbes[pieProbe.GetName()]; ok {
+			continue
+		}
+		err := r.client.Delete(ctx, &pieProbe)
+		if client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	err = r.updateStatus(ctx, &pieProbeTemplate, statuses)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// getStorageClassStatuses returns the StorageClasses matching the selector of the PieProbeTemplate.
+// StorageClasses that are being deleted are excluded, and the ones opted out are reported with the reasons.
+func (r *PieProbeTemplateReconciler) getStorageClassStatuses(
+	ctx context.Context,
+	pieProbeTemplate *piev1alpha1.PieProbeTemplate,
+) ([]piev1alpha1.PieProbeTemplateStorageClassStatus, error) {
+	selector := labels.Everything()
+	if pieProbeTemplate.Spec.StorageClassSelector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(pieProbeTemplate.Spec.StorageClassSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	storageClassList := storagev1.StorageClassList{}
+	err := r.client.List(ctx, &storageClassList, &client.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	statuses := []piev1alpha1.PieProbeTemplateStorageClassStatus{}
+	for _, storageClass := range storageClassList.Items {
+		if storageClass.DeletionTimestamp != nil {
+			continue
+		}
+		status := piev1alpha1.PieProbeTemplateStorageClassStatus{
+			Name:  storageClass.GetName(),
+			State: piev1alpha1.StorageClassStateManaged,
+		}
+		switch {
+		case storageClass.GetAnnotations()[constants.StorageClassIgnoreAnnotationKey] == "true":
+			status.State = piev1alpha1.StorageClassStateIgnored
+			status.Message = fmt.Sprintf("the StorageClass is annotated with %s=true",
+				constants.StorageClassIgnoreAnnotationKey)
+		case slices.Contains(pieProbeTemplate.Spec.ExcludedProvisioners, storageClass.Provisioner):
+			status.State = piev1alpha1.StorageClassStateExcluded
+			status.Message = fmt.Sprintf("the provisioner %s is excluded", storageClass.Provisioner)
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses, nil
+}
+
+func (r *PieProbeTemplateReconciler) updateStatus(
+	ctx context.Context,
+	pieProbeTemplate *piev1alpha1.PieProbeTemplate,
+	statuses []piev1alpha1.PieProbeTemplateStorageClassStatus,
+) error {
+	condition := metav1.Condition{
+		Type:    piev1alpha1.PieProbeTemplateConditionPieProbeConflicted,
+		Status:  metav1.ConditionFalse,
+		Reason:  "PieProbeControlled",
+		Message: "the PieProbes for the selected StorageClasses are controlled by the PieProbeTemplate",
+	}
+	for _, status := range statuses {
+		if status.State == piev1alpha1.StorageClassStateConflicted {
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "PieProbeConflicted"
+			condition.Message = "some PieProbes for the selected StorageClasses are not controlled by the PieProbeTemplate"
+			break
+		}
+	}
+	condition.ObservedGeneration = pieProbeTemplate.GetGeneration()
+
+	changed := meta.SetStatusCondition(&pieProbeTemplate.Status.Conditions, condition)
+	if !equality.Semantic.DeepEqual(pieProbeTemplate.Status.StorageClasses, statuses) {
+		pieProbeTemplate.Status.StorageClasses = statuses
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return r.client.Status().Update(ctx, pieProbeTemplate)
+}
+
+// createOrUpdatePieProbe returns errPieProbeConflicted if the PieProbe is not controlled by the PieProbeTemplate.
+func (r *PieProbeTemplateReconciler) createOrUpdatePieProbe(
+	ctx context.Context,
+	pieProbeTemplate *piev1alpha1.PieProbeTemplate,
+	pieProbeName string,
+	storageClass string,
+) error {
+	logger := log.FromContext(ctx)
+
+	pieProbe := &piev1alpha1.PieProbe{}
+	pieProbe.SetNamespace(r.namespace)
+	pieProbe.SetName(pieProbeName)
+
+	op, err := ctrl.CreateOrUpdate(ctx, r.client, pieProbe, func() error {
+		if !pieProbe.CreationTimestamp.IsZero() && !metav1.IsControlledBy(pieProbe, pieProbeTemplate) {
+			return errPieProbeConflicted
+		}
+		pieProbeTemplate.Spec.PieProbeSpec.DeepCopyInto(&pieProbe.Spec)
+		pieProbe.Spec.MonitoringStorageClass = storageClass
+		pieProbe.Spec.StorageClassSelector = nil
+
+		return ctrl.SetControllerReference(pieProbeTemplate, pieProbe, r.client.Scheme())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create PieProbe '%s' of storageclass %s: %w", pieProbeName, storageClass, err)
+	}
+	if op != controllerutil.OperationResultNone {
+		logger.Info(fmt.Sprintf("PieProbe '%s' successfully created of storageclass %s: %s", pieProbeName, storageClass, op))
+	}
+
+	return nil
+}
+
+// PieProbe name is used as a label value, so it should be less than or equal to 63 characters.
+// Like CronJob names, the template and StorageClass names are cut off and a hash value is added at the end.
+func getPieProbeName(pieProbeTemplate *piev1alpha1.PieProbeTemplate, storageClass string) (string, error) {
+	templateName := pieProbeTemplate.GetName()
+
+	sha1Hash := sha1.New()
+	_, err := io.WriteString(sha1Hash, templateName+"\000"+storageClass)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash pieprobe name: %w", err)
+	}
+	hashedName := hex.EncodeToString(sha1Hash.Sum(nil))
+
+	if len(templateName) > 24 {
+		templateName = templateName[:24]
+	}
+	if len(storageClass) > 31 {
+		storageClass = storageClass[:31]
+	}
+	return fmt.Sprintf("%s-%s-%s", templateName, storageClass, hashedName[:6]), nil
+}
+
+func (r *PieProbeTemplateReconciler) findPieProbeTemplatesForStorageClass(
+	ctx context.Context,
+	_ client.Object,
+) []reconcile.Request {
+	pieProbeTemplateList := piev1alpha1.PieProbeTemplateList{}
+	err := r.client.List(ctx, &pieProbeTemplateList)
+	if err != nil {
+		return []reconcile.Request{}
+	}
+	requests := []reconcile.Request{}
+	for _, item := range pieProbeTemplateList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name: item.GetName(),
+			},
+		})
+	}
+	return requests
+}
+
+// findPieProbeTemplatesForPieProbe returns the requests for the PieProbeTemplates conflicting with the PieProbe
+// to take it over after it is deleted.
+func (r *PieProbeTemplateReconciler) findPieProbeTemplatesForPieProbe(
+	ctx context.Context,
+	obj client.Object,
+) []reconcile.Request {
+	if obj.GetNamespace() != r.namespace {
+		return []reconcile.Request{}
+	}
+	pieProbeTemplateList := piev1alpha1.PieProbeTemplateList{}
+	err := r.client.List(ctx, &pieProbeTemplateList)
+	if err != nil {
+		return []reconcile.Request{}
+	}
+	requests := []reconcile.Request{}
+	for _, item := range pieProbeTemplateList.Items {
+		for _, status := range item.Status.StorageClasses {
+			if status.State == piev1alpha1.StorageClassStateConflicted && status.PieProbeName == obj.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name: item.GetName(),
+					},
+				})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PieProbeTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&piev1alpha1.PieProbeTemplate{}).
+		Owns(&piev1alpha1.PieProbe{}).
+		Watches(
+			&piev1alpha1.PieProbe{},
+			handler.EnqueueRequestsFromMapFunc(r.findPieProbeTemplatesForPieProbe),
+		).
+		Watches(
+			&storagev1.StorageClass{},
+			handler.EnqueueRequestsFromMapFunc(r.findPieProbeTemplatesForStorageClass),
+		).
+		Complete(r)
+}
+
+func NewPieProbeTemplateController(
+	client client.Client,
+	namespace string,
+) *PieProbeTemplateReconciler {
+	return &PieProbeTemplateReconciler{
+		client:    client,
+		namespace: namespace,
+	}
+}
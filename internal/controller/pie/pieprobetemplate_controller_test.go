@@ -0,0 +1,309 @@
+package pie
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+var _ = Describe("PieProbeTemplate controller", func() {
+	ctx := context.Background()
+	var stopFunc func()
+
+	listOwnedPieProbes := func(g Gomega, pieProbeTemplate *piev1alpha1.PieProbeTemplate) []piev1alpha1.PieProbe {
+		var pieProbeList piev1alpha1.PieProbeList
+		err := k8sClient.List(ctx, &pieProbeList, client.InNamespace("default"))
+		g.Expect(err).NotTo(HaveOccurred())
+		pieProbes := []piev1alpha1.PieProbe{}
+		for _, pieProbe := range pieProbeList.Items {
+			if metav1.IsControlledBy(&pieProbe, pieProbeTemplate) {
+				pieProbes = append(pieProbes, pieProbe)
+			}
+		}
+		return pieProbes
+	}
+
+	BeforeEach(func() {
+		skipNameValidation := true
+		mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+			Scheme:  scheme,
+			Metrics: metricsserver.Options{BindAddress: "0"},
+			Controller: config.Controller{
+				SkipNameValidation: &skipNameValidation,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		pieProbeTemplateReconciler := NewPieProbeTemplateController(k8sClient, "default")
+		err = pieProbeTemplateReconciler.SetupWithManager(mgr)
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(ctx)
+		stopFunc = cancel
+		go func() {
+			err := mgr.Start(ctx)
+			if err != nil {
+				panic(err)
+			}
+		}()
+		time.Sleep(100 * time.Millisecond)
+	})
+
+	AfterEach(func() {
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+	})
+
+	It("should create and delete PieProbes following the selected StorageClasses", func() {
+		By("creating StorageClasses")
+		storageClasses := []*storagev1.StorageClass{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "sc-template1",
+					Labels: map[string]string{"pie.topolvm.io/template": "true"},
+				},
+				Provisioner: "sc-provisioner",
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "sc-template2",
+					Labels: map[string]string{"pie.topolvm.io/template": "true"},
+				},
+				Provisioner: "sc-provisioner",
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "sc-template-ignored",
+					Labels:      map[string]string{"pie.topolvm.io/template": "true"},
+					Annotations: map[string]string{"pie.topolvm.io/ignore": "true"},
+				},
+				Provisioner: "sc-provisioner",
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "sc-template-excluded",
+					Labels: map[string]string{"pie.topolvm.io/template": "true"},
+				},
+				Provisioner: "excluded-provisioner",
+			},
+		}
+		for _, storageClass := range storageClasses {
+			_, err := ctrl.CreateOrUpdate(ctx, k8sClient, storageClass, func() error { return nil })
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		By("creating a PieProbeTemplate")
+		pieProbeTemplate := &piev1alpha1.PieProbeTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pie-probe-template",
+			},
+			Spec: piev1alpha1.PieProbeTemplateSpec{
+				StorageClassSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"pie.topolvm.io/template": "true"},
+				},
+				ExcludedProvisioners: []string{"excluded-provisioner"},
+				PieProbeSpec: piev1alpha1.PieProbeSpec{
+					NodeSelector: corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{
+										Key:      "key1",
+										Operator: corev1.NodeSelectorOpIn,
+										Values:   []string{"value1"},
+									},
+								},
+							},
+						},
+					},
+					ProbePeriod: 1,
+				},
+			},
+		}
+		err := k8sClient.Create(ctx, pieProbeTemplate)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking PieProbes are created for the selected StorageClasses")
+		Eventually(func(g Gomega) {
+			pieProbes := listOwnedPieProbes(g, pieProbeTemplate)
+			monitored := []string{}
+			for _, pieProbe := range pieProbes {
+				monitored = append(monitored, pieProbe.Spec.MonitoringStorageClass)
+				g.Expect(pieProbe.Spec.ProbePeriod).To(Equal(1))
+			}
+			g.Expect(monitored).To(ConsistOf("sc-template1", "sc-template2"))
+		}).Should(Succeed())
+
+		By("deleting a StorageClass")
+		err = k8sClient.Delete(ctx, storageClasses[1])
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the PieProbe for the deleted StorageClass is deleted")
+		Eventually(func(g Gomega) {
+			pieProbes := listOwnedPieProbes(g, pieProbeTemplate)
+			g.Expect(pieProbes).To(HaveLen(1))
+			g.Expect(pieProbes[0].Spec.MonitoringStorageClass).To(Equal("sc-template1"))
+		}).Should(Succeed())
+
+		By("cleaning up")
+		err = k8sClient.Delete(ctx, pieProbeTemplate)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			for _, pieProbe := range listOwnedPieProbes(g, pieProbeTemplate) {
+				err := k8sClient.Delete(ctx, &pieProbe)
+				g.Expect(client.IgnoreNotFound(err)).NotTo(HaveOccurred())
+			}
+		}).Should(Succeed())
+		for _, storageClass := range storageClasses {
+			err := k8sClient.Delete(ctx, storageClass)
+			Expect(client.IgnoreNotFound(err)).NotTo(HaveOccurred())
+		}
+	})
+
+	It("should not take over the PieProbes not controlled by the PieProbeTemplate", func() {
+		By("creating StorageClasses")
+		storageClasses := []*storagev1.StorageClass{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "sc-template-conflicted",
+					Labels: map[string]string{"pie.topolvm.io/template-conflict": "true"},
+				},
+				Provisioner: "sc-provisioner",
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "sc-template-conflict-ignored",
+					Labels:      map[string]string{"pie.topolvm.io/template-conflict": "true"},
+					Annotations: map[string]string{"pie.topolvm.io/ignore": "true"},
+				},
+				Provisioner: "sc-provisioner",
+			},
+		}
+		for _, storageClass := range storageClasses {
+			_, err := ctrl.CreateOrUpdate(ctx, k8sClient, storageClass, func() error { return nil })
+			Expect(err).NotTo(HaveOccurred())
+		}
+		nodeSelector := corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{
+							Key:      "key1",
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   []string{"value1"},
+						},
+					},
+				},
+			},
+		}
+
+		pieProbeTemplate := &piev1alpha1.PieProbeTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pie-probe-template-conflict",
+			},
+			Spec: piev1alpha1.PieProbeTemplateSpec{
+				StorageClassSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"pie.topolvm.io/template-conflict": "true"},
+				},
+				PieProbeSpec: piev1alpha1.PieProbeSpec{
+					NodeSelector: nodeSelector,
+					ProbePeriod:  1,
+				},
+			},
+		}
+
+		By("creating a PieProbe having the name of the one for the StorageClass")
+		pieProbeName, err := getPieProbeName(pieProbeTemplate, "sc-template-conflicted")
+		Expect(err).NotTo(HaveOccurred())
+		pieProbe := &piev1alpha1.PieProbe{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      pieProbeName,
+			},
+			Spec: piev1alpha1.PieProbeSpec{
+				MonitoringStorageClass: "sc-template-conflicted",
+				NodeSelector:           nodeSelector,
+				ProbePeriod:            5,
+			},
+		}
+		err = k8sClient.Create(ctx, pieProbe)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("creating a PieProbeTemplate")
+		err = k8sClient.Create(ctx, pieProbeTemplate)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the conflict and the ignored StorageClass are reported")
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pieProbeTemplate), pieProbeTemplate)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(pieProbeTemplate.Status.StorageClasses).To(ConsistOf(
+				And(
+					HaveField("Name", "sc-template-conflict-ignored"),
+					HaveField("State", piev1alpha1.StorageClassStateIgnored),
+				),
+				And(
+					HaveField("Name", "sc-template-conflicted"),
+					HaveField("PieProbeName", pieProbeName),
+					HaveField("State", piev1alpha1.StorageClassStateConflicted),
+				),
+			))
+			condition := meta.FindStatusCondition(pieProbeTemplate.Status.Conditions,
+				piev1alpha1.PieProbeTemplateConditionPieProbeConflicted)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		}).Should(Succeed())
+
+		By("checking the PieProbe is left untouched")
+		Consistently(func(g Gomega) {
+			var current piev1alpha1.PieProbe
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pieProbe), &current)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(current.Spec.ProbePeriod).To(Equal(5))
+			g.Expect(metav1.GetControllerOf(&current)).To(BeNil())
+		}).WithTimeout(3 * time.Second).Should(Succeed())
+
+		By("deleting the PieProbe")
+		err = k8sClient.Delete(ctx, pieProbe)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the PieProbeTemplate creates its PieProbe")
+		Eventually(func(g Gomega) {
+			pieProbes := listOwnedPieProbes(g, pieProbeTemplate)
+			g.Expect(pieProbes).To(HaveLen(1))
+			g.Expect(pieProbes[0].GetName()).To(Equal(pieProbeName))
+
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pieProbeTemplate), pieProbeTemplate)
+			g.Expect(err).NotTo(HaveOccurred())
+			condition := meta.FindStatusCondition(pieProbeTemplate.Status.Conditions,
+				piev1alpha1.PieProbeTemplateConditionPieProbeConflicted)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		}).Should(Succeed())
+
+		By("cleaning up")
+		err = k8sClient.Delete(ctx, pieProbeTemplate)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			for _, pieProbe := range listOwnedPieProbes(g, pieProbeTemplate) {
+				err := k8sClient.Delete(ctx, &pieProbe)
+				g.Expect(client.IgnoreNotFound(err)).NotTo(HaveOccurred())
+			}
+		}).Should(Succeed())
+		for _, storageClass := range storageClasses {
+			err := k8sClient.Delete(ctx, storageClass)
+			Expect(client.IgnoreNotFound(err)).NotTo(HaveOccurred())
+		}
+	})
+})
@@ -0,0 +1,227 @@
+package pie
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	"github.com/topolvm/pie/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const sharedAccessProbeStageRunning = "running"
+
+// getSharedAccessProbeName returns the name of the PVC of the shared-access probe if the node name is empty,
+// and the name of the Job of the node otherwise.
+func getSharedAccessProbeName(nodeName string, pieProbe *piev1alpha1.PieProbe, storageClass string) (string, error) {
+	return getStagedProbeName(constants.SharedAccessProbeNamePrefix, nodeName, pieProbe, storageClass)
+}
+
+// reconcileSharedAccessProbe advances the shared-access probe of the StorageClass by a step.
+// It returns the duration after which the probe should be checked again.
+func (r *PieProbeReconciler) reconcileSharedAccessProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	availableNodes map[string]corev1.Node,
+) (time.Duration, error) {
+	logger := log.FromContext(ctx)
+
+	name, err := getSharedAccessProbeName("", pieProbe, storageClass)
+	if err != nil {
+		return 0, err
+	}
+	key := types.NamespacedName{Namespace: pieProbe.GetNamespace(), Name: name}
+	period := time.Duration(pieProbe.Spec.SharedAccessProbe.Period) * time.Minute
+
+	run, lastRunTime, hasRun := r.getStagedProbeRun(key)
+
+	if run == nil {
+		nodeNames, err := selectNodes(pieProbe.Spec.SharedAccessProbe.NodeSelector, availableNodes)
+		if err != nil {
+			return 0, err
+		}
+		if len(nodeNames) < 2 {
+			logger.Info("skip the shared-access probe because less than two nodes are selected",
+				"storageClass", storageClass)
+			return 0, nil
+		}
+		if hasRun && time.Since(lastRunTime) < period {
+			return period - time.Since(lastRunTime), nil
+		}
+		return r.startSharedAccessProbe(ctx, pieProbe, storageClass, nodeNames, key)
+	}
+
+	jobs, err := r.listSharedAccessJobs(ctx, pieProbe, storageClass)
+	if err != nil {
+		return 0, err
+	}
+	if time.Since(run.stageStartTime) >= pieProbe.Spec.SharedAccessProbe.Timeout.Duration {
+		logger.Info("shared-access probe timed out", "name", name)
+		return r.finishSharedAccessProbe(ctx, pieProbe, storageClass, key, jobs)
+	}
+	for _, job := range jobs {
+		if finished, _ := getJobResult(&job); !finished {
+			return stagedProbePollInterval, nil
+		}
+	}
+	return r.finishSharedAccessProbe(ctx, pieProbe, storageClass, key, jobs)
+}
+
+// startSharedAccessProbe creates a ReadWriteMany PVC and the Jobs mounting it on the nodes at the same time.
+func (r *PieProbeReconciler) startSharedAccessProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeNames []string,
+	key types.NamespacedName,
+) (time.Duration, error) {
+	// Clean up the resources left by the previous run, e.g. before the controller restarted.
+	deleted, err := r.cleanupSharedAccessProbe(ctx, pieProbe, storageClass, key)
+	if err != nil {
+		return 0, err
+	}
+	if deleted {
+		return stagedProbePollInterval, nil
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+			Labels: map[string]string{
+				constants.ProbeStorageClassLabelKey: storageClass,
+				constants.ProbePieProbeLabelKey:     pieProbe.GetName(),
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			StorageClassName: &storageClass,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceStorage: *pieProbe.Spec.PVCCapacity,
+				},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(pieProbe, pvc, r.client.Scheme()); err != nil {
+		return 0, err
+	}
+	err = r.client.Create(ctx, pvc)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return 0, fmt.Errorf("failed to create PVC '%s' for the shared-access probe: %w", key.Name, err)
+	}
+
+	for _, nodeName := range nodeNames {
+		jobName, err := getSharedAccessProbeName(nodeName, pieProbe, storageClass)
+		if err != nil {
+			return 0, err
+		}
+		err = r.createStagedProbeJob(ctx, pieProbe, storageClass, nodeName, jobName, key.Name, []string{
+			"shared-access",
+			"--path=/mounted/",
+			fmt.Sprintf("--destination-address=%s", r.controllerUrl),
+			fmt.Sprintf("--node-name=%s", nodeName),
+			fmt.Sprintf("--storage-class=%s", storageClass),
+			fmt.Sprintf("--pie-probe-name=%s", pieProbe.GetName()),
+			fmt.Sprintf("--namespace=%s", pieProbe.GetNamespace()),
+			fmt.Sprintf("--nodes=%s", strings.Join(nodeNames, ",")),
+			fmt.Sprintf("--deadline=%s", pieProbe.Spec.SharedAccessProbe.Deadline.Duration),
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	r.beginStagedProbe(key, sharedAccessProbeStageRunning)
+	return stagedProbePollInterval, nil
+}
+
+// finishSharedAccessProbe exports the results of the Jobs on the nodes and cleans up the resources.
+// The Jobs not finished yet are counted as failed.
+func (r *PieProbeReconciler) finishSharedAccessProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	key types.NamespacedName,
+	jobs []batchv1.Job,
+) (time.Duration, error) {
+	for _, job := range jobs {
+		_, succeeded := getJobResult(&job)
+		nodeName := job.GetLabels()[constants.ProbeNodeLabelKey]
+		r.exporter.IncrementSharedAccessProbeCount(
+			pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClass, succeeded)
+	}
+
+	if _, err := r.cleanupSharedAccessProbe(ctx, pieProbe, storageClass, key); err != nil {
+		return 0, err
+	}
+
+	r.endStagedProbe(key)
+	return time.Duration(pieProbe.Spec.SharedAccessProbe.Period) * time.Minute, nil
+}
+
+// listSharedAccessJobs lists the Jobs of the shared-access probe of the StorageClass.
+func (r *PieProbeReconciler) listSharedAccessJobs(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+) ([]batchv1.Job, error) {
+	var jobList batchv1.JobList
+	err := r.client.List(ctx, &jobList, client.InNamespace(pieProbe.GetNamespace()), client.MatchingLabels{
+		constants.ProbePieProbeLabelKey:     pieProbe.GetName(),
+		constants.ProbeStorageClassLabelKey: storageClass,
+	})
+	if err != nil {
+		return nil, err
+	}
+	jobs := []batchv1.Job{}
+	for _, job := range jobList.Items {
+		if strings.HasPrefix(job.GetName(), constants.SharedAccessProbeNamePrefix) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+// cleanupSharedAccessProbe deletes the resources of the shared-access probe.
+// It returns true if any of them still exists.
+func (r *PieProbeReconciler) cleanupSharedAccessProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	key types.NamespacedName,
+) (bool, error) {
+	jobs, err := r.listSharedAccessJobs(ctx, pieProbe, storageClass)
+	if err != nil {
+		return false, err
+	}
+	found := false
+	for _, job := range jobs {
+		found = true
+		if job.GetDeletionTimestamp() != nil {
+			continue
+		}
+		policy := metav1.DeletePropagationBackground
+		err := r.client.Delete(ctx, &job, &client.DeleteOptions{PropagationPolicy: &policy})
+		if client.IgnoreNotFound(err) != nil {
+			return false, err
+		}
+	}
+
+	pvcFound, err := r.deleteStagedProbeObjects(ctx, key, &corev1.PersistentVolumeClaim{})
+	if err != nil {
+		return false, err
+	}
+	return found || pvcFound, nil
+}
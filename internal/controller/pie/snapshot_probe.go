@@ -0,0 +1,320 @@
+package pie
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	"github.com/topolvm/pie/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create;delete
+
+const (
+	snapshotProbeStageSnapshotReady = "snapshot_ready"
+	snapshotProbeStageRestoreBound  = "restore_bound"
+	snapshotProbeStageDataVerified  = "data_verified"
+)
+
+var volumeSnapshotGVK = schema.GroupVersionKind{
+	Group:   "snapshot.storage.k8s.io",
+	Version: "v1",
+	Kind:    "VolumeSnapshot",
+}
+
+// newVolumeSnapshot returns an empty VolumeSnapshot.
+// The VolumeSnapshots are handled as unstructured objects not to depend on the client library of them.
+func newVolumeSnapshot() *unstructured.Unstructured {
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(volumeSnapshotGVK)
+	return vs
+}
+
+// getSnapshotProbeName returns the name of the VolumeSnapshot, the restored PVC and the verification Job
+// of the snapshot probe. They share the same name.
+func getSnapshotProbeName(nodeName string, pieProbe *piev1alpha1.PieProbe, storageClass string) (string, error) {
+	return getStagedProbeName(constants.SnapshotProbeNamePrefix, nodeName, pieProbe, storageClass)
+}
+
+// reconcileSnapshotProbe advances the snapshot probe of the node by a step.
+// It returns the duration after which the probe should be checked again.
+func (r *PieProbeReconciler) reconcileSnapshotProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+) (time.Duration, error) {
+	logger := log.FromContext(ctx)
+
+	if !r.snapshotAPIAvailable {
+		logger.Info("skip the snapshot probe because the VolumeSnapshot API is not available")
+		return 0, nil
+	}
+
+	name, err := getSnapshotProbeName(nodeName, pieProbe, storageClass)
+	if err != nil {
+		return 0, err
+	}
+	key := types.NamespacedName{Namespace: pieProbe.GetNamespace(), Name: name}
+	period := time.Duration(pieProbe.Spec.SnapshotProbe.Period) * time.Minute
+
+	run, lastRunTime, hasRun := r.getStagedProbeRun(key)
+
+	if run == nil {
+		if hasRun && time.Since(lastRunTime) < period {
+			return period - time.Since(lastRunTime), nil
+		}
+		return r.startSnapshotProbe(ctx, pieProbe, storageClass, nodeName, key)
+	}
+
+	if time.Since(run.stageStartTime) >= pieProbe.Spec.SnapshotProbe.Timeout.Duration {
+		logger.Info("snapshot probe timed out", "name", name, "stage", run.stage)
+		return r.finishSnapshotProbe(ctx, pieProbe, storageClass, nodeName, key, run, false)
+	}
+
+	switch run.stage {
+	case snapshotProbeStageSnapshotReady:
+		vs := newVolumeSnapshot()
+		err := r.client.Get(ctx, key, vs)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return r.finishSnapshotProbe(ctx, pieProbe, storageClass, nodeName, key, run, false)
+			}
+			return 0, err
+		}
+		ready, _, _ := unstructured.NestedBool(vs.Object, "status", "readyToUse")
+		if !ready {
+			return stagedProbePollInterval, nil
+		}
+		// The snapshot is taken, so the mount probes may write to the PVC again.
+		err = r.releaseMountProbe(ctx, pieProbe, storageClass, nodeName, mountProbeSuspenderSnapshotProbe)
+		if err != nil {
+			return 0, err
+		}
+		err = r.createRestoredPVC(ctx, pieProbe, storageClass, nodeName, vs)
+		if err != nil {
+			return 0, err
+		}
+		// The Job is created with the PVC because the PVC may not be bound until it is used by a Pod.
+		err = r.createStagedProbeJob(ctx, pieProbe, storageClass, nodeName, name, name,
+			makeVerifyArgs(pieProbe, storageClass, nodeName))
+		if err != nil {
+			return 0, err
+		}
+		r.passSnapshotProbeStage(pieProbe, storageClass, nodeName, run, snapshotProbeStageRestoreBound)
+
+	case snapshotProbeStageRestoreBound:
+		var pvc corev1.PersistentVolumeClaim
+		err := r.client.Get(ctx, key, &pvc)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return r.finishSnapshotProbe(ctx, pieProbe, storageClass, nodeName, key, run, false)
+			}
+			return 0, err
+		}
+		if pvc.Status.Phase != corev1.ClaimBound {
+			return stagedProbePollInterval, nil
+		}
+		r.passSnapshotProbeStage(pieProbe, storageClass, nodeName, run, snapshotProbeStageDataVerified)
+
+	case snapshotProbeStageDataVerified:
+		var job batchv1.Job
+		err := r.client.Get(ctx, key, &job)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return r.finishSnapshotProbe(ctx, pieProbe, storageClass, nodeName, key, run, false)
+			}
+			return 0, err
+		}
+		if finished, succeeded := getJobResult(&job); finished {
+			return r.finishSnapshotProbe(ctx, pieProbe, storageClass, nodeName, key, run, succeeded)
+		}
+	}
+
+	return stagedProbePollInterval, nil
+}
+
+// startSnapshotProbe takes a VolumeSnapshot of the mount-probe PVC.
+// The probe is not started until a mount probe succeeds on the PVC and writes the known file.
+// The mount-probe CronJob is suspended until the snapshot is ready, and the snapshot is taken
+// only after the running mount probe finishes, so that the PVC is not written during the snapshot.
+func (r *PieProbeReconciler) startSnapshotProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+	key types.NamespacedName,
+) (time.Duration, error) {
+	pvc, err := r.getWrittenMountProbePVC(ctx, pieProbe, storageClass, nodeName)
+	if err != nil {
+		return 0, err
+	}
+	if pvc == nil {
+		return stagedProbePollInterval, nil
+	}
+
+	// Clean up the resources left by the previous run, e.g. before the controller restarted.
+	deleted, err := r.cleanupSnapshotProbe(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if deleted {
+		return stagedProbePollInterval, nil
+	}
+
+	quiesced, err := r.quiesceMountProbe(ctx, pieProbe, storageClass, nodeName, mountProbeSuspenderSnapshotProbe)
+	if err != nil {
+		return 0, err
+	}
+	if !quiesced {
+		return stagedProbePollInterval, nil
+	}
+
+	vs := newVolumeSnapshot()
+	vs.SetNamespace(key.Namespace)
+	vs.SetName(key.Name)
+	vs.SetLabels(map[string]string{
+		constants.ProbeStorageClassLabelKey: storageClass,
+		constants.ProbeNodeLabelKey:         nodeName,
+		constants.ProbePieProbeLabelKey:     pieProbe.GetName(),
+	})
+	err = unstructured.SetNestedField(vs.Object, pieProbe.Spec.SnapshotProbe.VolumeSnapshotClassName,
+		"spec", "volumeSnapshotClassName")
+	if err != nil {
+		return 0, err
+	}
+	err = unstructured.SetNestedField(vs.Object, pvc.GetName(), "spec", "source", "persistentVolumeClaimName")
+	if err != nil {
+		return 0, err
+	}
+	if err := ctrl.SetControllerReference(pieProbe, vs, r.client.Scheme()); err != nil {
+		return 0, err
+	}
+	err = r.client.Create(ctx, vs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create VolumeSnapshot '%s': %w", key.Name, err)
+	}
+
+	r.beginStagedProbe(key, snapshotProbeStageSnapshotReady)
+	return stagedProbePollInterval, nil
+}
+
+// passSnapshotProbeStage exports the result of the current stage and moves on to the next stage.
+func (r *PieProbeReconciler) passSnapshotProbeStage(
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+	run *stagedProbeRun,
+	nextStage string,
+) {
+	stage := run.stage
+	duration := r.advanceStagedProbe(run, nextStage)
+	r.exporter.SetSnapshotProbeDuration(
+		pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClass, stage, duration)
+	r.exporter.IncrementSnapshotProbeCount(
+		pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClass, stage, true)
+}
+
+// finishSnapshotProbe exports the result of the last stage and cleans up the resources.
+func (r *PieProbeReconciler) finishSnapshotProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+	key types.NamespacedName,
+	run *stagedProbeRun,
+	succeed bool,
+) (time.Duration, error) {
+	if succeed {
+		duration := time.Since(run.stageStartTime).Seconds()
+		r.exporter.SetSnapshotProbeDuration(
+			pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClass, run.stage, duration)
+	}
+	r.exporter.IncrementSnapshotProbeCount(
+		pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClass, run.stage, succeed)
+
+	// The CronJob is still suspended if the snapshot failed to be ready.
+	err := r.releaseMountProbe(ctx, pieProbe, storageClass, nodeName, mountProbeSuspenderSnapshotProbe)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := r.cleanupSnapshotProbe(ctx, key); err != nil {
+		return 0, err
+	}
+
+	r.endStagedProbe(key)
+	return time.Duration(pieProbe.Spec.SnapshotProbe.Period) * time.Minute, nil
+}
+
+// cleanupSnapshotProbe deletes the resources of the snapshot probe.
+// It returns true if any of them still exists.
+func (r *PieProbeReconciler) cleanupSnapshotProbe(ctx context.Context, key types.NamespacedName) (bool, error) {
+	return r.deleteStagedProbeObjects(ctx, key,
+		&batchv1.Job{},
+		&corev1.PersistentVolumeClaim{},
+		newVolumeSnapshot(),
+	)
+}
+
+func (r *PieProbeReconciler) createRestoredPVC(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+	vs *unstructured.Unstructured,
+) error {
+	capacity := *pieProbe.Spec.PVCCapacity
+	if restoreSize, found, _ := unstructured.NestedString(vs.Object, "status", "restoreSize"); found {
+		if q, err := resource.ParseQuantity(restoreSize); err == nil && q.Cmp(capacity) > 0 {
+			capacity = q
+		}
+	}
+
+	apiGroup := volumeSnapshotGVK.Group
+	volumeMode := getVolumeMode(pieProbe)
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: vs.GetNamespace(),
+			Name:      vs.GetName(),
+			Labels: map[string]string{
+				constants.ProbeStorageClassLabelKey: storageClass,
+				constants.ProbeNodeLabelKey:         nodeName,
+				constants.ProbePieProbeLabelKey:     pieProbe.GetName(),
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: &storageClass,
+			VolumeMode:       &volumeMode,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceStorage: capacity,
+				},
+			},
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     volumeSnapshotGVK.Kind,
+				Name:     vs.GetName(),
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(pieProbe, pvc, r.client.Scheme()); err != nil {
+		return err
+	}
+	err := r.client.Create(ctx, pvc)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create PVC '%s' from the snapshot: %w", pvc.GetName(), err)
+	}
+	return nil
+}
@@ -0,0 +1,363 @@
+package pie
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	"github.com/topolvm/pie/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+
+// stagedProbePollInterval is the interval to check the progress of the staged probes.
+const stagedProbePollInterval = 5 * time.Second
+
+// stagedProbeRun is the state of a running staged probe, e.g. the snapshot probe.
+// A staged probe consists of the stages whose results and durations are exported separately.
+type stagedProbeRun struct {
+	stage          string
+	stageStartTime time.Time
+}
+
+// getStagedProbeName returns the name of the resources of a staged probe of the node.
+// The resources of a staged probe share the same name.
+// The node name is omitted if it is empty, i.e. the resource is not specific to a node.
+func getStagedProbeName(
+	prefix string,
+	nodeName string,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+) (string, error) {
+	pieProbeName := pieProbe.Name
+
+	sha1Hash := sha1.New()
+	_, err := io.WriteString(sha1Hash, pieProbeName+"\000"+nodeName+"\000"+storageClass)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s probe name: %w", prefix, err)
+	}
+	hashedName := hex.EncodeToString(sha1Hash.Sum(nil))
+
+	if len(pieProbeName) > 10 {
+		pieProbeName = pieProbeName[:10]
+	}
+	if len(nodeName) > 11 {
+		nodeName = nodeName[:11]
+	}
+	if len(storageClass) > 12 {
+		storageClass = storageClass[:12]
+	}
+	if nodeName == "" {
+		return fmt.Sprintf("%s-%s-%s-%s", prefix, pieProbeName, storageClass, hashedName[:6]), nil
+	}
+	return fmt.Sprintf("%s-%s-%s-%s-%s", prefix, pieProbeName, nodeName, storageClass, hashedName[:6]), nil
+}
+
+// selectNodes returns the sorted names of the available nodes matching the selector.
+// All of them are returned if the selector is nil.
+func selectNodes(selector *corev1.NodeSelector, availableNodes map[string]corev1.Node) ([]string, error) {
+	var nodeSelector *nodeaffinity.NodeSelector
+	if selector != nil {
+		var err error
+		nodeSelector, err = nodeaffinity.NewNodeSelector(selector)
+		if err != nil {
+			return nil, err
+		}
+	}
+	nodeNames := []string{}
+	for nodeName, node := range availableNodes {
+		if nodeSelector != nil && !nodeSelector.Match(&node) {
+			continue
+		}
+		nodeNames = append(nodeNames, nodeName)
+	}
+	slices.Sort(nodeNames)
+	return nodeNames, nil
+}
+
+// getStagedProbeRun returns the running probe, and the time when the last probe finished if any.
+func (r *PieProbeReconciler) getStagedProbeRun(key types.NamespacedName) (*stagedProbeRun, time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lastRunTime, hasRun := r.stagedProbeLastRunTimes[key]
+	return r.stagedProbeRuns[key], lastRunTime, hasRun
+}
+
+// beginStagedProbe starts tracking the probe from the first stage.
+func (r *PieProbeReconciler) beginStagedProbe(key types.NamespacedName, stage string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stagedProbeRuns[key] = &stagedProbeRun{
+		stage:          stage,
+		stageStartTime: time.Now(),
+	}
+}
+
+// advanceStagedProbe moves the probe on to the next stage.
+// It returns the duration of the current stage.
+func (r *PieProbeReconciler) advanceStagedProbe(run *stagedProbeRun, nextStage string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	duration := time.Since(run.stageStartTime).Seconds()
+	run.stage = nextStage
+	run.stageStartTime = time.Now()
+	return duration
+}
+
+// endStagedProbe stops tracking the running probe and records the time when it finished.
+func (r *PieProbeReconciler) endStagedProbe(key types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.stagedProbeRuns, key)
+	r.stagedProbeLastRunTimes[key] = time.Now()
+}
+
+// forgetStagedProbe stops tracking the staged probe.
+func (r *PieProbeReconciler) forgetStagedProbe(key types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.stagedProbeRuns, key)
+	delete(r.stagedProbeLastRunTimes, key)
+}
+
+// deleteStagedProbeObjects deletes the objects named by the key.
+// It returns true if any of them still exists.
+func (r *PieProbeReconciler) deleteStagedProbeObjects(
+	ctx context.Context,
+	key types.NamespacedName,
+	objects ...client.Object,
+) (bool, error) {
+	found := false
+	for _, obj := range objects {
+		err := r.client.Get(ctx, key, obj)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+		found = true
+		if obj.GetDeletionTimestamp() != nil {
+			continue
+		}
+		policy := metav1.DeletePropagationBackground
+		err = r.client.Delete(ctx, obj, &client.DeleteOptions{PropagationPolicy: &policy})
+		if client.IgnoreNotFound(err) != nil {
+			return false, err
+		}
+	}
+	return found, nil
+}
+
+// getJobResult returns whether the Job has finished and whether it has succeeded.
+func getJobResult(job *batchv1.Job) (finished bool, succeeded bool) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, true
+		case batchv1.JobFailed:
+			return true, false
+		}
+	}
+	return false, false
+}
+
+// getWrittenMountProbePVC returns the mount-probe PVC of the node
+// if a mount probe has succeeded on it and written the known file.
+// It returns nil if the PVC is not ready to be the source of a probe yet.
+func (r *PieProbeReconciler) getWrittenMountProbePVC(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+) (*corev1.PersistentVolumeClaim, error) {
+	pvcName, err := getPVCName(nodeName, pieProbe, storageClass)
+	if err != nil {
+		return nil, err
+	}
+	var pvc corev1.PersistentVolumeClaim
+	err = r.client.Get(ctx, client.ObjectKey{Namespace: pieProbe.GetNamespace(), Name: pvcName}, &pvc)
+	if err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	if pvc.Status.Phase != corev1.ClaimBound || pvc.DeletionTimestamp != nil {
+		return nil, nil
+	}
+
+	cronJobName, err := getCronJobName(MountProbe, &nodeName, pieProbe, storageClass, "")
+	if err != nil {
+		return nil, err
+	}
+	var cronJob batchv1.CronJob
+	err = r.client.Get(ctx, client.ObjectKey{Namespace: pieProbe.GetNamespace(), Name: cronJobName}, &cronJob)
+	if err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	lastSuccessfulTime := cronJob.Status.LastSuccessfulTime
+	if lastSuccessfulTime == nil || lastSuccessfulTime.Before(&pvc.CreationTimestamp) {
+		return nil, nil
+	}
+	return &pvc, nil
+}
+
+// quiesceMountProbe suspends the mount-probe CronJob of the node on behalf of the suspender,
+// so that no mount probe writes to the PVC while it is being copied.
+// It returns true once no mount probe is running on the PVC.
+func (r *PieProbeReconciler) quiesceMountProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+	suspender string,
+) (bool, error) {
+	cronJob, err := r.getMountProbeCronJob(ctx, pieProbe, storageClass, nodeName)
+	if err != nil {
+		return false, err
+	}
+	err = r.suspendMountProbe(ctx, cronJob, suspender)
+	if err != nil {
+		return false, err
+	}
+	running, err := r.isMountProbeRunning(ctx, cronJob, pieProbe, storageClass, nodeName)
+	if err != nil {
+		return false, err
+	}
+	return !running, nil
+}
+
+// releaseMountProbe resumes the mount-probe CronJob of the node if it was suspended only by the suspender.
+func (r *PieProbeReconciler) releaseMountProbe(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+	suspender string,
+) error {
+	cronJob, err := r.getMountProbeCronJob(ctx, pieProbe, storageClass, nodeName)
+	if err != nil {
+		return err
+	}
+	return r.resumeMountProbe(ctx, cronJob, suspender)
+}
+
+// makeVerifyArgs returns the args of the probe verifying the known file written by the mount probe.
+func makeVerifyArgs(pieProbe *piev1alpha1.PieProbe, storageClass string, nodeName string) []string {
+	return []string{
+		"verify",
+		makeTargetArg(pieProbe),
+		fmt.Sprintf("--node-name=%s", nodeName),
+		fmt.Sprintf("--storage-class=%s", storageClass),
+		fmt.Sprintf("--pie-probe-name=%s", pieProbe.GetName()),
+	}
+}
+
+// createStagedProbeJob creates a Job running the probe with the args on the node.
+// The PVC is mounted at /mounted, or attached at blockDevicePath in the Block volumeMode.
+func (r *PieProbeReconciler) createStagedProbeJob(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+	name string,
+	pvcName string,
+	args []string,
+) error {
+	job := r.makeStagedProbeJob(pieProbe, storageClass, nodeName, name, pvcName, args)
+	return r.createJob(ctx, pieProbe, job)
+}
+
+// makeStagedProbeJob returns the Job created by createStagedProbeJob.
+// It is used to customize the Job before creating it.
+func (r *PieProbeReconciler) makeStagedProbeJob(
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+	name string,
+	pvcName string,
+	args []string,
+) *batchv1.Job {
+	label := map[string]string{
+		constants.ProbeStorageClassLabelKey: storageClass,
+		constants.ProbeNodeLabelKey:         nodeName,
+		constants.ProbePieProbeLabelKey:     pieProbe.GetName(),
+	}
+	var backoffLimit int32 = 0
+	var userID int64 = 1001
+	var groupID int64 = 1001
+	var periodSeconds int64 = 5
+	volumeName := "genericvol"
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: pieProbe.GetNamespace(),
+			Name:      name,
+			Labels:    label,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: label,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:      constants.ProbeContainerName,
+							Image:     r.containerImage,
+							Resources: pieProbe.Spec.Resources,
+							Args:      args,
+						},
+					},
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsUser:  &userID,
+						RunAsGroup: &groupID,
+						FSGroup:    &groupID,
+					},
+					RestartPolicy:                 corev1.RestartPolicyNever,
+					TerminationGracePeriodSeconds: &periodSeconds,
+					Affinity:                      makeNodeAffinity(nodeName),
+					Volumes: []corev1.Volume{
+						{
+							Name: volumeName,
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: pvcName,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	setProbeVolume(&job.Spec.Template.Spec.Containers[0], pieProbe, volumeName)
+	return job
+}
+
+// createJob creates the Job owned by the PieProbe. It is not an error if the Job already exists.
+func (r *PieProbeReconciler) createJob(ctx context.Context, pieProbe *piev1alpha1.PieProbe, job *batchv1.Job) error {
+	if err := ctrl.SetControllerReference(pieProbe, job, r.client.Scheme()); err != nil {
+		return err
+	}
+	err := r.client.Create(ctx, job)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create Job '%s': %w", job.GetName(), err)
+	}
+	return nil
+}
@@ -47,7 +47,10 @@ var _ = BeforeSuite(func() {
 
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
-		CRDDirectoryPaths:           []string{filepath.Join("..", "..", "..", "config", "crd", "bases")},
+		CRDDirectoryPaths: []string{
+			filepath.Join("..", "..", "..", "config", "crd", "bases"),
+			filepath.Join("testdata", "crd"),
+		},
 		ErrorIfCRDPathMissing:       true,
 		DownloadBinaryAssets:        true,
 		DownloadBinaryAssetsVersion: "v" + os.Getenv("PIE_ENVTEST_VERSION"),
@@ -0,0 +1,159 @@
+package pie
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch
+
+// The fields of the PVs verified against the mount probes.
+const (
+	volumeFieldNodeAffinity = "node_affinity"
+	volumeFieldDriver       = "driver"
+	volumeFieldCapacity     = "capacity"
+)
+
+// getPersistentVolumeMismatches returns the fields of the PV not matching the node of the mount probe,
+// the provisioner of the StorageClass or the requested capacity.
+// The PVs without node affinity are accessible from any node, and the drivers of non-CSI PVs are not verified.
+func getPersistentVolumeMismatches(
+	pv *corev1.PersistentVolume,
+	node *corev1.Node,
+	storageClass *storagev1.StorageClass,
+	capacity resource.Quantity,
+) ([]string, error) {
+	mismatches := []string{}
+
+	if pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
+		nodeSelector, err := nodeaffinity.NewNodeSelector(pv.Spec.NodeAffinity.Required)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the node affinity of PV '%s': %w", pv.GetName(), err)
+		}
+		if !nodeSelector.Match(node) {
+			mismatches = append(mismatches, volumeFieldNodeAffinity)
+		}
+	}
+
+	if pv.Spec.CSI != nil && pv.Spec.CSI.Driver != storageClass.Provisioner {
+		mismatches = append(mismatches, volumeFieldDriver)
+	}
+
+	pvCapacity, ok := pv.Spec.Capacity[corev1.ResourceStorage]
+	if !ok || pvCapacity.Cmp(capacity) < 0 {
+		mismatches = append(mismatches, volumeFieldCapacity)
+	}
+
+	return mismatches, nil
+}
+
+// verifyMountProbeVolumes verifies the PVs bound to the mount-probe PVCs,
+// and reports the mismatches as the metrics and the condition of the PieProbe.
+func (r *PieProbeReconciler) verifyMountProbeVolumes(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClasses []string,
+	availableNodes map[string]corev1.Node,
+) error {
+	mismatchedPVs := []string{}
+	for _, storageClassName := range storageClasses {
+		var storageClass storagev1.StorageClass
+		err := r.client.Get(ctx, client.ObjectKey{Name: storageClassName}, &storageClass)
+		if err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			continue
+		}
+
+		for nodeName, node := range availableNodes {
+			pv, err := r.getMountProbePV(ctx, pieProbe, storageClassName, nodeName)
+			if err != nil {
+				return err
+			}
+			if pv == nil {
+				continue
+			}
+			mismatches, err := getPersistentVolumeMismatches(pv, &node, &storageClass, *pieProbe.Spec.PVCCapacity)
+			if err != nil {
+				return err
+			}
+			for _, field := range []string{volumeFieldNodeAffinity, volumeFieldDriver, volumeFieldCapacity} {
+				r.exporter.SetPersistentVolumeMismatched(
+					pieProbe.GetNamespace(), pieProbe.GetName(), nodeName, storageClassName, field,
+					slices.Contains(mismatches, field))
+			}
+			if len(mismatches) != 0 {
+				mismatchedPVs = append(mismatchedPVs,
+					fmt.Sprintf("%s (%s)", pv.GetName(), strings.Join(mismatches, ", ")))
+			}
+		}
+	}
+	return r.updateVolumeMismatchCondition(ctx, pieProbe, mismatchedPVs)
+}
+
+// getMountProbePV returns the PV bound to the mount-probe PVC of the node, or nil if it is not bound.
+func (r *PieProbeReconciler) getMountProbePV(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	storageClass string,
+	nodeName string,
+) (*corev1.PersistentVolume, error) {
+	pvcName, err := getPVCName(nodeName, pieProbe, storageClass)
+	if err != nil {
+		return nil, err
+	}
+	var pvc corev1.PersistentVolumeClaim
+	err = r.client.Get(ctx, client.ObjectKey{Namespace: pieProbe.GetNamespace(), Name: pvcName}, &pvc)
+	if err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	if pvc.Status.Phase != corev1.ClaimBound || pvc.Spec.VolumeName == "" {
+		return nil, nil
+	}
+	var pv corev1.PersistentVolume
+	err = r.client.Get(ctx, client.ObjectKey{Name: pvc.Spec.VolumeName}, &pv)
+	if err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return &pv, nil
+}
+
+func (r *PieProbeReconciler) updateVolumeMismatchCondition(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	mismatchedPVs []string,
+) error {
+	condition := metav1.Condition{
+		Type:    piev1alpha1.PieProbeConditionPersistentVolumeMismatched,
+		Status:  metav1.ConditionFalse,
+		Reason:  "PersistentVolumeMatched",
+		Message: "the PersistentVolumes of the mount probes match the nodes, the StorageClasses and pvcCapacity",
+	}
+	if len(mismatchedPVs) != 0 {
+		sort.Strings(mismatchedPVs)
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "PersistentVolumeMismatched"
+		condition.Message = fmt.Sprintf("%d PersistentVolumes of the mount probes mismatch: %s",
+			len(mismatchedPVs), strings.Join(mismatchedPVs, "; "))
+	}
+	condition.ObservedGeneration = pieProbe.GetGeneration()
+
+	patch := client.MergeFromWithOptions(pieProbe.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	if !meta.SetStatusCondition(&pieProbe.Status.Conditions, condition) {
+		return nil
+	}
+	return r.client.Status().Patch(ctx, pieProbe, patch)
+}
@@ -3,7 +3,6 @@ package controller
 import (
 	"context"
 	"strings"
-	"time"
 
 	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
 	"github.com/topolvm/pie/constants"
@@ -20,22 +19,25 @@ import (
 type ProbePodReconciler struct {
 	client client.Client
 
-	startTime time.Time
-	po        *provisionObserver
+	po       *provisionObserver
+	recorder *metrics.ResultRecorder
 }
 
+// NewProbePodReconciler returns the ProbePodReconciler.
+// The results of the mount probes written to the termination messages are recorded by the recorder.
 func NewProbePodReconciler(
 	client client.Client,
 	exporter metrics.MetricsExporter,
+	recorder *metrics.ResultRecorder,
 ) *ProbePodReconciler {
 	return &ProbePodReconciler{
-		client:    client,
-		startTime: time.Now(),
-		po:        newProvisionObserver(client, exporter),
+		client:   client,
+		po:       newProvisionObserver(client, exporter),
+		recorder: recorder,
 	}
 }
 
-//+kubebuilder:rbac:namespace=default,groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -88,6 +90,11 @@ func (r *ProbePodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			r.po.setPodStartedTime(pod.Namespace, pod.Name, status.State.Running.StartedAt.Time)
 		} else if status.State.Terminated != nil {
 			r.po.setPodStartedTime(pod.Namespace, pod.Name, status.State.Terminated.StartedAt.Time)
+			if strings.HasPrefix(pod.Name, constants.MountProbeNamePrefix) {
+				if err := r.recordTerminationMessage(ctx, &pod, status.State.Terminated); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
 		}
 	}
 
@@ -103,6 +110,47 @@ func (r *ProbePodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	return ctrl.Result{}, nil
 }
 
+// recordTerminationMessage records the result written to the termination message by the mount probe.
+// The failed Pods are kept with the Jobs and reconciled many times, even after the controller restarts,
+// so the recorded run ID is persisted in the annotation of the Pod and the result is recorded only once.
+func (r *ProbePodReconciler) recordTerminationMessage(
+	ctx context.Context,
+	pod *corev1.Pod,
+	terminated *corev1.ContainerStateTerminated,
+) error {
+	logger := log.FromContext(ctx)
+
+	// The message is empty if the probe posted the result to the controller.
+	if terminated.Message == "" || r.recorder == nil {
+		return nil
+	}
+	if _, ok := pod.Annotations[constants.ResultRecordedAnnotationKey]; ok {
+		return nil
+	}
+
+	result, err := metrics.DecodeResult([]byte(terminated.Message))
+	if err != nil {
+		logger.Info("ignoring the termination message not containing the result", "pod", pod.Name, "error", err)
+		return nil
+	}
+	if result.RunID == "" {
+		logger.Info("ignoring the termination message without run ID", "pod", pod.Name)
+		return nil
+	}
+	// The Pod is in the namespace of the PieProbe, even if the older probes don't report it.
+	result.Identity.Namespace = pod.Namespace
+	// The run ID is also remembered by the recorder, so the result is not recorded again
+	// even if the annotation fails to be patched and the Pod is reconciled again.
+	r.recorder.Record(result)
+
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[constants.ResultRecordedAnnotationKey] = result.RunID
+	return r.client.Patch(ctx, pod, patch)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ProbePodReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := mgr.Add(r.po); err != nil {
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	"github.com/topolvm/pie/constants"
+	"github.com/topolvm/pie/metrics"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type countingMetricsExporter struct {
+	metrics.MetricsExporter
+	performanceCount int
+}
+
+func (e *countingMetricsExporter) IncrementPerformanceOnMountProbeCount(
+	namespace, pieProbeName, node, storageClass, volumeMode string, succeed bool,
+) {
+	e.performanceCount++
+}
+
+func (e *countingMetricsExporter) IncrementPerformanceOnMountProbeFailureCount(
+	namespace, pieProbeName, node, storageClass, volumeMode, reason string,
+) {
+}
+
+var _ = Describe("ProbePodReconciler", func() {
+	const terminationMessage = `{
+		"schema_version": "1.0",
+		"run_id": "run-1",
+		"kind": "mount",
+		"identity": {"pie_probe_name": "pie-probe", "node": "node1", "storage_class": "sc"},
+		"measurements": [
+			{"type": "performance", "performance": {"volume_mode": "Filesystem", "failure_reason": "timeout"}}
+		]
+	}`
+
+	It("should record the result of the termination message only once", func(ctx context.Context) {
+		By("preparing the failed mount probe Pod kept with its Job")
+		testScheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(testScheme)).To(Succeed())
+		Expect(piev1alpha1.AddToScheme(testScheme)).To(Succeed())
+
+		pieProbe := &piev1alpha1.PieProbe{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pie-probe"},
+		}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  "default",
+				Name:       constants.MountProbeNamePrefix + "-pod",
+				Labels:     map[string]string{constants.ProbePieProbeLabelKey: "pie-probe"},
+				Finalizers: []string{constants.PodFinalizerName},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name: constants.ProbeContainerName,
+						State: corev1.ContainerState{
+							Terminated: &corev1.ContainerStateTerminated{
+								ExitCode:   1,
+								Message:    terminationMessage,
+								FinishedAt: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+							},
+						},
+					},
+				},
+			},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(pieProbe, pod).Build()
+		exporter := &countingMetricsExporter{}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)}
+
+		By("reconciling the Pod")
+		r := NewProbePodReconciler(fakeClient, exporter, metrics.NewResultRecorder(exporter, nil))
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exporter.performanceCount).To(Equal(1))
+
+		var recorded corev1.Pod
+		Expect(fakeClient.Get(ctx, req.NamespacedName, &recorded)).To(Succeed())
+		Expect(recorded.Annotations).To(HaveKeyWithValue(constants.ResultRecordedAnnotationKey, "run-1"))
+
+		By("reconciling the Pod again after the recorder forgot the run ID")
+		// The new recorder remembers no run IDs as if more than an hour passed or the controller restarted.
+		r = NewProbePodReconciler(fakeClient, exporter, metrics.NewResultRecorder(exporter, nil))
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exporter.performanceCount).To(Equal(1))
+	})
+})
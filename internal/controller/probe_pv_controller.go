@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	"github.com/topolvm/pie/constants"
+	"github.com/topolvm/pie/metrics"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// probeLabelKeys are the labels copied from the probe PVCs to their PVs.
+var probeLabelKeys = []string{
+	constants.ProbePieProbeLabelKey,
+	constants.ProbeStorageClassLabelKey,
+	constants.ProbeNodeLabelKey,
+	constants.ProbeTopologyLabelKey,
+}
+
+// ProbePVReconciler labels the PVs bound to the PVCs of the probes
+// and observes that they are deleted after the PVCs are deleted.
+type ProbePVReconciler struct {
+	client client.Client
+	// watchedNamespaces is nil if the controller watches all namespaces.
+	watchedNamespaces []string
+
+	ro *reclaimObserver
+}
+
+func NewProbePVReconciler(
+	client client.Client,
+	exporter metrics.MetricsExporter,
+	watchedNamespaces []string,
+) *ProbePVReconciler {
+	return &ProbePVReconciler{
+		client:            client,
+		watchedNamespaces: watchedNamespaces,
+		ro:                newReclaimObserver(client, exporter),
+	}
+}
+
+//+kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch;update;patch
+
+// Reconcile labels the PV if it is bound to a PVC of the probes,
+// and tracks the PV labelled so after the PVC is deleted.
+func (r *ProbePVReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var pv corev1.PersistentVolume
+	err := r.client.Get(ctx, client.ObjectKey{Name: req.Name}, &pv)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			r.ro.setDeletedTime(req.Name, time.Now())
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if _, ok := pv.Labels[constants.ProbePieProbeLabelKey]; !ok {
+		return ctrl.Result{}, r.labelPV(ctx, &pv)
+	}
+	if pv.Spec.ClaimRef == nil || !r.isWatched(pv.Spec.ClaimRef.Namespace) {
+		return ctrl.Result{}, nil
+	}
+	if pv.Status.Phase != corev1.VolumeReleased && pv.Status.Phase != corev1.VolumeFailed {
+		return ctrl.Result{}, nil
+	}
+
+	// The threshold is not known if the PieProbe has already been deleted.
+	// Such PVs are not counted by the reclaim probe, and reported as leaked only if the PieProbe is recreated.
+	namespace := pv.Spec.ClaimRef.Namespace
+	pieProbeName := pv.Labels[constants.ProbePieProbeLabelKey]
+	var threshold *time.Duration
+	var pieProbe piev1alpha1.PieProbe
+	err = r.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: pieProbeName}, &pieProbe)
+	if err == nil {
+		threshold = &pieProbe.Spec.ProbeThreshold.Duration
+	} else if !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	storageClass := pv.Labels[constants.ProbeStorageClassLabelKey]
+	r.ro.setReleasedTime(pv.Name, namespace, pieProbeName, storageClass, threshold, time.Now())
+
+	return ctrl.Result{}, nil
+}
+
+func (r *ProbePVReconciler) labelPV(ctx context.Context, pv *corev1.PersistentVolume) error {
+	logger := log.FromContext(ctx)
+
+	claimRef := pv.Spec.ClaimRef
+	if claimRef == nil || !r.isWatched(claimRef.Namespace) {
+		return nil
+	}
+
+	var pvc corev1.PersistentVolumeClaim
+	err := r.client.Get(ctx, client.ObjectKey{Namespace: claimRef.Namespace, Name: claimRef.Name}, &pvc)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if pvc.GetUID() != claimRef.UID {
+		return nil
+	}
+	if _, ok := pvc.Labels[constants.ProbePieProbeLabelKey]; !ok {
+		return nil
+	}
+
+	patch := client.MergeFrom(pv.DeepCopy())
+	if pv.Labels == nil {
+		pv.Labels = map[string]string{}
+	}
+	for _, key := range probeLabelKeys {
+		if value, ok := pvc.Labels[key]; ok {
+			pv.Labels[key] = value
+		}
+	}
+	err = r.client.Patch(ctx, pv, patch)
+	if err != nil {
+		logger.Error(err, "failed to label pv", "pv", pv.GetName())
+		return err
+	}
+	return nil
+}
+
+func (r *ProbePVReconciler) isWatched(namespace string) bool {
+	return r.watchedNamespaces == nil || slices.Contains(r.watchedNamespaces, namespace)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ProbePVReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.Add(r.ro); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.PersistentVolume{}).
+		Complete(r)
+}
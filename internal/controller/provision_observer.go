@@ -98,17 +98,17 @@ func (p *provisionObserver) deleteEventTime(namespace, podName string) {
 	delete(p.podPieProbeName, namespacePod{namespace, podName})
 }
 
-func (p *provisionObserver) getNodeNameAndStorageClass(
+func (p *provisionObserver) getProbeLabels(
 	ctx context.Context,
 	namespace, podName string,
-) (string, string, error) {
+) (map[string]string, error) {
 	var pod corev1.Pod
 	err := p.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: podName}, &pod)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
-	return pod.GetLabels()[constants.ProbeNodeLabelKey], pod.GetLabels()[constants.ProbeStorageClassLabelKey], nil
+	return pod.GetLabels(), nil
 }
 
 func isProbeJob2(o metav1.OwnerReference) bool {
@@ -155,11 +155,23 @@ func (p *provisionObserver) deleteOwnerJobOfPod(ctx context.Context, namespace,
 	return nil
 }
 
-func (p *provisionObserver) incrementProbeCount(pieProbeName, podName, nodeName, storageClass string, onTime bool) {
+func (p *provisionObserver) incrementProbeCount(
+	namespace, pieProbeName, podName string,
+	probeLabels map[string]string,
+	onTime bool,
+) {
+	nodeName := probeLabels[constants.ProbeNodeLabelKey]
+	storageClass := probeLabels[constants.ProbeStorageClassLabelKey]
+	// The Pods created before the volume mode label is introduced have Filesystem volumes.
+	volumeMode, ok := probeLabels[constants.ProbeVolumeModeLabelKey]
+	if !ok {
+		volumeMode = string(corev1.PersistentVolumeFilesystem)
+	}
 	if strings.HasPrefix(podName, constants.ProvisionProbeNamePrefix) { // ProvisionProbe
-		p.exporter.IncrementProvisionProbeCount(pieProbeName, storageClass, onTime)
+		topology := probeLabels[constants.ProbeTopologyLabelKey]
+		p.exporter.IncrementProvisionProbeCount(namespace, pieProbeName, nodeName, storageClass, topology, volumeMode, onTime)
 	} else if strings.HasPrefix(podName, constants.MountProbeNamePrefix) { // MountProbe
-		p.exporter.IncrementMountProbeCount(pieProbeName, nodeName, storageClass, onTime)
+		p.exporter.IncrementMountProbeCount(namespace, pieProbeName, nodeName, storageClass, volumeMode, onTime)
 	}
 }
 
@@ -173,10 +185,10 @@ func (p *provisionObserver) check(ctx context.Context) {
 		if _, ok := p.countedFlag[nsAndPod]; ok {
 			continue
 		}
-		nodeName, storageClass, err := p.getNodeNameAndStorageClass(ctx, namespace, podName)
+		probeLabels, err := p.getProbeLabels(ctx, namespace, podName)
 		if err != nil {
 			if !apierrors.IsNotFound(err) {
-				logger.Error(err, "failed to get node and storage class related to the pod", "pod", podName)
+				logger.Error(err, "failed to get labels of the pod", "pod", podName)
 			}
 			continue
 		}
@@ -186,18 +198,18 @@ func (p *provisionObserver) check(ctx context.Context) {
 		if ok {
 			p.countedFlag[nsAndPod] = struct{}{}
 			if t.Sub(registeredTime) >= probeThreshold {
-				p.incrementProbeCount(pieProbeName, podName, nodeName, storageClass, false)
+				p.incrementProbeCount(namespace, pieProbeName, podName, probeLabels, false)
 				err := p.deleteOwnerJobOfPod(ctx, namespace, podName)
 				if err != nil {
 					continue
 				}
 			} else {
-				p.incrementProbeCount(pieProbeName, podName, nodeName, storageClass, true)
+				p.incrementProbeCount(namespace, pieProbeName, podName, probeLabels, true)
 			}
 		} else {
 			if time.Since(registeredTime) >= probeThreshold {
 				p.countedFlag[nsAndPod] = struct{}{}
-				p.incrementProbeCount(pieProbeName, podName, nodeName, storageClass, false)
+				p.incrementProbeCount(namespace, pieProbeName, podName, probeLabels, false)
 				err := p.deleteOwnerJobOfPod(ctx, namespace, podName)
 				if err != nil {
 					continue
@@ -207,7 +219,7 @@ func (p *provisionObserver) check(ctx context.Context) {
 	}
 }
 
-//+kubebuilder:rbac:namespace=default,groups=batch,resources=jobs,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;delete
 
 func (p *provisionObserver) Start(ctx context.Context) error {
 	ticker := time.NewTicker(time.Second)
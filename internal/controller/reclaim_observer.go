@@ -0,0 +1,254 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	"github.com/topolvm/pie/constants"
+	"github.com/topolvm/pie/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	reclaimLogger = ctrl.Log.WithName("reclaim-observer")
+)
+
+const (
+	// leakScanInterval is the interval to scan the PVs of the probes for leaks.
+	leakScanInterval = time.Minute
+	// defaultReclaimThreshold is used instead of the probe threshold if the PieProbe has already been deleted.
+	defaultReclaimThreshold = time.Minute
+)
+
+type releasedPV struct {
+	namespace    string
+	pieProbeName string
+	storageClass string
+	releasedTime time.Time
+	// threshold is nil if the PieProbe has already been deleted.
+	threshold *time.Duration
+	counted   bool
+}
+
+type pieProbeStorageClass struct {
+	namespace    string
+	pieProbeName string
+	storageClass string
+}
+
+type reclaimObserver struct {
+	client      client.Client
+	exporter    metrics.MetricsExporter
+	releasedPVs map[string]*releasedPV
+	// mu protects above map
+	mu sync.Mutex
+
+	// reportedLeaks holds the labels of the leak gauges set on the last scan.
+	reportedLeaks map[pieProbeStorageClass]struct{}
+}
+
+func newReclaimObserver(
+	client client.Client,
+	exporter metrics.MetricsExporter,
+) *reclaimObserver {
+	return &reclaimObserver{
+		client:        client,
+		exporter:      exporter,
+		releasedPVs:   make(map[string]*releasedPV),
+		reportedLeaks: make(map[pieProbeStorageClass]struct{}),
+	}
+}
+
+func (r *reclaimObserver) setReleasedTime(
+	pvName, namespace, pieProbeName, storageClass string,
+	threshold *time.Duration,
+	eventTime time.Time,
+) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.releasedPVs[pvName]; ok {
+		return
+	}
+	r.releasedPVs[pvName] = &releasedPV{
+		namespace:    namespace,
+		pieProbeName: pieProbeName,
+		storageClass: storageClass,
+		releasedTime: eventTime,
+		threshold:    threshold,
+	}
+}
+
+func (r *reclaimObserver) setDeletedTime(pvName string, eventTime time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pv, ok := r.releasedPVs[pvName]
+	if !ok {
+		return
+	}
+	delete(r.releasedPVs, pvName)
+	if pv.counted || pv.threshold == nil {
+		return
+	}
+	onTime := eventTime.Sub(pv.releasedTime) < *pv.threshold
+	r.exporter.IncrementReclaimProbeCount(pv.namespace, pv.pieProbeName, pv.storageClass, onTime)
+}
+
+// check counts the PVs not deleted within the threshold as late.
+func (r *reclaimObserver) check() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, pv := range r.releasedPVs {
+		if pv.counted || pv.threshold == nil {
+			continue
+		}
+		if time.Since(pv.releasedTime) >= *pv.threshold {
+			pv.counted = true
+			r.exporter.IncrementReclaimProbeCount(pv.namespace, pv.pieProbeName, pv.storageClass, false)
+		}
+	}
+}
+
+// isLeaked returns true if the PV is in the Failed phase,
+// or in the Released phase for longer than the threshold.
+func (r *reclaimObserver) isLeaked(pv *corev1.PersistentVolume) bool {
+	if pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimDelete {
+		return false
+	}
+	switch pv.Status.Phase {
+	case corev1.VolumeFailed:
+		return true
+	case corev1.VolumeReleased:
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		released, ok := r.releasedPVs[pv.GetName()]
+		if !ok {
+			return false
+		}
+		threshold := defaultReclaimThreshold
+		if released.threshold != nil {
+			threshold = *released.threshold
+		}
+		return time.Since(released.releasedTime) >= threshold
+	}
+	return false
+}
+
+// scan reports the leaked PVs of the probes as the metrics and the conditions of the PieProbes.
+// The leaks of the PieProbes already deleted are not reported, because their metrics are deleted with them.
+func (r *reclaimObserver) scan(ctx context.Context) error {
+	var pieProbeList piev1alpha1.PieProbeList
+	err := r.client.List(ctx, &pieProbeList)
+	if err != nil {
+		return err
+	}
+	pieProbes := map[types.NamespacedName]struct{}{}
+	for _, pieProbe := range pieProbeList.Items {
+		pieProbes[client.ObjectKeyFromObject(&pieProbe)] = struct{}{}
+	}
+
+	var pvList corev1.PersistentVolumeList
+	err = r.client.List(ctx, &pvList, client.HasLabels{constants.ProbePieProbeLabelKey})
+	if err != nil {
+		return err
+	}
+
+	counts := map[pieProbeStorageClass]int{}
+	leakedPVs := map[types.NamespacedName][]string{}
+	for _, pv := range pvList.Items {
+		if pv.Spec.ClaimRef == nil || !r.isLeaked(&pv) {
+			continue
+		}
+		pieProbe := types.NamespacedName{
+			Namespace: pv.Spec.ClaimRef.Namespace,
+			Name:      pv.Labels[constants.ProbePieProbeLabelKey],
+		}
+		if _, ok := pieProbes[pieProbe]; !ok {
+			continue
+		}
+		key := pieProbeStorageClass{pieProbe.Namespace, pieProbe.Name, pv.Labels[constants.ProbeStorageClassLabelKey]}
+		counts[key]++
+		leakedPVs[pieProbe] = append(leakedPVs[pieProbe], pv.GetName())
+	}
+
+	// The gauges are deleted once the PVs are reclaimed, not to leave the series of the deleted PieProbes.
+	for key := range r.reportedLeaks {
+		if _, ok := counts[key]; !ok {
+			r.exporter.DeleteLeakedPersistentVolumeCount(key.namespace, key.pieProbeName, key.storageClass)
+			delete(r.reportedLeaks, key)
+		}
+	}
+	for key, count := range counts {
+		r.exporter.SetLeakedPersistentVolumeCount(key.namespace, key.pieProbeName, key.storageClass, count)
+		r.reportedLeaks[key] = struct{}{}
+	}
+
+	for _, pieProbe := range pieProbeList.Items {
+		pvNames := leakedPVs[client.ObjectKeyFromObject(&pieProbe)]
+		err := r.updateLeakCondition(ctx, &pieProbe, pvNames)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *reclaimObserver) updateLeakCondition(
+	ctx context.Context,
+	pieProbe *piev1alpha1.PieProbe,
+	pvNames []string,
+) error {
+	condition := metav1.Condition{
+		Type:    piev1alpha1.PieProbeConditionPersistentVolumeLeaked,
+		Status:  metav1.ConditionFalse,
+		Reason:  "PersistentVolumeReclaimed",
+		Message: "the PersistentVolumes of the probes are reclaimed",
+	}
+	if len(pvNames) != 0 {
+		sort.Strings(pvNames)
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "PersistentVolumeLeaked"
+		condition.Message = fmt.Sprintf("%d PersistentVolumes of the probes are not reclaimed: %s",
+			len(pvNames), strings.Join(pvNames, ", "))
+	}
+	condition.ObservedGeneration = pieProbe.GetGeneration()
+
+	patch := client.MergeFromWithOptions(pieProbe.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	if !meta.SetStatusCondition(&pieProbe.Status.Conditions, condition) {
+		return nil
+	}
+	return r.client.Status().Patch(ctx, pieProbe, patch)
+}
+
+func (r *reclaimObserver) Start(ctx context.Context) error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	scanTicker := time.NewTicker(leakScanInterval)
+	defer scanTicker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.check()
+		case <-scanTicker.C:
+			if err := r.scan(ctx); err != nil {
+				reclaimLogger.Error(err, "failed to scan leaked pvs")
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
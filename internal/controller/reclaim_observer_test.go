@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	piev1alpha1 "github.com/topolvm/pie/api/pie/v1alpha1"
+	"github.com/topolvm/pie/constants"
+	"github.com/topolvm/pie/metrics"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// leakGaugeExporter holds the leak gauges set by the reclaimObserver keyed by "<namespace>/<pieProbeName>/<sc>".
+type leakGaugeExporter struct {
+	metrics.MetricsExporter
+	gauges map[string]int
+}
+
+func (e *leakGaugeExporter) SetLeakedPersistentVolumeCount(namespace, pieProbeName, storageClass string, count int) {
+	e.gauges[namespace+"/"+pieProbeName+"/"+storageClass] = count
+}
+
+func (e *leakGaugeExporter) DeleteLeakedPersistentVolumeCount(namespace, pieProbeName, storageClass string) {
+	delete(e.gauges, namespace+"/"+pieProbeName+"/"+storageClass)
+}
+
+var _ = Describe("reclaimObserver", func() {
+	newLeakedPV := func(name, namespace, pieProbeName string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					constants.ProbePieProbeLabelKey:     pieProbeName,
+					constants.ProbeStorageClassLabelKey: "sc",
+				},
+			},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+				ClaimRef:                      &corev1.ObjectReference{Namespace: namespace, Name: name},
+			},
+			Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeFailed},
+		}
+	}
+
+	It("should delete the leak gauges once the PVs are reclaimed or the PieProbes are deleted", func(ctx context.Context) {
+		By("preparing the leaked PVs of the PieProbes with the same name in two namespaces")
+		testScheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(testScheme)).To(Succeed())
+		Expect(piev1alpha1.AddToScheme(testScheme)).To(Succeed())
+
+		pieProbe1 := &piev1alpha1.PieProbe{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pie-probe"},
+		}
+		pieProbe2 := &piev1alpha1.PieProbe{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "pie-probe"},
+		}
+		pv1 := newLeakedPV("pv1", "default", "pie-probe")
+		pv2 := newLeakedPV("pv2", "tenant", "pie-probe")
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(testScheme).
+			WithObjects(pieProbe1, pieProbe2, pv1, pv2).
+			WithStatusSubresource(&piev1alpha1.PieProbe{}).
+			Build()
+		exporter := &leakGaugeExporter{gauges: map[string]int{}}
+		r := newReclaimObserver(fakeClient, exporter)
+
+		By("checking the leaks are reported for each namespace")
+		Expect(r.scan(ctx)).To(Succeed())
+		Expect(exporter.gauges).To(Equal(map[string]int{
+			"default/pie-probe/sc": 1,
+			"tenant/pie-probe/sc":  1,
+		}))
+
+		By("reclaiming the PV of the PieProbe in the default namespace")
+		Expect(fakeClient.Delete(ctx, pv1)).To(Succeed())
+		Expect(r.scan(ctx)).To(Succeed())
+		Expect(exporter.gauges).To(Equal(map[string]int{
+			"tenant/pie-probe/sc": 1,
+		}))
+
+		By("deleting the PieProbe in the tenant namespace")
+		Expect(fakeClient.Delete(ctx, pieProbe2)).To(Succeed())
+		Expect(r.scan(ctx)).To(Succeed())
+		Expect(exporter.gauges).To(BeEmpty())
+	})
+})
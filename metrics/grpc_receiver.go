@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+
+	resultv1 "github.com/topolvm/pie/api/result/v1"
+)
+
+type grpcReceiver struct {
+	resultv1.UnimplementedResultServiceServer
+	recorder *ResultRecorder
+}
+
+// NewGRPCReceiver returns the gRPC service receiving the results of the mount probes.
+// The results are recorded by the recorder shared with the HTTP receiver.
+func NewGRPCReceiver(recorder *ResultRecorder) resultv1.ResultServiceServer {
+	return &grpcReceiver{
+		recorder: recorder,
+	}
+}
+
+func (rh *grpcReceiver) ReportResults(stream resultv1.ResultService_ReportResultsServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(rh.report(req.GetResult())); err != nil {
+			return err
+		}
+	}
+}
+
+// report records the result and returns its acknowledgement.
+// The invalid results are rejected one by one without closing the stream.
+func (rh *grpcReceiver) report(msg *resultv1.Result) *resultv1.ReportResultsResponse {
+	result := msg.ToEnvelope()
+	resp := &resultv1.ReportResultsResponse{
+		RunId: result.RunID,
+	}
+	if err := ValidateResult(result); err != nil {
+		resp.Status = resultv1.ReportResultsResponse_STATUS_REJECTED
+		resp.Message = err.Error()
+		return resp
+	}
+
+	if !rh.recorder.Record(result) {
+		slog.Info("ignoring the duplicate result", "run_id", result.RunID)
+		resp.Status = resultv1.ReportResultsResponse_STATUS_DUPLICATE
+		return resp
+	}
+	resp.Status = resultv1.ReportResultsResponse_STATUS_ACCEPTED
+	return resp
+}
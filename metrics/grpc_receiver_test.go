@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	resultv1 "github.com/topolvm/pie/api/result/v1"
+	"github.com/topolvm/pie/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestResult(schemaVersion, runID string) *types.ResultEnvelope {
+	return &types.ResultEnvelope{
+		SchemaVersion: schemaVersion,
+		RunID:         runID,
+		Kind:          types.ProbeKindMount,
+		Identity: types.ProbeIdentity{
+			PieProbeName: "pie-probe",
+			Node:         "node1",
+			StorageClass: "sc",
+		},
+		Measurements: []types.Measurement{
+			{
+				Type: types.MeasurementTypePerformance,
+				Performance: &types.PerformanceMeasurement{
+					VolumeMode: "Filesystem",
+					IOMode:     types.IOModeDirect,
+					Succeeded:  true,
+				},
+			},
+		},
+	}
+}
+
+func TestGRPCReceiver(t *testing.T) {
+	exporter := &countingExporter{}
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	resultv1.RegisterResultServiceServer(s, NewGRPCReceiver(NewResultRecorder(exporter, nil)))
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	defer s.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to connect to the receiver: %v", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := resultv1.NewResultServiceClient(conn).ReportResults(ctx)
+	if err != nil {
+		t.Fatalf("failed to open the stream: %v", err)
+	}
+
+	// The results are sent on the same stream, which is kept open after the rejections.
+	testCases := []struct {
+		name       string
+		result     *types.ResultEnvelope
+		wantStatus resultv1.ReportResultsResponse_Status
+		wantCount  int
+	}{
+		{
+			name:       "accepts the result",
+			result:     newTestResult(types.ResultSchemaVersion, "run-1"),
+			wantStatus: resultv1.ReportResultsResponse_STATUS_ACCEPTED,
+			wantCount:  1,
+		},
+		{
+			name:       "ignores the result with the same run ID",
+			result:     newTestResult(types.ResultSchemaVersion, "run-1"),
+			wantStatus: resultv1.ReportResultsResponse_STATUS_DUPLICATE,
+			wantCount:  1,
+		},
+		{
+			name:       "rejects the unsupported major version",
+			result:     newTestResult("2.0", "run-2"),
+			wantStatus: resultv1.ReportResultsResponse_STATUS_REJECTED,
+			wantCount:  1,
+		},
+		{
+			name:       "accepts the newer minor version",
+			result:     newTestResult("1.99", "run-3"),
+			wantStatus: resultv1.ReportResultsResponse_STATUS_ACCEPTED,
+			wantCount:  2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := stream.Send(&resultv1.ReportResultsRequest{Result: resultv1.NewResult(tc.result)})
+			if err != nil {
+				t.Fatalf("failed to send the result: %v", err)
+			}
+			resp, err := stream.Recv()
+			if err != nil {
+				t.Fatalf("failed to receive the response: %v", err)
+			}
+			if resp.GetRunId() != tc.result.RunID {
+				t.Errorf("run ID = %q, want %q", resp.GetRunId(), tc.result.RunID)
+			}
+			if resp.GetStatus() != tc.wantStatus {
+				t.Errorf("status = %v, want %v: %s", resp.GetStatus(), tc.wantStatus, resp.GetMessage())
+			}
+			if exporter.performanceCount != tc.wantCount {
+				t.Errorf("recorded %d results, want %d", exporter.performanceCount, tc.wantCount)
+			}
+		})
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("failed to close the stream: %v", err)
+	}
+}
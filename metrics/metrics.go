@@ -4,6 +4,7 @@ import (
 	"strconv"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/topolvm/pie/types"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
@@ -13,18 +14,69 @@ const (
 )
 
 type MetricsExporter interface {
-	SetLatencyOnMountProbe(pieProbeName, node, storageClass string, readLatency, writeLatency float64)
-	IncrementPerformanceOnMountProbeCount(pieProbeName, node, storageClass string, succeed bool)
-	IncrementProvisionProbeCount(pieProbeName string, storageClass string, onTime bool)
-	IncrementMountProbeCount(pieProbeName, node, storageClass string, onTime bool)
+	SetLatencyOnMountProbe(
+		namespace, pieProbeName, node, storageClass, volumeMode, ioMode string,
+		readLatency, writeLatency float64,
+	)
+	IncrementPerformanceOnMountProbeCount(namespace, pieProbeName, node, storageClass, volumeMode string, succeed bool)
+	IncrementPerformanceOnMountProbeFailureCount(namespace, pieProbeName, node, storageClass, volumeMode, reason string)
+	SetFilesystemUsageOnMountProbe(namespace, pieProbeName, node, storageClass string, usage *types.FilesystemUsage)
+	SetMetadataOperationLatencyOnMountProbe(namespace, pieProbeName, node, storageClass, operation string, latency float64)
+	AddMetadataOperationErrorsOnMountProbe(namespace, pieProbeName, node, storageClass, operation string, count int)
+	IncrementProvisionProbeCount(namespace, pieProbeName, node, storageClass, topology, volumeMode string, onTime bool)
+	IncrementMountProbeCount(namespace, pieProbeName, node, storageClass, volumeMode string, onTime bool)
+	SetPVCRotationDeleteDuration(namespace, pieProbeName, node, storageClass string, duration float64)
+	SetPVCRotationRebindDuration(namespace, pieProbeName, node, storageClass string, duration float64)
+	IncrementReclaimProbeCount(namespace, pieProbeName, storageClass string, onTime bool)
+	SetLeakedPersistentVolumeCount(namespace, pieProbeName, storageClass string, count int)
+	DeleteLeakedPersistentVolumeCount(namespace, pieProbeName, storageClass string)
+	IncrementSnapshotProbeCount(namespace, pieProbeName, node, storageClass, stage string, succeed bool)
+	SetSnapshotProbeDuration(namespace, pieProbeName, node, storageClass, stage string, duration float64)
+	IncrementExpansionProbeCount(namespace, pieProbeName, node, storageClass, stage string, succeed bool)
+	SetExpansionProbeDuration(namespace, pieProbeName, node, storageClass, stage string, duration float64)
+	IncrementCloneProbeCount(namespace, pieProbeName, node, storageClass, stage string, succeed bool)
+	SetCloneProbeDuration(namespace, pieProbeName, node, storageClass, stage string, duration float64)
+	IncrementSharedAccessProbeCount(namespace, pieProbeName, node, storageClass string, succeed bool)
+	SetSharedAccessVisibilityLatency(namespace, pieProbeName, node, storageClass string, latency float64)
+	AddSharedAccessConsistencyErrors(namespace, pieProbeName, node, storageClass string, count int)
+	IncrementFailoverProbeCount(namespace, pieProbeName, storageClass, stage string, onTime bool)
+	SetPersistentVolumeMismatched(namespace, pieProbeName, node, storageClass, field string, mismatched bool)
+	SetMountMismatched(namespace, pieProbeName, node, storageClass, field string, mismatched bool)
+	SetFailoverProbeDuration(namespace, pieProbeName, storageClass, stage string, duration float64)
+	DeleteMetricsOfStorageClass(namespace, pieProbeName, storageClass string)
+	DeleteMetricsOfPieProbe(namespace, pieProbeName string)
 }
 
 type metricExporterImpl struct {
 	writeLatencyOnMountProbeGauge *prometheus.GaugeVec
 	readLatencyOnMountProbeGauge  *prometheus.GaugeVec
 	performanceOnMountProbeCount  *prometheus.CounterVec
+	performanceOnMountProbeFails  *prometheus.CounterVec
+	filesystemSizeBytesGauge      *prometheus.GaugeVec
+	filesystemFreeBytesGauge      *prometheus.GaugeVec
+	filesystemInodesGauge         *prometheus.GaugeVec
+	filesystemFreeInodesGauge     *prometheus.GaugeVec
+	metadataOperationLatency      *prometheus.GaugeVec
+	metadataOperationErrors       *prometheus.CounterVec
 	provisionProbeCount           *prometheus.CounterVec
 	mountProbeCount               *prometheus.CounterVec
+	pvcRotationDeleteDuration     *prometheus.GaugeVec
+	pvcRotationRebindDuration     *prometheus.GaugeVec
+	reclaimProbeCount             *prometheus.CounterVec
+	leakedPersistentVolumeGauge   *prometheus.GaugeVec
+	snapshotProbeCount            *prometheus.CounterVec
+	snapshotProbeDurationGauge    *prometheus.GaugeVec
+	expansionProbeCount           *prometheus.CounterVec
+	expansionProbeDurationGauge   *prometheus.GaugeVec
+	cloneProbeCount               *prometheus.CounterVec
+	cloneProbeDurationGauge       *prometheus.GaugeVec
+	sharedAccessProbeCount        *prometheus.CounterVec
+	sharedAccessVisibilityLatency *prometheus.GaugeVec
+	sharedAccessConsistencyErrors *prometheus.CounterVec
+	failoverProbeCount            *prometheus.CounterVec
+	failoverProbeDurationGauge    *prometheus.GaugeVec
+	persistentVolumeMismatchGauge *prometheus.GaugeVec
+	mountMismatchGauge            *prometheus.GaugeVec
 }
 
 func NewMetrics() MetricsExporter {
@@ -40,7 +92,7 @@ func (m *metricExporterImpl) registerMetrics() {
 			Name:      "io_write_latency_on_mount_probe_seconds",
 			Help:      "IO latency of write.",
 		},
-		[]string{"pie_probe_name", "node", "storage_class"})
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "volume_mode", "io_mode"})
 
 	metrics.Registry.MustRegister(m.writeLatencyOnMountProbeGauge)
 
@@ -50,7 +102,7 @@ func (m *metricExporterImpl) registerMetrics() {
 			Name:      "io_read_latency_on_mount_probe_seconds",
 			Help:      "IO latency of read.",
 		},
-		[]string{"pie_probe_name", "node", "storage_class"})
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "volume_mode", "io_mode"})
 
 	metrics.Registry.MustRegister(m.readLatencyOnMountProbeGauge)
 
@@ -60,17 +112,87 @@ func (m *metricExporterImpl) registerMetrics() {
 			Name:      "performance_on_mount_probe_total",
 			Help:      "The number of performance tests on a probe container.",
 		},
-		[]string{"pie_probe_name", "node", "storage_class", "succeed"})
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "volume_mode", "succeed"})
 
 	metrics.Registry.MustRegister(m.performanceOnMountProbeCount)
 
+	m.performanceOnMountProbeFails = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pie",
+			Name:      "performance_on_mount_probe_failure_total",
+			Help:      "The number of failed performance tests on a probe container by the class of the error.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "volume_mode", "reason"})
+
+	metrics.Registry.MustRegister(m.performanceOnMountProbeFails)
+
+	m.filesystemSizeBytesGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pie",
+			Name:      "filesystem_size_bytes_on_mount_probe",
+			Help:      "The size of the filesystem of the mount-probe PVC.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class"})
+
+	metrics.Registry.MustRegister(m.filesystemSizeBytesGauge)
+
+	m.filesystemFreeBytesGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pie",
+			Name:      "filesystem_free_bytes_on_mount_probe",
+			Help:      "The free bytes of the filesystem of the mount-probe PVC available to unprivileged users.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class"})
+
+	metrics.Registry.MustRegister(m.filesystemFreeBytesGauge)
+
+	m.filesystemInodesGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pie",
+			Name:      "filesystem_inodes_on_mount_probe",
+			Help:      "The number of the inodes of the filesystem of the mount-probe PVC.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class"})
+
+	metrics.Registry.MustRegister(m.filesystemInodesGauge)
+
+	m.filesystemFreeInodesGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pie",
+			Name:      "filesystem_free_inodes_on_mount_probe",
+			Help:      "The number of the free inodes of the filesystem of the mount-probe PVC.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class"})
+
+	metrics.Registry.MustRegister(m.filesystemFreeInodesGauge)
+
+	m.metadataOperationLatency = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pie",
+			Name:      "metadata_operation_latency_on_mount_probe_seconds",
+			Help:      "The mean latency of each operation of the metadata benchmark.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "operation"})
+
+	metrics.Registry.MustRegister(m.metadataOperationLatency)
+
+	m.metadataOperationErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pie",
+			Name:      "metadata_operation_errors_on_mount_probe_total",
+			Help:      "The number of the errors of each operation of the metadata benchmark.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "operation"})
+
+	metrics.Registry.MustRegister(m.metadataOperationErrors)
+
 	m.provisionProbeCount = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: "pie",
 			Name:      "provision_probe_total",
 			Help:      "The number of attempts that the provision of the Pod object and the creation of the container.",
 		},
-		[]string{"pie_probe_name", "storage_class", "on_time"})
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "topology", "volume_mode", "on_time"})
 
 	metrics.Registry.MustRegister(m.provisionProbeCount)
 
@@ -80,39 +202,437 @@ func (m *metricExporterImpl) registerMetrics() {
 			Name:      "mount_probe_total",
 			Help:      "The number of attempts that the mount of the Pod object and the creation of the container.",
 		},
-		[]string{"pie_probe_name", "node", "storage_class", "on_time"})
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "volume_mode", "on_time"})
 
 	metrics.Registry.MustRegister(m.mountProbeCount)
+
+	m.pvcRotationDeleteDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pie",
+			Name:      "pvc_rotation_delete_duration_seconds",
+			Help:      "The duration to delete the PVC of the mount probe on its rotation.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class"})
+
+	metrics.Registry.MustRegister(m.pvcRotationDeleteDuration)
+
+	m.pvcRotationRebindDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pie",
+			Name:      "pvc_rotation_rebind_duration_seconds",
+			Help:      "The duration to bind the PVC of the mount probe recreated on its rotation.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class"})
+
+	metrics.Registry.MustRegister(m.pvcRotationRebindDuration)
+
+	m.reclaimProbeCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pie",
+			Name:      "reclaim_probe_total",
+			Help:      "The number of attempts that the PV of the probe is deleted after the PVC is deleted.",
+		},
+		[]string{"namespace", "pie_probe_name", "storage_class", "on_time"})
+
+	metrics.Registry.MustRegister(m.reclaimProbeCount)
+
+	m.leakedPersistentVolumeGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pie",
+			Name:      "leaked_persistent_volumes",
+			Help:      "The number of PVs of the probes stuck in the Released or Failed phase.",
+		},
+		[]string{"namespace", "pie_probe_name", "storage_class"})
+
+	metrics.Registry.MustRegister(m.leakedPersistentVolumeGauge)
+
+	m.snapshotProbeCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pie",
+			Name:      "snapshot_probe_total",
+			Help:      "The number of attempts of each stage of the snapshot probe.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "stage", "succeed"})
+
+	metrics.Registry.MustRegister(m.snapshotProbeCount)
+
+	m.snapshotProbeDurationGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pie",
+			Name:      "snapshot_probe_duration_seconds",
+			Help:      "The duration of each stage of the snapshot probe.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "stage"})
+
+	metrics.Registry.MustRegister(m.snapshotProbeDurationGauge)
+
+	m.expansionProbeCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pie",
+			Name:      "expansion_probe_total",
+			Help:      "The number of attempts of each stage of the expansion probe.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "stage", "succeed"})
+
+	metrics.Registry.MustRegister(m.expansionProbeCount)
+
+	m.expansionProbeDurationGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pie",
+			Name:      "expansion_probe_duration_seconds",
+			Help:      "The duration of each stage of the expansion probe.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "stage"})
+
+	metrics.Registry.MustRegister(m.expansionProbeDurationGauge)
+
+	m.cloneProbeCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pie",
+			Name:      "clone_probe_total",
+			Help:      "The number of attempts of each stage of the clone probe.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "stage", "succeed"})
+
+	metrics.Registry.MustRegister(m.cloneProbeCount)
+
+	m.cloneProbeDurationGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pie",
+			Name:      "clone_probe_duration_seconds",
+			Help:      "The duration of each stage of the clone probe.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "stage"})
+
+	metrics.Registry.MustRegister(m.cloneProbeDurationGauge)
+
+	m.sharedAccessProbeCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pie",
+			Name:      "shared_access_probe_total",
+			Help:      "The number of attempts of the shared-access probe on each node.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "succeed"})
+
+	metrics.Registry.MustRegister(m.sharedAccessProbeCount)
+
+	m.sharedAccessVisibilityLatency = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pie",
+			Name:      "shared_access_visibility_latency_seconds",
+			Help:      "The longest time until the files written on the other nodes are seen on the node.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class"})
+
+	metrics.Registry.MustRegister(m.sharedAccessVisibilityLatency)
+
+	m.sharedAccessConsistencyErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pie",
+			Name:      "shared_access_consistency_errors_total",
+			Help:      "The number of the files seen with unexpected content by the shared-access probe.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class"})
+
+	metrics.Registry.MustRegister(m.sharedAccessConsistencyErrors)
+
+	m.failoverProbeCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pie",
+			Name:      "failover_probe_total",
+			Help:      "The number of attempts of each stage of the failover probe.",
+		},
+		[]string{"namespace", "pie_probe_name", "storage_class", "stage", "on_time"})
+
+	metrics.Registry.MustRegister(m.failoverProbeCount)
+
+	m.failoverProbeDurationGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pie",
+			Name:      "failover_probe_duration_seconds",
+			Help:      "The duration of each stage of the failover probe.",
+		},
+		[]string{"namespace", "pie_probe_name", "storage_class", "stage"})
+
+	metrics.Registry.MustRegister(m.failoverProbeDurationGauge)
+
+	m.persistentVolumeMismatchGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pie",
+			Name:      "persistent_volume_mismatched",
+			Help:      "Whether the field of the PV bound to the mount-probe PVC does not match the expected one.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "field"})
+
+	metrics.Registry.MustRegister(m.persistentVolumeMismatchGauge)
+
+	m.mountMismatchGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pie",
+			Name:      "mount_mismatched",
+			Help:      "Whether the field of the mount seen by the mount probe does not match the StorageClass.",
+		},
+		[]string{"namespace", "pie_probe_name", "node", "storage_class", "field"})
+
+	metrics.Registry.MustRegister(m.mountMismatchGauge)
 }
 
 func (m *metricExporterImpl) SetLatencyOnMountProbe(
-	pieProbeName, node, storageClass string,
+	namespace, pieProbeName, node, storageClass, volumeMode, ioMode string,
 	readLatency, writeLatency float64,
 ) {
-	m.writeLatencyOnMountProbeGauge.WithLabelValues(pieProbeName, node, storageClass).Set(writeLatency)
-	m.readLatencyOnMountProbeGauge.WithLabelValues(pieProbeName, node, storageClass).Set(readLatency)
+	// Only the latencies in the last I/O mode are exported not to be compared with the ones in the other mode.
+	labels := prometheus.Labels{
+		"namespace":      namespace,
+		"pie_probe_name": pieProbeName,
+		"node":           node,
+		"storage_class":  storageClass,
+	}
+	m.writeLatencyOnMountProbeGauge.DeletePartialMatch(labels)
+	m.readLatencyOnMountProbeGauge.DeletePartialMatch(labels)
+
+	m.writeLatencyOnMountProbeGauge.WithLabelValues(namespace, pieProbeName, node, storageClass, volumeMode, ioMode).
+		Set(writeLatency)
+	m.readLatencyOnMountProbeGauge.WithLabelValues(namespace, pieProbeName, node, storageClass, volumeMode, ioMode).
+		Set(readLatency)
 }
 
 func (m *metricExporterImpl) IncrementPerformanceOnMountProbeCount(
-	pieProbeName, node, storageClass string,
+	namespace, pieProbeName, node, storageClass, volumeMode string,
 	succeed bool,
 ) {
 	succeedStr := strconv.FormatBool(succeed)
-	m.performanceOnMountProbeCount.WithLabelValues(pieProbeName, node, storageClass, succeedStr).Inc()
+	m.performanceOnMountProbeCount.
+		WithLabelValues(namespace, pieProbeName, node, storageClass, volumeMode, succeedStr).Inc()
+}
+
+func (m *metricExporterImpl) IncrementPerformanceOnMountProbeFailureCount(
+	namespace, pieProbeName, node, storageClass, volumeMode, reason string,
+) {
+	m.performanceOnMountProbeFails.WithLabelValues(namespace, pieProbeName, node, storageClass, volumeMode, reason).Inc()
+}
+
+func (m *metricExporterImpl) SetFilesystemUsageOnMountProbe(
+	namespace, pieProbeName, node, storageClass string,
+	usage *types.FilesystemUsage,
+) {
+	m.filesystemSizeBytesGauge.WithLabelValues(namespace, pieProbeName, node, storageClass).Set(float64(usage.TotalBytes))
+	m.filesystemFreeBytesGauge.WithLabelValues(namespace, pieProbeName, node, storageClass).Set(float64(usage.FreeBytes))
+	m.filesystemInodesGauge.WithLabelValues(namespace, pieProbeName, node, storageClass).Set(float64(usage.TotalInodes))
+	m.filesystemFreeInodesGauge.WithLabelValues(namespace, pieProbeName, node, storageClass).Set(float64(usage.FreeInodes))
+}
+
+func (m *metricExporterImpl) SetMetadataOperationLatencyOnMountProbe(
+	namespace, pieProbeName, node, storageClass, operation string,
+	latency float64,
+) {
+	m.metadataOperationLatency.WithLabelValues(namespace, pieProbeName, node, storageClass, operation).Set(latency)
+}
+
+func (m *metricExporterImpl) AddMetadataOperationErrorsOnMountProbe(
+	namespace, pieProbeName, node, storageClass, operation string,
+	count int,
+) {
+	m.metadataOperationErrors.WithLabelValues(namespace, pieProbeName, node, storageClass, operation).Add(float64(count))
 }
 
-func (m *metricExporterImpl) IncrementProvisionProbeCount(pieProbeName string, storageClass string, onTime bool) {
+func (m *metricExporterImpl) IncrementProvisionProbeCount(
+	namespace, pieProbeName, node, storageClass, topology, volumeMode string,
+	onTime bool,
+) {
 	onTimeStr := "false"
 	if onTime {
 		onTimeStr = "true"
 	}
-	m.provisionProbeCount.WithLabelValues(pieProbeName, storageClass, onTimeStr).Inc()
+	m.provisionProbeCount.
+		WithLabelValues(namespace, pieProbeName, node, storageClass, topology, volumeMode, onTimeStr).Inc()
 }
 
 func (m *metricExporterImpl) IncrementMountProbeCount(
-	pieProbeName, node, storageClass string,
+	namespace, pieProbeName, node, storageClass, volumeMode string,
+	onTime bool,
+) {
+	onTimeStr := strconv.FormatBool(onTime)
+	m.mountProbeCount.WithLabelValues(namespace, pieProbeName, node, storageClass, volumeMode, onTimeStr).Inc()
+}
+
+func (m *metricExporterImpl) SetPVCRotationDeleteDuration(
+	namespace, pieProbeName, node, storageClass string,
+	duration float64,
+) {
+	m.pvcRotationDeleteDuration.WithLabelValues(namespace, pieProbeName, node, storageClass).Set(duration)
+}
+
+func (m *metricExporterImpl) SetPVCRotationRebindDuration(
+	namespace, pieProbeName, node, storageClass string,
+	duration float64,
+) {
+	m.pvcRotationRebindDuration.WithLabelValues(namespace, pieProbeName, node, storageClass).Set(duration)
+}
+
+func (m *metricExporterImpl) IncrementReclaimProbeCount(
+	namespace, pieProbeName, storageClass string,
+	onTime bool,
+) {
+	onTimeStr := strconv.FormatBool(onTime)
+	m.reclaimProbeCount.WithLabelValues(namespace, pieProbeName, storageClass, onTimeStr).Inc()
+}
+
+func (m *metricExporterImpl) SetLeakedPersistentVolumeCount(namespace, pieProbeName, storageClass string, count int) {
+	m.leakedPersistentVolumeGauge.WithLabelValues(namespace, pieProbeName, storageClass).Set(float64(count))
+}
+
+func (m *metricExporterImpl) DeleteLeakedPersistentVolumeCount(namespace, pieProbeName, storageClass string) {
+	m.leakedPersistentVolumeGauge.DeleteLabelValues(namespace, pieProbeName, storageClass)
+}
+
+func (m *metricExporterImpl) IncrementSnapshotProbeCount(
+	namespace, pieProbeName, node, storageClass, stage string,
+	succeed bool,
+) {
+	succeedStr := strconv.FormatBool(succeed)
+	m.snapshotProbeCount.WithLabelValues(namespace, pieProbeName, node, storageClass, stage, succeedStr).Inc()
+}
+
+func (m *metricExporterImpl) SetSnapshotProbeDuration(
+	namespace, pieProbeName, node, storageClass, stage string,
+	duration float64,
+) {
+	m.snapshotProbeDurationGauge.WithLabelValues(namespace, pieProbeName, node, storageClass, stage).Set(duration)
+}
+
+func (m *metricExporterImpl) IncrementExpansionProbeCount(
+	namespace, pieProbeName, node, storageClass, stage string,
+	succeed bool,
+) {
+	succeedStr := strconv.FormatBool(succeed)
+	m.expansionProbeCount.WithLabelValues(namespace, pieProbeName, node, storageClass, stage, succeedStr).Inc()
+}
+
+func (m *metricExporterImpl) SetExpansionProbeDuration(
+	namespace, pieProbeName, node, storageClass, stage string,
+	duration float64,
+) {
+	m.expansionProbeDurationGauge.WithLabelValues(namespace, pieProbeName, node, storageClass, stage).Set(duration)
+}
+
+func (m *metricExporterImpl) IncrementCloneProbeCount(
+	namespace, pieProbeName, node, storageClass, stage string,
+	succeed bool,
+) {
+	succeedStr := strconv.FormatBool(succeed)
+	m.cloneProbeCount.WithLabelValues(namespace, pieProbeName, node, storageClass, stage, succeedStr).Inc()
+}
+
+func (m *metricExporterImpl) SetCloneProbeDuration(
+	namespace, pieProbeName, node, storageClass, stage string,
+	duration float64,
+) {
+	m.cloneProbeDurationGauge.WithLabelValues(namespace, pieProbeName, node, storageClass, stage).Set(duration)
+}
+
+func (m *metricExporterImpl) IncrementSharedAccessProbeCount(
+	namespace, pieProbeName, node, storageClass string,
+	succeed bool,
+) {
+	succeedStr := strconv.FormatBool(succeed)
+	m.sharedAccessProbeCount.WithLabelValues(namespace, pieProbeName, node, storageClass, succeedStr).Inc()
+}
+
+func (m *metricExporterImpl) SetSharedAccessVisibilityLatency(
+	namespace, pieProbeName, node, storageClass string,
+	latency float64,
+) {
+	m.sharedAccessVisibilityLatency.WithLabelValues(namespace, pieProbeName, node, storageClass).Set(latency)
+}
+
+func (m *metricExporterImpl) AddSharedAccessConsistencyErrors(
+	namespace, pieProbeName, node, storageClass string,
+	count int,
+) {
+	m.sharedAccessConsistencyErrors.WithLabelValues(namespace, pieProbeName, node, storageClass).Add(float64(count))
+}
+
+func (m *metricExporterImpl) IncrementFailoverProbeCount(
+	namespace, pieProbeName, storageClass, stage string,
 	onTime bool,
 ) {
 	onTimeStr := strconv.FormatBool(onTime)
-	m.mountProbeCount.WithLabelValues(pieProbeName, node, storageClass, onTimeStr).Inc()
+	m.failoverProbeCount.WithLabelValues(namespace, pieProbeName, storageClass, stage, onTimeStr).Inc()
+}
+
+func (m *metricExporterImpl) SetFailoverProbeDuration(
+	namespace, pieProbeName, storageClass, stage string,
+	duration float64,
+) {
+	m.failoverProbeDurationGauge.WithLabelValues(namespace, pieProbeName, storageClass, stage).Set(duration)
+}
+
+func (m *metricExporterImpl) SetPersistentVolumeMismatched(
+	namespace, pieProbeName, node, storageClass, field string,
+	mismatched bool,
+) {
+	value := 0.0
+	if mismatched {
+		value = 1.0
+	}
+	m.persistentVolumeMismatchGauge.WithLabelValues(namespace, pieProbeName, node, storageClass, field).Set(value)
+}
+
+func (m *metricExporterImpl) SetMountMismatched(
+	namespace, pieProbeName, node, storageClass, field string,
+	mismatched bool,
+) {
+	value := 0.0
+	if mismatched {
+		value = 1.0
+	}
+	m.mountMismatchGauge.WithLabelValues(namespace, pieProbeName, node, storageClass, field).Set(value)
+}
+
+func (m *metricExporterImpl) DeleteMetricsOfStorageClass(namespace, pieProbeName, storageClass string) {
+	m.deletePartialMatch(prometheus.Labels{
+		"namespace":      namespace,
+		"pie_probe_name": pieProbeName,
+		"storage_class":  storageClass,
+	})
+}
+
+func (m *metricExporterImpl) DeleteMetricsOfPieProbe(namespace, pieProbeName string) {
+	m.deletePartialMatch(prometheus.Labels{
+		"namespace":      namespace,
+		"pie_probe_name": pieProbeName,
+	})
+}
+
+func (m *metricExporterImpl) deletePartialMatch(labels prometheus.Labels) {
+	m.writeLatencyOnMountProbeGauge.DeletePartialMatch(labels)
+	m.readLatencyOnMountProbeGauge.DeletePartialMatch(labels)
+	m.performanceOnMountProbeCount.DeletePartialMatch(labels)
+	m.performanceOnMountProbeFails.DeletePartialMatch(labels)
+	m.filesystemSizeBytesGauge.DeletePartialMatch(labels)
+	m.filesystemFreeBytesGauge.DeletePartialMatch(labels)
+	m.filesystemInodesGauge.DeletePartialMatch(labels)
+	m.filesystemFreeInodesGauge.DeletePartialMatch(labels)
+	m.metadataOperationLatency.DeletePartialMatch(labels)
+	m.metadataOperationErrors.DeletePartialMatch(labels)
+	m.provisionProbeCount.DeletePartialMatch(labels)
+	m.mountProbeCount.DeletePartialMatch(labels)
+	m.pvcRotationDeleteDuration.DeletePartialMatch(labels)
+	m.pvcRotationRebindDuration.DeletePartialMatch(labels)
+	m.reclaimProbeCount.DeletePartialMatch(labels)
+	m.leakedPersistentVolumeGauge.DeletePartialMatch(labels)
+	m.snapshotProbeCount.DeletePartialMatch(labels)
+	m.snapshotProbeDurationGauge.DeletePartialMatch(labels)
+	m.expansionProbeCount.DeletePartialMatch(labels)
+	m.expansionProbeDurationGauge.DeletePartialMatch(labels)
+	m.cloneProbeCount.DeletePartialMatch(labels)
+	m.cloneProbeDurationGauge.DeletePartialMatch(labels)
+	m.sharedAccessProbeCount.DeletePartialMatch(labels)
+	m.sharedAccessVisibilityLatency.DeletePartialMatch(labels)
+	m.sharedAccessConsistencyErrors.DeletePartialMatch(labels)
+	m.failoverProbeCount.DeletePartialMatch(labels)
+	m.failoverProbeDurationGauge.DeletePartialMatch(labels)
+	m.persistentVolumeMismatchGauge.DeletePartialMatch(labels)
+	m.mountMismatchGauge.DeletePartialMatch(labels)
 }
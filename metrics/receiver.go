@@ -2,17 +2,173 @@ package metrics
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/topolvm/pie/types"
 )
 
+// MountObserver observes the mounts reported by the mount probes.
+type MountObserver interface {
+	ObserveMount(namespace, pieProbeName, node, storageClass string, mount *types.MountInfo)
+}
+
+// runIDRetention is how long the run IDs of the received results are remembered to ignore the duplicates.
+// The duplicates are the results delivered but not acknowledged, which are replayed on the next runs of the probes.
+const runIDRetention = time.Hour
+
+// runIDCache remembers the run IDs of the received results for runIDRetention.
+type runIDCache struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+	// queue holds the run IDs in the order received to forget the old ones.
+	queue []receivedRunID
+}
+
+type receivedRunID struct {
+	runID      string
+	receivedAt time.Time
+}
+
+// add remembers the run ID. It returns false if the run ID has been received already.
+func (c *runIDCache) add(runID string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.queue) != 0 && now.Sub(c.queue[0].receivedAt) > runIDRetention {
+		delete(c.seen, c.queue[0].runID)
+		c.queue = c.queue[1:]
+	}
+
+	if _, ok := c.seen[runID]; ok {
+		return false
+	}
+	c.seen[runID] = struct{}{}
+	c.queue = append(c.queue, receivedRunID{runID: runID, receivedAt: now})
+	return true
+}
+
+// ResultRecorder records the results of the mount probes to the metrics.
+// The results are delivered by the receiver or read from the termination messages of the probe containers.
+type ResultRecorder struct {
+	metrics       MetricsExporter
+	mountObserver MountObserver
+	runIDs        *runIDCache
+}
+
+// NewResultRecorder returns the ResultRecorder.
+// The mounts reported by the mount probes are passed to the observer if it is not nil.
+func NewResultRecorder(m MetricsExporter, mountObserver MountObserver) *ResultRecorder {
+	return &ResultRecorder{
+		metrics:       m,
+		mountObserver: mountObserver,
+		runIDs: &runIDCache{
+			seen: map[string]struct{}{},
+		},
+	}
+}
+
+// Record records the result. It returns false if the result with the same run ID has been recorded already.
+func (rr *ResultRecorder) Record(result *types.ResultEnvelope) bool {
+	// The results replayed from the spools may have been received already.
+	if result.RunID != "" && !rr.runIDs.add(result.RunID, time.Now()) {
+		return false
+	}
+
+	id := &result.Identity
+	for _, measurement := range result.Measurements {
+		switch {
+		case measurement.Type == types.MeasurementTypePerformance && measurement.Performance != nil:
+			rr.recordPerformance(id, measurement.Performance)
+		case measurement.Type == types.MeasurementTypeMount && measurement.Mount != nil:
+			if rr.mountObserver != nil {
+				rr.mountObserver.ObserveMount(id.Namespace, id.PieProbeName, id.Node, id.StorageClass, measurement.Mount)
+			}
+		case measurement.Type == types.MeasurementTypeFilesystemUsage && measurement.FilesystemUsage != nil:
+			rr.metrics.SetFilesystemUsageOnMountProbe(
+				id.Namespace,
+				id.PieProbeName,
+				id.Node,
+				id.StorageClass,
+				measurement.FilesystemUsage,
+			)
+		case measurement.Type == types.MeasurementTypeMetadataOperation && measurement.MetadataOperation != nil:
+			operation := measurement.MetadataOperation
+			// The latency is 0 if none of the operations succeeded.
+			if operation.Latency != 0 {
+				rr.metrics.SetMetadataOperationLatencyOnMountProbe(
+					id.Namespace,
+					id.PieProbeName,
+					id.Node,
+					id.StorageClass,
+					operation.Operation,
+					operation.Latency,
+				)
+			}
+			rr.metrics.AddMetadataOperationErrorsOnMountProbe(
+				id.Namespace,
+				id.PieProbeName,
+				id.Node,
+				id.StorageClass,
+				operation.Operation,
+				operation.Errors,
+			)
+		}
+		// The types of the measurements added in the newer minor versions are ignored.
+	}
+	return true
+}
+
+func (rr *ResultRecorder) recordPerformance(id *types.ProbeIdentity, performance *types.PerformanceMeasurement) {
+	// The latencies are not valid if the probe failed.
+	if performance.FailureReason == "" {
+		rr.metrics.SetLatencyOnMountProbe(
+			id.Namespace,
+			id.PieProbeName,
+			id.Node,
+			id.StorageClass,
+			performance.VolumeMode,
+			performance.IOMode,
+			performance.ReadLatency,
+			performance.WriteLatency,
+		)
+	}
+	rr.metrics.IncrementPerformanceOnMountProbeCount(
+		id.Namespace,
+		id.PieProbeName,
+		id.Node,
+		id.StorageClass,
+		performance.VolumeMode,
+		performance.Succeeded,
+	)
+	if !performance.Succeeded {
+		// The older probes don't classify the errors.
+		reason := performance.FailureReason
+		if reason == "" {
+			reason = types.FailureReasonUnknown
+		}
+		rr.metrics.IncrementPerformanceOnMountProbeFailureCount(
+			id.Namespace,
+			id.PieProbeName,
+			id.Node,
+			id.StorageClass,
+			performance.VolumeMode,
+			reason,
+		)
+	}
+}
+
 type receiver struct {
-	metrics MetricsExporter
+	recorder *ResultRecorder
 }
 
+// ServeHTTP records the result of a mount probe.
+// It responds 422 Unprocessable Entity to the results of the unsupported major versions,
+// and 400 Bad Request to the malformed ones.
 func (rh *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	data, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -20,7 +176,38 @@ func (rh *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var receivedData types.MetricsExchangeFormat
+	result, err := DecodeResult(data)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrUnsupportedSchemaVersion) {
+			status = http.StatusUnprocessableEntity
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	// The duplicates are acknowledged not to be replayed again.
+	if !rh.recorder.Record(result) {
+		slog.Info("ignoring the duplicate result", "run_id", result.RunID)
+	}
+
+	if _, err := w.Write([]byte("OK")); err != nil {
+		slog.Error("failed to write data", "error", err)
+	}
+}
+
+type sharedAccessReceiver struct {
+	metrics MetricsExporter
+}
+
+func (rh *sharedAccessReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var receivedData types.SharedAccessExchangeFormat
 	err = json.Unmarshal(data, &receivedData)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -32,18 +219,21 @@ func (rh *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rh.metrics.SetLatencyOnMountProbe(
-		receivedData.PieProbeName,
-		receivedData.Node,
-		receivedData.StorageClass,
-		receivedData.ReadLatency,
-		receivedData.WriteLatency,
-	)
-	rh.metrics.IncrementPerformanceOnMountProbeCount(
+	if receivedData.AllVisible {
+		rh.metrics.SetSharedAccessVisibilityLatency(
+			receivedData.Namespace,
+			receivedData.PieProbeName,
+			receivedData.Node,
+			receivedData.StorageClass,
+			receivedData.VisibilityLatency,
+		)
+	}
+	rh.metrics.AddSharedAccessConsistencyErrors(
+		receivedData.Namespace,
 		receivedData.PieProbeName,
 		receivedData.Node,
 		receivedData.StorageClass,
-		receivedData.PerformanceProbeSucceed,
+		receivedData.ConsistencyErrors,
 	)
 
 	if _, err := w.Write([]byte("OK")); err != nil {
@@ -51,8 +241,16 @@ func (rh *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func NewReceiver(m MetricsExporter) http.Handler {
-	return &receiver{
+// NewReceiver returns the handler receiving the results of the probes.
+// The results of the shared-access probes are posted to types.SharedAccessProbePath,
+// and the others are posted to any other path and recorded by the recorder.
+func NewReceiver(m MetricsExporter, recorder *ResultRecorder) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", &receiver{
+		recorder: recorder,
+	})
+	mux.Handle(types.SharedAccessProbePath, &sharedAccessReceiver{
 		metrics: m,
-	}
+	})
+	return mux
 }
@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/topolvm/pie/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	// ErrUnsupportedSchemaVersion is returned if the major version of the result is not types.ResultSchemaMajorVersion.
+	ErrUnsupportedSchemaVersion = errors.New("unsupported schema version")
+	// ErrInvalidResult is returned if the result is malformed.
+	ErrInvalidResult = errors.New("invalid result")
+)
+
+// DecodeResult decodes the result of a mount probe.
+// The unversioned results of the older probes are converted into types.ResultEnvelope.
+func DecodeResult(data []byte) (*types.ResultEnvelope, error) {
+	var header struct {
+		SchemaVersion string `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidResult, err)
+	}
+
+	if header.SchemaVersion == "" {
+		var legacy types.MetricsExchangeFormat
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidResult, err)
+		}
+		if legacy.PieProbeName == "" {
+			return nil, fmt.Errorf("%w: PieProbeName is empty", ErrInvalidResult)
+		}
+		return convertLegacyResult(&legacy), nil
+	}
+
+	if err := validateSchemaVersion(header.SchemaVersion); err != nil {
+		return nil, err
+	}
+
+	var result types.ResultEnvelope
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidResult, err)
+	}
+	if err := ValidateResult(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ValidateResult checks the schema version, the kind and the identity of the result of a mount probe.
+func ValidateResult(result *types.ResultEnvelope) error {
+	if err := validateSchemaVersion(result.SchemaVersion); err != nil {
+		return err
+	}
+	if result.Identity.PieProbeName == "" {
+		return fmt.Errorf("%w: PieProbeName is empty", ErrInvalidResult)
+	}
+	if result.Kind != types.ProbeKindMount {
+		return fmt.Errorf("%w: unsupported probe kind %q", ErrInvalidResult, result.Kind)
+	}
+	return nil
+}
+
+func validateSchemaVersion(schemaVersion string) error {
+	major, _, _ := strings.Cut(schemaVersion, ".")
+	if v, err := strconv.Atoi(major); err != nil || v != types.ResultSchemaMajorVersion {
+		return fmt.Errorf("%w: %q, the supported major version is %d",
+			ErrUnsupportedSchemaVersion, schemaVersion, types.ResultSchemaMajorVersion)
+	}
+	return nil
+}
+
+// convertLegacyResult converts the result of the older probes into types.ResultEnvelope.
+func convertLegacyResult(legacy *types.MetricsExchangeFormat) *types.ResultEnvelope {
+	volumeMode := legacy.VolumeMode
+	if volumeMode == "" {
+		volumeMode = string(corev1.PersistentVolumeFilesystem)
+	}
+
+	// The older probes always benchmark with O_DIRECT.
+	ioMode := legacy.IOMode
+	if ioMode == "" {
+		ioMode = types.IOModeDirect
+	}
+
+	result := &types.ResultEnvelope{
+		RunID: legacy.RunID,
+		Kind:  types.ProbeKindMount,
+		Identity: types.ProbeIdentity{
+			PieProbeName: legacy.PieProbeName,
+			Node:         legacy.Node,
+			StorageClass: legacy.StorageClass,
+		},
+		StartedAt:  legacy.Timestamp,
+		FinishedAt: legacy.Timestamp,
+		Measurements: []types.Measurement{
+			{
+				Type: types.MeasurementTypePerformance,
+				Performance: &types.PerformanceMeasurement{
+					VolumeMode:    volumeMode,
+					IOMode:        ioMode,
+					Succeeded:     legacy.PerformanceProbeSucceed,
+					FailureReason: legacy.FailureReason,
+					ReadLatency:   legacy.ReadLatency,
+					WriteLatency:  legacy.WriteLatency,
+				},
+			},
+		},
+	}
+	if legacy.Mount != nil {
+		result.Measurements = append(result.Measurements, types.Measurement{
+			Type:  types.MeasurementTypeMount,
+			Mount: legacy.Mount,
+		})
+	}
+	if legacy.Usage != nil {
+		result.Measurements = append(result.Measurements, types.Measurement{
+			Type:            types.MeasurementTypeFilesystemUsage,
+			FilesystemUsage: legacy.Usage,
+		})
+	}
+	for _, operation := range legacy.MetadataOperations {
+		result.Measurements = append(result.Measurements, types.Measurement{
+			Type:              types.MeasurementTypeMetadataOperation,
+			MetadataOperation: &operation,
+		})
+	}
+	return result
+}
@@ -0,0 +1,235 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/topolvm/pie/types"
+)
+
+const (
+	testEnvelope = `{
+		"schema_version": %q,
+		"run_id": "run-1",
+		"kind": "mount",
+		"identity": {"pie_probe_name": "pie-probe", "node": "node1", "storage_class": "sc"},
+		"measurements": [
+			{"type": "performance", "performance": {"volume_mode": "Block", "io_mode": "buffered", "succeeded": true}},
+			{"type": "unknown_in_this_version"}
+		]
+	}`
+	testLegacyResult = `{
+		"pie_probe_name": "pie-probe",
+		"node": "node1",
+		"storage_class": "sc",
+		"performance_probe_succeed": true,
+		"mount": {"filesystem_type": "ext4", "mount_options": ["rw"], "read_only": false},
+		"usage": {"total_bytes": 100, "free_bytes": 50, "total_inodes": 10, "free_inodes": 5},
+		"metadata_operations": [{"operation": "create", "latency": 0.1}, {"operation": "stat", "latency": 0.2}]
+	}`
+)
+
+func makeTestEnvelope(schemaVersion string) string {
+	return fmt.Sprintf(testEnvelope, schemaVersion)
+}
+
+func TestDecodeResult(t *testing.T) {
+	testCases := []struct {
+		name             string
+		data             string
+		wantErr          error
+		wantVolumeMode   string
+		wantIOMode       string
+		wantMeasurements int
+	}{
+		{
+			name:             "envelope of the supported version",
+			data:             makeTestEnvelope(types.ResultSchemaVersion),
+			wantVolumeMode:   "Block",
+			wantIOMode:       types.IOModeBuffered,
+			wantMeasurements: 2,
+		},
+		{
+			name:             "envelope of a newer minor version",
+			data:             makeTestEnvelope("1.99"),
+			wantVolumeMode:   "Block",
+			wantIOMode:       types.IOModeBuffered,
+			wantMeasurements: 2,
+		},
+		{
+			name:    "envelope of an unsupported major version",
+			data:    makeTestEnvelope("2.0"),
+			wantErr: ErrUnsupportedSchemaVersion,
+		},
+		{
+			name:    "envelope of a malformed version",
+			data:    makeTestEnvelope("one"),
+			wantErr: ErrUnsupportedSchemaVersion,
+		},
+		{
+			name:    "envelope without PieProbeName",
+			data:    strings.Replace(makeTestEnvelope("1.0"), `"pie-probe"`, `""`, 1),
+			wantErr: ErrInvalidResult,
+		},
+		{
+			name:    "envelope of an unsupported kind",
+			data:    strings.Replace(makeTestEnvelope("1.0"), `"mount"`, `"snapshot"`, 1),
+			wantErr: ErrInvalidResult,
+		},
+		{
+			name:             "legacy result converted into the envelope",
+			data:             testLegacyResult,
+			wantVolumeMode:   "Filesystem",
+			wantIOMode:       types.IOModeDirect,
+			wantMeasurements: 5,
+		},
+		{
+			name:    "legacy result without PieProbeName",
+			data:    `{"node": "node1", "performance_probe_succeed": true}`,
+			wantErr: ErrInvalidResult,
+		},
+		{
+			name:    "malformed JSON",
+			data:    `{"schema_version": "1.0"`,
+			wantErr: ErrInvalidResult,
+		},
+		{
+			name:    "schema version of a wrong type",
+			data:    `{"schema_version": 1}`,
+			wantErr: ErrInvalidResult,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := DecodeResult([]byte(tc.data))
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("DecodeResult() = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeResult() failed: %v", err)
+			}
+			if result.Identity.PieProbeName != "pie-probe" || result.Kind != types.ProbeKindMount {
+				t.Errorf("identity = %+v, kind = %q", result.Identity, result.Kind)
+			}
+			if len(result.Measurements) != tc.wantMeasurements {
+				t.Fatalf("%d measurements, want %d", len(result.Measurements), tc.wantMeasurements)
+			}
+			performance := result.Measurements[0].Performance
+			if performance == nil || !performance.Succeeded {
+				t.Fatalf("performance = %+v, want the succeeded one", performance)
+			}
+			if performance.VolumeMode != tc.wantVolumeMode || performance.IOMode != tc.wantIOMode {
+				t.Errorf("volume mode = %q, io mode = %q, want %q and %q",
+					performance.VolumeMode, performance.IOMode, tc.wantVolumeMode, tc.wantIOMode)
+			}
+		})
+	}
+}
+
+// countingExporter counts the mount probes recorded by the ResultRecorder.
+type countingExporter struct {
+	MetricsExporter
+	performanceCount int
+}
+
+func (e *countingExporter) SetLatencyOnMountProbe(
+	namespace, pieProbeName, node, storageClass, volumeMode, ioMode string,
+	readLatency, writeLatency float64,
+) {
+}
+
+func (e *countingExporter) IncrementPerformanceOnMountProbeCount(
+	namespace, pieProbeName, node, storageClass, volumeMode string,
+	succeed bool,
+) {
+	e.performanceCount++
+}
+
+func (e *countingExporter) IncrementPerformanceOnMountProbeFailureCount(
+	namespace, pieProbeName, node, storageClass, volumeMode, reason string,
+) {
+}
+
+func (e *countingExporter) SetFilesystemUsageOnMountProbe(
+	namespace, pieProbeName, node, storageClass string,
+	usage *types.FilesystemUsage,
+) {
+}
+
+func (e *countingExporter) SetMetadataOperationLatencyOnMountProbe(
+	namespace, pieProbeName, node, storageClass, operation string,
+	latency float64,
+) {
+}
+
+func (e *countingExporter) AddMetadataOperationErrorsOnMountProbe(
+	namespace, pieProbeName, node, storageClass, operation string,
+	count int,
+) {
+}
+
+func TestReceiver(t *testing.T) {
+	exporter := &countingExporter{}
+	server := httptest.NewServer(NewReceiver(exporter, NewResultRecorder(exporter, nil)))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		data       string
+		wantStatus int
+		wantCount  int
+	}{
+		{
+			name:       "accepts the result",
+			data:       makeTestEnvelope(types.ResultSchemaVersion),
+			wantStatus: http.StatusOK,
+			wantCount:  1,
+		},
+		{
+			name:       "acknowledges the duplicate",
+			data:       makeTestEnvelope(types.ResultSchemaVersion),
+			wantStatus: http.StatusOK,
+			wantCount:  1,
+		},
+		{
+			name:       "responds 422 to the unsupported major version",
+			data:       makeTestEnvelope("2.0"),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCount:  1,
+		},
+		{
+			name:       "responds 400 to the malformed result",
+			data:       `{"schema_version": "1.0"`,
+			wantStatus: http.StatusBadRequest,
+			wantCount:  1,
+		},
+		{
+			name:       "accepts the legacy result",
+			data:       testLegacyResult,
+			wantStatus: http.StatusOK,
+			wantCount:  2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL, "application/json", strings.NewReader(tc.data))
+			if err != nil {
+				t.Fatalf("failed to post the result: %v", err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != tc.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tc.wantStatus)
+			}
+			if exporter.performanceCount != tc.wantCount {
+				t.Errorf("recorded %d results, want %d", exporter.performanceCount, tc.wantCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package probe
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+
+	"github.com/topolvm/pie/types"
+)
+
+// directIOAlignment is the alignment of the buffers and the sizes of O_DIRECT I/O.
+const directIOAlignment = 4096
+
+// alignedBuffer returns a buffer of the size aligned for O_DIRECT I/O.
+func alignedBuffer(size int) []byte {
+	buf := make([]byte, size+directIOAlignment)
+	offset := int(uintptr(unsafe.Pointer(&buf[0])) & (directIOAlignment - 1))
+	if offset != 0 {
+		offset = directIOAlignment - offset
+	}
+	return buf[offset : offset+size]
+}
+
+// detectIOMode returns types.IOModeBuffered if the target rejects O_DIRECT, and types.IOModeDirect otherwise.
+// A file is written next to the target file, while the raw block device is only read.
+// The other errors are left to the benchmark to fail and report.
+func detectIOMode(filename string, block bool) string {
+	var err error
+	if block {
+		err = tryDirectRead(filename)
+	} else {
+		err = tryDirectWrite(filepath.Join(filepath.Dir(filename), ".directiotest"))
+	}
+	ioMode := getIOMode(err)
+	if ioMode == types.IOModeBuffered {
+		log.Printf("O_DIRECT is not supported, falling back to buffered I/O: %v", err)
+	}
+	return ioMode
+}
+
+// getIOMode returns the I/O mode for the error of the O_DIRECT I/O.
+// The filesystems not supporting O_DIRECT fail to open or write the files with EINVAL or EOPNOTSUPP.
+func getIOMode(err error) string {
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.EOPNOTSUPP) {
+		return types.IOModeBuffered
+	}
+	return types.IOModeDirect
+}
+
+func tryDirectRead(devicePath string) error {
+	f, err := os.OpenFile(devicePath, os.O_RDONLY|syscall.O_DIRECT, 0)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	_, err = f.ReadAt(alignedBuffer(directIOAlignment), 0)
+	return err
+}
+
+func tryDirectWrite(filename string) error {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC|syscall.O_DIRECT, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(filename) }()
+	defer func() { _ = f.Close() }()
+	_, err = f.Write(alignedBuffer(directIOAlignment))
+	return err
+}
@@ -0,0 +1,64 @@
+package probe
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"github.com/topolvm/pie/types"
+)
+
+func TestGetIOMode(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "O_DIRECT succeeds",
+			err:  nil,
+			want: types.IOModeDirect,
+		},
+		{
+			name: "open is rejected with EINVAL",
+			err:  &fs.PathError{Op: "open", Path: "/mounted/.directiotest", Err: syscall.EINVAL},
+			want: types.IOModeBuffered,
+		},
+		{
+			name: "write is rejected with EOPNOTSUPP",
+			err:  &fs.PathError{Op: "write", Path: "/mounted/.directiotest", Err: syscall.EOPNOTSUPP},
+			want: types.IOModeBuffered,
+		},
+		{
+			name: "the other errors are left to the benchmark",
+			err:  &fs.PathError{Op: "open", Path: "/mounted/.directiotest", Err: syscall.EROFS},
+			want: types.IOModeDirect,
+		},
+		{
+			name: "error without errno",
+			err:  errors.New("short write"),
+			want: types.IOModeDirect,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := getIOMode(tc.err); got != tc.want {
+				t.Errorf("getIOMode(%v) = %q, want %q", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAlignedBuffer(t *testing.T) {
+	for _, size := range []int{directIOAlignment, 4 * directIOAlignment} {
+		buf := alignedBuffer(size)
+		if len(buf) != size {
+			t.Errorf("len(alignedBuffer(%d)) = %d", size, len(buf))
+		}
+		if addr := uintptr(unsafe.Pointer(&buf[0])); addr%directIOAlignment != 0 {
+			t.Errorf("alignedBuffer(%d) starts at %#x not aligned to %d", size, addr, directIOAlignment)
+		}
+	}
+}
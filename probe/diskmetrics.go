@@ -5,21 +5,39 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"strconv"
+	"syscall"
+
+	"github.com/topolvm/pie/types"
 )
 
 type diskMetricsImpl struct {
-	path string
+	filename string
+	offset   int64
+	block    bool
 }
 
 func NewDiskMetrics(path string) DiskMetricsInterface {
 	return &diskMetricsImpl{
-		path: path,
+		filename: filepath.Join(path, ".iotest"),
+	}
+}
+
+// NewBlockDiskMetrics returns the DiskMetricsInterface benchmarking the raw block device.
+// The known data at the head of the device is left untouched.
+func NewBlockDiskMetrics(devicePath string) DiskMetricsInterface {
+	return &diskMetricsImpl{
+		filename: devicePath,
+		offset:   knownBlockSize,
+		block:    true,
 	}
 }
 
-func execWrap(stdin []byte, command string, args ...string) ([]byte, error) {
-	c := exec.Command(command, args...)
+// execWrap runs the command and returns its stdout, which is also returned on failure.
+// The command is killed when the context is done.
+func execWrap(ctx context.Context, stdin []byte, command string, args ...string) ([]byte, error) {
+	c := exec.CommandContext(ctx, command, args...)
 	if stdin != nil {
 		c.Stdin = bytes.NewReader(stdin)
 	}
@@ -27,13 +45,17 @@ func execWrap(stdin []byte, command string, args ...string) ([]byte, error) {
 	c.Stdout = &stdoutBuf
 	c.Stderr = &stderrBuf
 	if err := c.Run(); err != nil {
-		return nil, fmt.Errorf("exec failed. stderr=%s, %w", stderrBuf.Bytes(), err)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = fmt.Errorf("%w: %w", ctxErr, err)
+		}
+		return stdoutBuf.Bytes(), fmt.Errorf("exec failed. stderr=%s, %w", stderrBuf.Bytes(), err)
 	}
 	return stdoutBuf.Bytes(), nil
 }
 
-func parseFioLatency(fioOutput []byte, property string) (float64, error) {
+func parseFioLatency(ctx context.Context, fioOutput []byte, property string) (float64, error) {
 	jqOut, err := execWrap(
+		ctx,
 		fioOutput,
 		"jq",
 		fmt.Sprintf(".jobs[0].%s.lat_ns.mean", property),
@@ -53,8 +75,9 @@ func parseFioLatency(fioOutput []byte, property string) (float64, error) {
 	return actualNumber / 1_000_000_000, nil
 }
 
-func parseFioError(fioOutput []byte) (int, error) {
+func parseFioError(ctx context.Context, fioOutput []byte) (int, error) {
 	jqOut, err := execWrap(
+		ctx,
 		fioOutput,
 		"jq",
 		".jobs[0].error",
@@ -73,12 +96,20 @@ func parseFioError(fioOutput []byte) (int, error) {
 	return int(actualNumber), nil
 }
 
+// GetMetrics benchmarks the target with O_DIRECT,
+// or with buffered I/O and fdatasync after every write if the target rejects O_DIRECT.
 func (mtr *diskMetricsImpl) GetMetrics(ctx context.Context) (*DiskMetrics, error) {
-	fioStdout, err := execWrap(
-		nil,
-		"fio",
-		fmt.Sprintf("-filename=%s/.iotest", mtr.path),
-		"-direct=1",
+	ioMode := detectIOMode(mtr.filename, mtr.block)
+	args := []string{
+		fmt.Sprintf("-filename=%s", mtr.filename),
+		fmt.Sprintf("-offset=%d", mtr.offset),
+	}
+	if ioMode == types.IOModeDirect {
+		args = append(args, "-direct=1")
+	} else {
+		args = append(args, "-direct=0", "-fdatasync=1")
+	}
+	args = append(args,
 		"-rw=readwrite",
 		"-bs=4k",
 		"-size=50M",
@@ -88,22 +119,28 @@ func (mtr *diskMetricsImpl) GetMetrics(ctx context.Context) (*DiskMetrics, error
 		"-name=run1",
 		"--output-format=json",
 	)
+
+	fioStdout, err := execWrap(ctx, nil, "fio", args...)
 	if err != nil {
+		// fio reports the errno of the failed job in its output.
+		if errorNumber, parseErr := parseFioError(ctx, fioStdout); parseErr == nil && errorNumber != 0 {
+			return nil, fmt.Errorf("fio failed: %w: %w", syscall.Errno(errorNumber), err)
+		}
 		return nil, err
 	}
 
-	var metrics DiskMetrics
-	metrics.ReadLatency, err = parseFioLatency(fioStdout, "read")
+	metrics := DiskMetrics{IOMode: ioMode}
+	metrics.ReadLatency, err = parseFioLatency(ctx, fioStdout, "read")
 	if err != nil {
 		return nil, err
 	}
 
-	metrics.WriteLatency, err = parseFioLatency(fioStdout, "write")
+	metrics.WriteLatency, err = parseFioLatency(ctx, fioStdout, "write")
 	if err != nil {
 		return nil, err
 	}
 
-	metrics.ErrorNumber, err = parseFioError(fioStdout)
+	metrics.ErrorNumber, err = parseFioError(ctx, fioStdout)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,73 @@
+package probe
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"time"
+)
+
+// filesystemPollInterval is the interval to check the size of the filesystem being expanded.
+const filesystemPollInterval = time.Second
+
+func getFilesystemSize(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, fmt.Errorf("failed to statfs %s: %w", path, err)
+	}
+	return stat.Blocks * uint64(stat.Bsize), nil
+}
+
+func getDeviceSize(devicePath string) (uint64, error) {
+	f, err := os.Open(devicePath)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the size of %s: %w", devicePath, err)
+	}
+	return uint64(size), nil
+}
+
+// WaitForFilesystemExpansion waits until the filesystem of the path grows larger than its size at the start.
+// It does not time out by itself, so the caller must limit the time.
+// The readyFile is created, if not empty, once the size at the start is recorded.
+func WaitForFilesystemExpansion(path, readyFile string) error {
+	return waitForExpansion(func() (uint64, error) { return getFilesystemSize(path) }, readyFile)
+}
+
+// WaitForDeviceExpansion waits until the raw block device grows larger than its size at the start.
+// It does not time out by itself, so the caller must limit the time.
+// The readyFile is created, if not empty, once the size at the start is recorded.
+func WaitForDeviceExpansion(devicePath, readyFile string) error {
+	return waitForExpansion(func() (uint64, error) { return getDeviceSize(devicePath) }, readyFile)
+}
+
+// waitForExpansion compares the sizes with the initial size instead of the requested capacity,
+// because the provisioners may round the capacity up.
+// The volume must not be expanded before the readyFile is created,
+// or the expanded size would be recorded as the initial size and the expansion would never be seen.
+func waitForExpansion(getSize func() (uint64, error), readyFile string) error {
+	initialSize, err := getSize()
+	if err != nil {
+		return err
+	}
+	if readyFile != "" {
+		if err := os.WriteFile(readyFile, nil, 0600); err != nil {
+			return fmt.Errorf("failed to create %s: %w", readyFile, err)
+		}
+	}
+	for {
+		time.Sleep(filesystemPollInterval)
+		size, err := getSize()
+		if err != nil {
+			return err
+		}
+		if size > initialSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package probe
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWaitForExpansion(t *testing.T) {
+	errStat := errors.New("stat failed")
+	testCases := []struct {
+		name    string
+		sizes   []uint64
+		errs    []error
+		wantErr error
+	}{
+		{
+			name: "grows beyond the initial size rounded up by the provisioner",
+			// The capacity of 100Mi is rounded up to 150Mi and expanded to 250Mi.
+			sizes: []uint64{150 << 20, 250 << 20},
+		},
+		{
+			name:  "waits while the size does not change",
+			sizes: []uint64{150 << 20, 150 << 20, 250 << 20},
+		},
+		{
+			name:    "fails to get the initial size",
+			sizes:   []uint64{0},
+			errs:    []error{errStat},
+			wantErr: errStat,
+		},
+		{
+			name:    "fails to get the size while waiting",
+			sizes:   []uint64{150 << 20, 0},
+			errs:    []error{nil, errStat},
+			wantErr: errStat,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			getSize := func() (uint64, error) {
+				i := calls
+				calls++
+				if i >= len(tc.sizes) {
+					t.Fatalf("the size is got more than %d times", len(tc.sizes))
+				}
+				if i < len(tc.errs) && tc.errs[i] != nil {
+					return 0, tc.errs[i]
+				}
+				return tc.sizes[i], nil
+			}
+
+			err := waitForExpansion(getSize, "")
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unexpected error: got %v, want %v", err, tc.wantErr)
+			}
+			if calls != len(tc.sizes) {
+				t.Errorf("the size is got %d times, want %d", calls, len(tc.sizes))
+			}
+		})
+	}
+}
+
+func TestWaitForExpansionReadyFile(t *testing.T) {
+	readyFile := filepath.Join(t.TempDir(), "ready")
+	isReady := func() bool {
+		_, err := os.Stat(readyFile)
+		return err == nil
+	}
+
+	// The volume is expanded as soon as the probe becomes ready, as with the fast online expansion.
+	calls := 0
+	getSize := func() (uint64, error) {
+		calls++
+		if calls == 1 {
+			if isReady() {
+				t.Error("the probe is ready before the initial size is recorded")
+			}
+			return 150 << 20, nil
+		}
+		if !isReady() {
+			t.Error("the probe is not ready after the initial size is recorded")
+		}
+		return 250 << 20, nil
+	}
+
+	err := waitForExpansion(getSize, readyFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("the size is got %d times, want 2", calls)
+	}
+}
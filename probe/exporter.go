@@ -2,60 +2,259 @@ package probe
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"math/rand/v2"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/topolvm/pie/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
 )
 
 type diskInfoImpl struct {
-	url          string
-	pieProbeName string
-	node         string
-	storageClass string
+	url        string
+	identity   types.ProbeIdentity
+	volumeMode string
+	// spool is nil if the results are not spooled.
+	spool *resultSpool
+	// grpcAddress is the gRPC receiver the results are streamed to instead of posting them if it is not empty.
+	grpcAddress string
+	// terminationMessagePath is the file the results are written to instead of posting them if it is not empty.
+	terminationMessagePath string
 }
 
+// errRejected is returned if the controller rejects the result, e.g. for its unsupported schema version.
+// The rejected results are neither retried nor spooled.
+var errRejected = errors.New("the result is rejected by the controller")
+
 const (
-	maxRetryCount    = 3
-	retryIntervalSec = 3
+	maxRetryCount        = 5
+	initialRetryInterval = time.Second
+	maxRetryInterval     = 10 * time.Second
+
+	// maxErrorResponseSize is the size of the error responses of the controller logged at most.
+	maxErrorResponseSize = 1024
+
+	// maxTerminationMessageSize is the size limit of the termination messages imposed by kubelet.
+	maxTerminationMessageSize = 4096
 )
 
-func NewDiskInfoExporter(url string, pieProbeName string, node string, storageClass string) DiskInfoExporter {
+// NewDiskInfoExporter returns the DiskInfoExporter posting the results to the url.
+// The results not delivered are spooled under spoolPath and replayed on the next runs if it is not empty.
+func NewDiskInfoExporter(
+	url string,
+	identity types.ProbeIdentity,
+	volumeMode string,
+	spoolPath string,
+) DiskInfoExporter {
+	di := &diskInfoImpl{
+		url:        url,
+		identity:   identity,
+		volumeMode: volumeMode,
+	}
+	if spoolPath != "" {
+		di.spool = newResultSpool(spoolPath)
+	}
+	return di
+}
+
+// NewGRPCDiskInfoExporter returns the DiskInfoExporter streaming the results to the gRPC receiver at the address.
+// The results not delivered are spooled under spoolPath and replayed on the next runs if it is not empty.
+func NewGRPCDiskInfoExporter(
+	address string,
+	identity types.ProbeIdentity,
+	volumeMode string,
+	spoolPath string,
+) DiskInfoExporter {
+	di := &diskInfoImpl{
+		grpcAddress: address,
+		identity:    identity,
+		volumeMode:  volumeMode,
+	}
+	if spoolPath != "" {
+		di.spool = newResultSpool(spoolPath)
+	}
+	return di
+}
+
+// NewTerminationMessageExporter returns the DiskInfoExporter writing the results to the termination message path,
+// from which the controller reads them after the container is terminated.
+func NewTerminationMessageExporter(
+	terminationMessagePath string,
+	identity types.ProbeIdentity,
+	volumeMode string,
+) DiskInfoExporter {
 	return &diskInfoImpl{
-		url:          url,
-		pieProbeName: pieProbeName,
-		node:         node,
-		storageClass: storageClass,
+		identity:               identity,
+		volumeMode:             volumeMode,
+		terminationMessagePath: terminationMessagePath,
 	}
 }
 
-func (di *diskInfoImpl) Export(metrics *DiskMetrics) error {
-	m := types.MetricsExchangeFormat{
-		PieProbeName:            di.pieProbeName,
-		Node:                    di.node,
-		StorageClass:            di.storageClass,
-		WriteLatency:            metrics.WriteLatency,
-		ReadLatency:             metrics.ReadLatency,
-		PerformanceProbeSucceed: metrics.ErrorNumber == 0,
+func (di *diskInfoImpl) Export(
+	ctx context.Context,
+	startedAt time.Time,
+	metrics *DiskMetrics,
+	mount *types.MountInfo,
+	usage *types.FilesystemUsage,
+) error {
+	m := types.ResultEnvelope{
+		SchemaVersion: types.ResultSchemaVersion,
+		RunID:         string(uuid.NewUUID()),
+		Kind:          types.ProbeKindMount,
+		Identity:      di.identity,
+		StartedAt:     startedAt,
+		FinishedAt:    time.Now(),
+		Measurements: []types.Measurement{
+			{
+				Type: types.MeasurementTypePerformance,
+				Performance: &types.PerformanceMeasurement{
+					VolumeMode:    di.volumeMode,
+					IOMode:        metrics.IOMode,
+					Succeeded:     metrics.ErrorNumber == 0 && metrics.FailureReason == "",
+					FailureReason: metrics.FailureReason,
+					ReadLatency:   metrics.ReadLatency,
+					WriteLatency:  metrics.WriteLatency,
+				},
+			},
+		},
+	}
+	if mount != nil {
+		m.Measurements = append(m.Measurements, types.Measurement{
+			Type:  types.MeasurementTypeMount,
+			Mount: mount,
+		})
+	}
+	if usage != nil {
+		m.Measurements = append(m.Measurements, types.Measurement{
+			Type:            types.MeasurementTypeFilesystemUsage,
+			FilesystemUsage: usage,
+		})
+	}
+	for _, operation := range metrics.MetadataOperations {
+		m.Measurements = append(m.Measurements, types.Measurement{
+			Type:              types.MeasurementTypeMetadataOperation,
+			MetadataOperation: &operation,
+		})
+	}
+
+	if di.terminationMessagePath != "" {
+		s, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		return writeTerminationMessage(di.terminationMessagePath, s)
 	}
 
-	s, err := json.Marshal(m)
-	if err != nil {
-		return err
+	send := func(ctx context.Context, result *types.ResultEnvelope) error {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		return postWithRetry(ctx, di.url, data)
+	}
+	if di.grpcAddress != "" {
+		client, err := newGRPCResultClient(di.grpcAddress)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := client.close(); err != nil {
+				log.Printf("failed to close the connection to the gRPC receiver: %v", err)
+			}
+		}()
+		send = client.send
+	}
+
+	if di.spool == nil {
+		return send(ctx, &m)
 	}
 
-	var resp *http.Response
-	for retryCounter := 0; retryCounter < maxRetryCount; retryCounter++ {
-		resp, err = http.Post(di.url, "application/json", bytes.NewReader(s))
+	// The spooled results are sent first for the gauges to end up with the latest values.
+	err := di.spool.replay(ctx, send)
+	if err == nil {
+		err = send(ctx, &m)
+	}
+	// The results rejected by the controller are never accepted on the next runs.
+	if err != nil && !errors.Is(err, errRejected) {
+		log.Printf("spooling the result not delivered: %v", err)
+		return di.spool.put(&m)
+	}
+	return err
+}
+
+// writeTerminationMessage writes the data to the termination message path.
+// kubelet truncates the larger messages, which the controller cannot decode.
+func writeTerminationMessage(path string, data []byte) error {
+	if len(data) > maxTerminationMessageSize {
+		return fmt.Errorf("the result of %d bytes exceeds the termination message limit of %d bytes",
+			len(data), maxTerminationMessageSize)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// postWithRetry posts the data to the url until it succeeds, it fails maxRetryCount times or the context is done.
+func postWithRetry(ctx context.Context, url string, data []byte) error {
+	return retry(ctx, func() error {
+		return post(ctx, url, data)
+	})
+}
+
+// retry calls f until it succeeds, it fails maxRetryCount times or the context is done.
+// The rejections by the controller are not retried.
+func retry(ctx context.Context, f func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := f()
 		if err == nil {
-			_ = resp.Body.Close()
 			return nil
 		}
-		log.Printf("failed to post data: %v", err)
-		time.Sleep(time.Second * retryIntervalSec)
+		log.Printf("failed to send data: %v", err)
+		if attempt+1 >= maxRetryCount || errors.Is(err, errRejected) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-time.After(getRetryInterval(attempt)):
+		}
 	}
+}
 
-	return err
+func post(ctx context.Context, url string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 300 {
+		return resp.Body.Close()
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorResponseSize))
+	_ = resp.Body.Close()
+	if resp.StatusCode < 500 {
+		return fmt.Errorf("%w: %s: %s", errRejected, resp.Status, bytes.TrimSpace(body))
+	}
+	return fmt.Errorf("the controller responded %s: %s", resp.Status, bytes.TrimSpace(body))
+}
+
+// getRetryInterval returns the interval before the next attempt, which grows exponentially up to maxRetryInterval.
+// It is jittered between the half and the whole not to retry at the same time as the other probes.
+func getRetryInterval(attempt int) time.Duration {
+	interval := maxRetryInterval
+	if attempt < 16 {
+		interval = min(initialRetryInterval<<attempt, maxRetryInterval)
+	}
+	return interval/2 + rand.N(interval/2+1)
 }
@@ -0,0 +1,200 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/topolvm/pie/types"
+)
+
+func TestGetRetryInterval(t *testing.T) {
+	testCases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: time.Second},
+		{attempt: 1, want: 2 * time.Second},
+		{attempt: 2, want: 4 * time.Second},
+		{attempt: 3, want: 8 * time.Second},
+		{attempt: 4, want: maxRetryInterval},
+		{attempt: 15, want: maxRetryInterval},
+		// The shift overflows without the cap.
+		{attempt: 64, want: maxRetryInterval},
+	}
+	for _, tc := range testCases {
+		for i := 0; i < 100; i++ {
+			got := getRetryInterval(tc.attempt)
+			if got < tc.want/2 || got > tc.want {
+				t.Fatalf("getRetryInterval(%d) = %v, want between %v and %v", tc.attempt, got, tc.want/2, tc.want)
+			}
+		}
+	}
+}
+
+func TestPostWithRetry(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// statusAbort makes the server abort the connection without responding.
+	const statusAbort = 0
+	testCases := []struct {
+		name      string
+		ctx       context.Context
+		statuses  []int
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "succeeds at once",
+			ctx:       context.Background(),
+			statuses:  []int{http.StatusOK},
+			wantCalls: 1,
+		},
+		{
+			name:      "succeeds after a failure",
+			ctx:       context.Background(),
+			statuses:  []int{statusAbort, http.StatusOK},
+			wantCalls: 2,
+		},
+		{
+			name:      "does not retry the rejection",
+			ctx:       context.Background(),
+			statuses:  []int{http.StatusBadRequest},
+			wantErr:   errRejected,
+			wantCalls: 1,
+		},
+		{
+			name:      "stops when the context is done",
+			ctx:       canceled,
+			wantErr:   context.Canceled,
+			wantCalls: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tc.statuses[calls]
+				calls++
+				if status == statusAbort {
+					panic(http.ErrAbortHandler)
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			err := postWithRetry(tc.ctx, server.URL, []byte("{}"))
+			if !errors.Is(err, tc.wantErr) || (tc.wantErr == nil && err != nil) {
+				t.Errorf("postWithRetry() = %v, want %v", err, tc.wantErr)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("postWithRetry() posted %d times, want %d", calls, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestWriteTerminationMessage(t *testing.T) {
+	testCases := []struct {
+		name    string
+		size    int
+		wantErr bool
+	}{
+		{name: "writes the small message", size: 100},
+		{name: "writes the message of the limit", size: maxTerminationMessageSize},
+		{name: "refuses the message which kubelet truncates", size: maxTerminationMessageSize + 1, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "termination-log")
+			data := []byte(strings.Repeat("x", tc.size))
+			err := writeTerminationMessage(path, data)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("writeTerminationMessage() succeeded, want an error")
+				}
+				if _, err := os.Stat(path); !os.IsNotExist(err) {
+					t.Errorf("the truncated message is written: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("writeTerminationMessage() failed: %v", err)
+			}
+			written, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(written) != tc.size {
+				t.Errorf("wrote %d bytes, want %d", len(written), tc.size)
+			}
+		})
+	}
+}
+
+// TestTerminationMessageExporter checks the result with all the measurements fits in the termination message.
+func TestTerminationMessageExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "termination-log")
+	identity := types.ProbeIdentity{
+		PieProbeName: strings.Repeat("p", 63),
+		Node:         strings.Repeat("n", 253),
+		StorageClass: strings.Repeat("s", 253),
+		Namespace:    strings.Repeat("m", 63),
+		PodName:      strings.Repeat("o", 253),
+		PVCName:      strings.Repeat("c", 253),
+		PVName:       "pvc-00000000-0000-0000-0000-000000000000",
+	}
+	metrics := &DiskMetrics{
+		ReadLatency:  0.001234567890123,
+		WriteLatency: 0.001234567890123,
+		IOMode:       types.IOModeDirect,
+	}
+	for _, operation := range []string{
+		types.MetadataOperationCreate,
+		types.MetadataOperationFsync,
+		types.MetadataOperationDirFsync,
+		types.MetadataOperationRename,
+		types.MetadataOperationStat,
+		types.MetadataOperationUnlink,
+	} {
+		metrics.MetadataOperations = append(metrics.MetadataOperations, types.MetadataOperationResult{
+			Operation: operation,
+			Latency:   0.001234567890123,
+			Errors:    10000,
+		})
+	}
+	mount := &types.MountInfo{
+		FilesystemType: "ext4",
+		MountOptions:   strings.Split("rw,relatime,nosuid,nodev,noexec,data=ordered,errors=remount-ro,stripe=16", ","),
+	}
+	usage := &types.FilesystemUsage{
+		TotalBytes:  1 << 50,
+		FreeBytes:   1 << 50,
+		TotalInodes: 1 << 40,
+		FreeInodes:  1 << 40,
+	}
+
+	exporter := NewTerminationMessageExporter(path, identity, "Filesystem")
+	if err := exporter.Export(context.Background(), time.Now(), metrics, mount, usage); err != nil {
+		t.Fatalf("Export() failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result types.ResultEnvelope
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("failed to decode the termination message of %d bytes: %v", len(data), err)
+	}
+	if len(result.Measurements) != 9 {
+		t.Errorf("%d measurements are written, want 9", len(result.Measurements))
+	}
+}
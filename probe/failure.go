@@ -0,0 +1,32 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+
+	"github.com/topolvm/pie/types"
+)
+
+// classifyFailure returns the class of the error failing the probe, which is exported to the controller.
+// The errors of fio are classified by the errno reported in its output.
+func classifyFailure(err error) string {
+	switch {
+	case errors.Is(err, syscall.EROFS):
+		return types.FailureReasonReadOnly
+	case errors.Is(err, syscall.ENOSPC):
+		return types.FailureReasonNoSpace
+	case errors.Is(err, syscall.EIO):
+		return types.FailureReasonIOError
+	case errors.Is(err, syscall.EDQUOT):
+		return types.FailureReasonQuotaExceeded
+	// The probe is cancelled on SIGTERM, e.g. when its Pod is deleted while it hangs.
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled),
+		errors.Is(err, os.ErrDeadlineExceeded), errors.Is(err, syscall.ETIMEDOUT):
+		return types.FailureReasonTimeout
+	case errors.Is(err, syscall.EACCES), errors.Is(err, syscall.EPERM):
+		return types.FailureReasonPermissionDenied
+	}
+	return types.FailureReasonUnknown
+}
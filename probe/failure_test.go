@@ -0,0 +1,89 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/topolvm/pie/types"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "read-only filesystem",
+			err:  &fs.PathError{Op: "open", Path: "/mounted/file", Err: syscall.EROFS},
+			want: types.FailureReasonReadOnly,
+		},
+		{
+			name: "no space reported by fio",
+			err:  fmt.Errorf("fio failed: %w: %w", syscall.ENOSPC, errors.New("exit status 1")),
+			want: types.FailureReasonNoSpace,
+		},
+		{
+			name: "I/O error",
+			err:  syscall.EIO,
+			want: types.FailureReasonIOError,
+		},
+		{
+			name: "quota exceeded",
+			err:  &fs.PathError{Op: "write", Path: "/mounted/file", Err: syscall.EDQUOT},
+			want: types.FailureReasonQuotaExceeded,
+		},
+		{
+			name: "deadline of the probe",
+			err:  fmt.Errorf("fio failed: %w", context.DeadlineExceeded),
+			want: types.FailureReasonTimeout,
+		},
+		{
+			name: "cancellation on SIGTERM",
+			err:  context.Canceled,
+			want: types.FailureReasonTimeout,
+		},
+		{
+			name: "deadline of the file",
+			err:  &fs.PathError{Op: "read", Path: "/mounted/file", Err: os.ErrDeadlineExceeded},
+			want: types.FailureReasonTimeout,
+		},
+		{
+			name: "timed out errno",
+			err:  syscall.ETIMEDOUT,
+			want: types.FailureReasonTimeout,
+		},
+		{
+			name: "permission denied",
+			err:  &fs.PathError{Op: "open", Path: "/mounted/file", Err: syscall.EACCES},
+			want: types.FailureReasonPermissionDenied,
+		},
+		{
+			name: "operation not permitted",
+			err:  syscall.EPERM,
+			want: types.FailureReasonPermissionDenied,
+		},
+		{
+			name: "unknown errno",
+			err:  syscall.EINVAL,
+			want: types.FailureReasonUnknown,
+		},
+		{
+			name: "error without errno",
+			err:  errors.New("fio failed"),
+			want: types.FailureReasonUnknown,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := classifyFailure(tc.err); got != tc.want {
+				t.Errorf("classifyFailure(%v) = %q, want %q", tc.err, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	resultv1 "github.com/topolvm/pie/api/result/v1"
+	"github.com/topolvm/pie/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// grpcStreamTimeout is the deadline of a stream to the gRPC receiver, within which the results are acknowledged.
+const grpcStreamTimeout = 30 * time.Second
+
+// grpcResultClient streams the results to the gRPC receiver of the controller.
+// The stream is opened on the first result and reopened after it fails.
+type grpcResultClient struct {
+	conn   *grpc.ClientConn
+	client resultv1.ResultServiceClient
+	stream grpc.BidiStreamingClient[resultv1.ReportResultsRequest, resultv1.ReportResultsResponse]
+	cancel context.CancelFunc
+}
+
+func newGRPCResultClient(address string) (*grpcResultClient, error) {
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	return &grpcResultClient{
+		conn:   conn,
+		client: resultv1.NewResultServiceClient(conn),
+	}, nil
+}
+
+// send sends the result and waits for its acknowledgement.
+func (c *grpcResultClient) send(ctx context.Context, result *types.ResultEnvelope) error {
+	req := &resultv1.ReportResultsRequest{
+		Result: resultv1.NewResult(result),
+	}
+	return retry(ctx, func() error {
+		return c.sendOnce(ctx, req)
+	})
+}
+
+func (c *grpcResultClient) sendOnce(ctx context.Context, req *resultv1.ReportResultsRequest) error {
+	if c.stream == nil {
+		streamCtx, cancel := context.WithTimeout(ctx, grpcStreamTimeout)
+		stream, err := c.client.ReportResults(streamCtx)
+		if err != nil {
+			cancel()
+			return err
+		}
+		c.stream = stream
+		c.cancel = cancel
+	}
+
+	err := c.stream.Send(req)
+	if errors.Is(err, io.EOF) {
+		// The error of the stream is returned by Recv.
+		_, err = c.stream.Recv()
+	}
+	var resp *resultv1.ReportResultsResponse
+	if err == nil {
+		resp, err = c.stream.Recv()
+	}
+	if err != nil {
+		c.closeStream()
+		return err
+	}
+
+	if resp.GetStatus() == resultv1.ReportResultsResponse_STATUS_REJECTED {
+		return fmt.Errorf("%w: %s", errRejected, resp.GetMessage())
+	}
+	return nil
+}
+
+// closeStream closes the stream and waits for the receiver to finish it.
+func (c *grpcResultClient) closeStream() {
+	if c.stream == nil {
+		return
+	}
+	if err := c.stream.CloseSend(); err == nil {
+		for {
+			if _, err := c.stream.Recv(); err != nil {
+				break
+			}
+		}
+	}
+	c.cancel()
+	c.stream = nil
+	c.cancel = nil
+}
+
+func (c *grpcResultClient) close() error {
+	c.closeStream()
+	return c.conn.Close()
+}
@@ -0,0 +1,91 @@
+package probe
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// knownFileName is the file written by the mount probes to verify the data of the volumes copied from them.
+const knownFileName = ".pie-known"
+
+// knownBlockSize is the size of the region at the head of a raw block device where the known data is written.
+// The I/O benchmark of the device does not touch the region.
+const knownBlockSize = 4096
+
+func knownFileContent(pieProbeName, node, storageClass string) []byte {
+	return fmt.Appendf(nil, "pie-probe=%s\nnode=%s\nstorage-class=%s\n", pieProbeName, node, storageClass)
+}
+
+// WriteKnownFile writes the known file to the directory and syncs it.
+func WriteKnownFile(path, pieProbeName, node, storageClass string) error {
+	f, err := os.Create(filepath.Join(path, knownFileName))
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(knownFileContent(pieProbeName, node, storageClass))
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	err = f.Sync()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteKnownBlock writes the known data at the head of the raw block device and syncs it.
+func WriteKnownBlock(devicePath, pieProbeName, node, storageClass string) error {
+	f, err := os.OpenFile(devicePath, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	block := make([]byte, knownBlockSize)
+	copy(block, knownFileContent(pieProbeName, node, storageClass))
+	_, err = f.WriteAt(block, 0)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	err = f.Sync()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// VerifyKnownBlock checks the head of the raw block device has the expected known data.
+func VerifyKnownBlock(devicePath, pieProbeName, node, storageClass string) error {
+	f, err := os.Open(devicePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	expected := knownFileContent(pieProbeName, node, storageClass)
+	content := make([]byte, len(expected))
+	_, err = f.ReadAt(content, 0)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(content, expected) {
+		return fmt.Errorf("unexpected known data of the device: %q", content)
+	}
+	return nil
+}
+
+// VerifyKnownFile checks the known file in the directory has the expected content.
+func VerifyKnownFile(path, pieProbeName, node, storageClass string) error {
+	content, err := os.ReadFile(filepath.Join(path, knownFileName))
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(content, knownFileContent(pieProbeName, node, storageClass)) {
+		return fmt.Errorf("unexpected content of the known file: %q", content)
+	}
+	return nil
+}
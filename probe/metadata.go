@@ -0,0 +1,129 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/topolvm/pie/types"
+)
+
+// metadataTestDir is the scratch directory of the metadata benchmark on the volume.
+const metadataTestDir = ".metadatatest"
+
+// metadataOperationStats accumulates the results of an operation of the metadata benchmark.
+type metadataOperationStats struct {
+	total     time.Duration
+	succeeded int
+	errors    int
+}
+
+func (s *metadataOperationStats) measure(op func() error) error {
+	start := time.Now()
+	err := op()
+	if err != nil {
+		s.errors++
+		return err
+	}
+	s.total += time.Since(start)
+	s.succeeded++
+	return nil
+}
+
+// RunMetadataBenchmark creates, fsyncs, renames, stats and unlinks the files in a scratch directory under the path,
+// and fsyncs the directory after each creation. It returns the mean latency and the errors of each operation.
+// The operations on a file are skipped after one of them fails.
+func RunMetadataBenchmark(ctx context.Context, path string, count int) ([]types.MetadataOperationResult, error) {
+	dir := filepath.Join(path, metadataTestDir)
+	// Remove the leftovers of the interrupted runs.
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("failed to remove %s: %w", dir, err)
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	operations := []string{
+		types.MetadataOperationCreate,
+		types.MetadataOperationFsync,
+		types.MetadataOperationDirFsync,
+		types.MetadataOperationRename,
+		types.MetadataOperationStat,
+		types.MetadataOperationUnlink,
+	}
+	stats := map[string]*metadataOperationStats{}
+	for _, operation := range operations {
+		stats[operation] = &metadataOperationStats{}
+	}
+
+	for i := 0; i < count; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		runMetadataOperations(dir, i, stats)
+	}
+
+	results := make([]types.MetadataOperationResult, 0, len(operations))
+	for _, operation := range operations {
+		s := stats[operation]
+		result := types.MetadataOperationResult{
+			Operation: operation,
+			Errors:    s.errors,
+		}
+		if s.succeeded != 0 {
+			result.Latency = s.total.Seconds() / float64(s.succeeded)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func runMetadataOperations(dir string, i int, stats map[string]*metadataOperationStats) {
+	name := filepath.Join(dir, fmt.Sprintf("file-%d", i))
+	renamed := filepath.Join(dir, fmt.Sprintf("renamed-%d", i))
+
+	var f *os.File
+	err := stats[types.MetadataOperationCreate].measure(func() error {
+		var err error
+		f, err = os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		return err
+	})
+	if err != nil {
+		return
+	}
+	err = stats[types.MetadataOperationFsync].measure(f.Sync)
+	_ = f.Close()
+	if err != nil {
+		return
+	}
+	err = stats[types.MetadataOperationDirFsync].measure(func() error {
+		d, err := os.Open(dir)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = d.Close() }()
+		return d.Sync()
+	})
+	if err != nil {
+		return
+	}
+	err = stats[types.MetadataOperationRename].measure(func() error {
+		return os.Rename(name, renamed)
+	})
+	if err != nil {
+		return
+	}
+	err = stats[types.MetadataOperationStat].measure(func() error {
+		_, err := os.Stat(renamed)
+		return err
+	})
+	if err != nil {
+		return
+	}
+	_ = stats[types.MetadataOperationUnlink].measure(func() error {
+		return os.Remove(renamed)
+	})
+}
@@ -0,0 +1,95 @@
+package probe
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/topolvm/pie/types"
+)
+
+const mountInfoPath = "/proc/self/mountinfo"
+
+// GetMountInfo returns the mount of the filesystem containing the path.
+func GetMountInfo(path string) (*types.MountInfo, error) {
+	f, err := os.Open(mountInfoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	path = filepath.Clean(path)
+	var mountInfo *types.MountInfo
+	mountPointLength := -1
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		mountPoint, info, err := parseMountInfoLine(scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		if !isUnderMountPoint(path, mountPoint) {
+			continue
+		}
+		// The later mounts on the same mount point hide the earlier ones.
+		if len(mountPoint) >= mountPointLength {
+			mountInfo = info
+			mountPointLength = len(mountPoint)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", mountInfoPath, err)
+	}
+	if mountInfo == nil {
+		return nil, fmt.Errorf("no mount is found for %s", path)
+	}
+	return mountInfo, nil
+}
+
+// parseMountInfoLine parses a line of /proc/self/mountinfo, which looks like:
+//
+//	36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+//
+// It returns the mount point, i.e. the fifth field, and the mount.
+func parseMountInfoLine(line string) (string, *types.MountInfo, error) {
+	fields := strings.Fields(line)
+	separator := slices.Index(fields, "-")
+	if separator < 6 || len(fields) < separator+4 {
+		return "", nil, fmt.Errorf("malformed line in %s: %s", mountInfoPath, line)
+	}
+
+	mountOptions := strings.Split(fields[5], ",")
+	for _, option := range strings.Split(fields[separator+3], ",") {
+		if !slices.Contains(mountOptions, option) {
+			mountOptions = append(mountOptions, option)
+		}
+	}
+	return unescapeMountInfoField(fields[4]), &types.MountInfo{
+		FilesystemType: fields[separator+1],
+		MountOptions:   mountOptions,
+		ReadOnly:       slices.Contains(mountOptions, "ro"),
+	}, nil
+}
+
+// unescapeMountInfoField decodes the octal escapes, e.g. `\040` for a space, in a field of /proc/self/mountinfo.
+func unescapeMountInfoField(field string) string {
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+3 < len(field) {
+			if c, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(field[i])
+	}
+	return b.String()
+}
+
+func isUnderMountPoint(path, mountPoint string) bool {
+	return mountPoint == "/" || path == mountPoint || strings.HasPrefix(path, mountPoint+"/")
+}
@@ -0,0 +1,141 @@
+package probe
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/topolvm/pie/types"
+)
+
+func TestParseMountInfoLine(t *testing.T) {
+	testCases := []struct {
+		name           string
+		line           string
+		wantMountPoint string
+		wantMountInfo  *types.MountInfo
+		wantErr        bool
+	}{
+		{
+			name:           "merges the super options into the mount options",
+			line:           "36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue",
+			wantMountPoint: "/mnt2",
+			wantMountInfo: &types.MountInfo{
+				FilesystemType: "ext3",
+				MountOptions:   []string{"rw", "noatime", "errors=continue"},
+			},
+		},
+		{
+			name:           "accepts no optional fields",
+			line:           "25 1 259:1 / /var/lib/kubelet rw,relatime - xfs /dev/nvme0n1p1 rw,attr2,inode64",
+			wantMountPoint: "/var/lib/kubelet",
+			wantMountInfo: &types.MountInfo{
+				FilesystemType: "xfs",
+				MountOptions:   []string{"rw", "relatime", "attr2", "inode64"},
+			},
+		},
+		{
+			name: "skips multiple optional fields",
+			line: "100 25 253:3 / /mounted ro,nosuid shared:7 master:2 propagate_from:1 - ext4 /dev/dm-3 " +
+				"ro,data=ordered",
+			wantMountPoint: "/mounted",
+			wantMountInfo: &types.MountInfo{
+				FilesystemType: "ext4",
+				MountOptions:   []string{"ro", "nosuid", "data=ordered"},
+				ReadOnly:       true,
+			},
+		},
+		{
+			name:           "reports read-only in the super options",
+			line:           "36 35 98:0 / /mnt rw - ext4 /dev/sda1 ro",
+			wantMountPoint: "/mnt",
+			wantMountInfo: &types.MountInfo{
+				FilesystemType: "ext4",
+				MountOptions:   []string{"rw", "ro"},
+				ReadOnly:       true,
+			},
+		},
+		{
+			name:           "decodes the octal escapes in the mount point",
+			line:           `36 35 98:0 / /mnt/with\040space\011tab rw - ext4 /dev/sda1 rw`,
+			wantMountPoint: "/mnt/with space\ttab",
+			wantMountInfo: &types.MountInfo{
+				FilesystemType: "ext4",
+				MountOptions:   []string{"rw"},
+			},
+		},
+		{
+			name:    "fails without the separator",
+			line:    "36 35 98:0 / /mnt rw ext4 /dev/sda1 rw",
+			wantErr: true,
+		},
+		{
+			name:    "fails without the super options",
+			line:    "36 35 98:0 / /mnt rw - ext4 /dev/sda1",
+			wantErr: true,
+		},
+		{
+			name:    "fails with too few fields before the separator",
+			line:    "36 35 98:0 / - ext4 /dev/sda1 rw",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mountPoint, mountInfo, err := parseMountInfoLine(tc.line)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("parseMountInfoLine() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMountInfoLine() failed: %v", err)
+			}
+			if mountPoint != tc.wantMountPoint {
+				t.Errorf("mount point = %q, want %q", mountPoint, tc.wantMountPoint)
+			}
+			if !reflect.DeepEqual(mountInfo, tc.wantMountInfo) {
+				t.Errorf("mount info = %+v, want %+v", mountInfo, tc.wantMountInfo)
+			}
+		})
+	}
+}
+
+func TestUnescapeMountInfoField(t *testing.T) {
+	testCases := []struct {
+		field string
+		want  string
+	}{
+		{field: "/mnt", want: "/mnt"},
+		{field: `/a\040b`, want: "/a b"},
+		{field: `/a\134b`, want: `/a\b`},
+		{field: `\040`, want: " "},
+		// The escapes not followed by three octal digits are kept as they are.
+		{field: `/a\04`, want: `/a\04`},
+		{field: `/a\089`, want: `/a\089`},
+	}
+	for _, tc := range testCases {
+		if got := unescapeMountInfoField(tc.field); got != tc.want {
+			t.Errorf("unescapeMountInfoField(%q) = %q, want %q", tc.field, got, tc.want)
+		}
+	}
+}
+
+func TestIsUnderMountPoint(t *testing.T) {
+	testCases := []struct {
+		path       string
+		mountPoint string
+		want       bool
+	}{
+		{path: "/mounted/file", mountPoint: "/", want: true},
+		{path: "/mounted", mountPoint: "/mounted", want: true},
+		{path: "/mounted/file", mountPoint: "/mounted", want: true},
+		{path: "/mounted2/file", mountPoint: "/mounted", want: false},
+		{path: "/", mountPoint: "/mounted", want: false},
+	}
+	for _, tc := range testCases {
+		if got := isUnderMountPoint(tc.path, tc.mountPoint); got != tc.want {
+			t.Errorf("isUnderMountPoint(%q, %q) = %v, want %v", tc.path, tc.mountPoint, got, tc.want)
+		}
+	}
+}
@@ -2,22 +2,132 @@ package probe
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"syscall"
+	"time"
+
+	"github.com/topolvm/pie/types"
+	corev1 "k8s.io/api/core/v1"
 )
 
-func SubMain(pieProbeName string, node string, measurePath string, storageClass string, serverURI string) error {
-	context := context.Background()
-	diskMetrics := NewDiskMetrics(measurePath)
-	infoExporter := NewDiskInfoExporter(serverURI, pieProbeName, node, storageClass)
+// terminationExportTimeout is the time limit to export the result after the probe is terminated.
+// It is shorter than the terminationGracePeriodSeconds of the probe Pods.
+const terminationExportTimeout = 4 * time.Second
 
-	metrics, err := diskMetrics.GetMetrics(context)
-	if err != nil {
-		return err
+// SubMain writes the known data to the volume, benchmarks it and exports the result with the identity of the probe.
+// The volume is the raw block device at devicePath if it is not empty,
+// and the filesystem mounted at measurePath otherwise.
+// The metadata of the filesystem is also benchmarked with the files as many as metadataOperations if it is not 0.
+// The probe fails as a timeout if it doesn't finish within the timeout or the context is cancelled,
+// and the result is exported even then.
+// If spool is true, the result not delivered to the controller is spooled on the filesystem and replayed later.
+// The result is streamed to the gRPC receiver at grpcAddress instead of posting it to serverURI if it is not empty,
+// and written to terminationMessagePath instead of sending it if it is not empty.
+func SubMain(
+	ctx context.Context,
+	identity types.ProbeIdentity,
+	measurePath string,
+	devicePath string,
+	serverURI string,
+	grpcAddress string,
+	metadataOperations int,
+	timeout time.Duration,
+	spool bool,
+	terminationMessagePath string,
+) error {
+	startedAt := time.Now()
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var diskMetrics DiskMetricsInterface
+	var infoExporter DiskInfoExporter
+	var mount *types.MountInfo
+	var usage *types.FilesystemUsage
+	var err error
+	if devicePath != "" {
+		diskMetrics = NewBlockDiskMetrics(devicePath)
+		infoExporter = newInfoExporter(
+			serverURI, grpcAddress, identity, string(corev1.PersistentVolumeBlock), "", terminationMessagePath)
+		err = WriteKnownBlock(devicePath, identity.PieProbeName, identity.Node, identity.StorageClass)
+	} else {
+		diskMetrics = NewDiskMetrics(measurePath)
+		spoolPath := ""
+		if spool {
+			spoolPath = measurePath
+		}
+		infoExporter = newInfoExporter(
+			serverURI, grpcAddress, identity, string(corev1.PersistentVolumeFilesystem), spoolPath,
+			terminationMessagePath)
+		// The mount and the usage are only reported to the controller. The benchmark goes on without them.
+		mount, err = GetMountInfo(measurePath)
+		if err != nil {
+			log.Printf("failed to get the mount of %s: %v", measurePath, err)
+		}
+		// The usage is taken before writing to tell the failures of full volumes.
+		usage, err = GetFilesystemUsage(measurePath)
+		if err != nil {
+			log.Printf("failed to get the usage of %s: %v", measurePath, err)
+		}
+		if mount != nil && mount.ReadOnly {
+			// The kernel remounts the filesystem read-only when it hits errors.
+			err = fmt.Errorf("%s is mounted read-only: %w", measurePath, syscall.EROFS)
+		} else {
+			err = WriteKnownFile(measurePath, identity.PieProbeName, identity.Node, identity.StorageClass)
+		}
+	}
+
+	var metrics *DiskMetrics
+	if err == nil {
+		metrics, err = diskMetrics.GetMetrics(probeCtx)
+	}
+
+	// The result is exported even after the probe is cancelled, but within the grace period if the Pod is terminated.
+	export := func(metrics *DiskMetrics) error {
+		exportCtx := ctx
+		if ctx.Err() != nil {
+			var cancel context.CancelFunc
+			exportCtx, cancel = context.WithTimeout(context.WithoutCancel(ctx), terminationExportTimeout)
+			defer cancel()
+		}
+		return infoExporter.Export(exportCtx, startedAt, metrics, mount, usage)
 	}
 
-	err = infoExporter.Export(metrics)
 	if err != nil {
+		// Report the class of the error to the controller before failing.
+		exportErr := export(&DiskMetrics{FailureReason: classifyFailure(err)})
+		if exportErr != nil {
+			log.Printf("failed to export the failure: %v", exportErr)
+		}
 		return err
 	}
+	if metrics.ErrorNumber != 0 {
+		metrics.FailureReason = classifyFailure(syscall.Errno(metrics.ErrorNumber))
+	}
+
+	if devicePath == "" && metadataOperations != 0 {
+		metrics.MetadataOperations, err = RunMetadataBenchmark(probeCtx, measurePath, metadataOperations)
+		if err != nil {
+			log.Printf("failed to run the metadata benchmark: %v", err)
+		}
+	}
 
-	return nil
+	return export(metrics)
+}
+
+func newInfoExporter(
+	serverURI string,
+	grpcAddress string,
+	identity types.ProbeIdentity,
+	volumeMode string,
+	spoolPath string,
+	terminationMessagePath string,
+) DiskInfoExporter {
+	if terminationMessagePath != "" {
+		return NewTerminationMessageExporter(terminationMessagePath, identity, volumeMode)
+	}
+	if grpcAddress != "" {
+		return NewGRPCDiskInfoExporter(grpcAddress, identity, volumeMode, spoolPath)
+	}
+	return NewDiskInfoExporter(serverURI, identity, volumeMode, spoolPath)
 }
@@ -0,0 +1,159 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/topolvm/pie/types"
+)
+
+const (
+	// sharedFilePrefix is the prefix of the files written by the shared-access probes on each node.
+	sharedFilePrefix = ".pie-shared-"
+	// sharedFilePollInterval is the interval to look for the files written on the other nodes.
+	sharedFilePollInterval = 100 * time.Millisecond
+)
+
+type sharedFile struct {
+	Node        string    `json:"node"`
+	WrittenTime time.Time `json:"written_time"`
+}
+
+// writeSharedFile writes the node-stamped file atomically by renaming a temporary file.
+func writeSharedFile(path, node string) error {
+	content, err := json.Marshal(sharedFile{Node: node, WrittenTime: time.Now()})
+	if err != nil {
+		return err
+	}
+	name := filepath.Join(path, sharedFilePrefix+node)
+	f, err := os.Create(name + ".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(content)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	err = f.Sync()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(name+".tmp", name)
+}
+
+// readSharedFile returns the time when the file of the node was written.
+// It returns false if the file is not visible yet, and an error if its content is not the one written.
+func readSharedFile(path, node string) (time.Time, bool, error) {
+	content, err := os.ReadFile(filepath.Join(path, sharedFilePrefix+node))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, err
+	}
+	var f sharedFile
+	err = json.Unmarshal(content, &f)
+	if err != nil {
+		return time.Time{}, true, fmt.Errorf("unexpected content of the file of %s: %q", node, content)
+	}
+	if f.Node != node {
+		return time.Time{}, true, fmt.Errorf("the file of %s is stamped by %s", node, f.Node)
+	}
+	return f.WrittenTime, true, nil
+}
+
+// checkSharedAccess writes the file of the node and waits for the files written on the other nodes.
+// The visibility latency of a file is measured from the time written in it, so it includes the clock skew.
+func checkSharedAccess(path, node string, nodes []string, timeout time.Duration) types.SharedAccessExchangeFormat {
+	result := types.SharedAccessExchangeFormat{}
+	if err := writeSharedFile(path, node); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write the shared file: %v\n", err)
+		result.ConsistencyErrors++
+		return result
+	}
+
+	waiting := map[string]struct{}{}
+	for _, n := range nodes {
+		waiting[n] = struct{}{}
+	}
+	// The own file is read back as well.
+	waiting[node] = struct{}{}
+
+	deadline := time.Now().Add(timeout)
+	for len(waiting) != 0 && time.Now().Before(deadline) {
+		for n := range waiting {
+			writtenTime, visible, err := readSharedFile(path, n)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				result.ConsistencyErrors++
+				delete(waiting, n)
+				continue
+			}
+			if !visible {
+				continue
+			}
+			delete(waiting, n)
+			latency := max(time.Since(writtenTime).Seconds(), 0)
+			result.VisibilityLatency = max(result.VisibilityLatency, latency)
+		}
+		time.Sleep(sharedFilePollInterval)
+	}
+	if len(waiting) != 0 {
+		fmt.Fprintf(os.Stderr, "the files of %s are not visible within %s\n",
+			strings.Join(sortedKeys(waiting), ", "), timeout)
+	}
+	result.AllVisible = len(waiting) == 0 && result.ConsistencyErrors == 0
+	return result
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// SharedAccessMain checks the files written on the nodes sharing the volume are visible on this node
+// within the deadline, and posts the result to the controller.
+func SharedAccessMain(
+	ctx context.Context,
+	namespace, pieProbeName, node, storageClass, path, serverURI string,
+	nodes []string,
+	deadline time.Duration,
+) error {
+	result := checkSharedAccess(path, node, nodes, deadline)
+	result.Namespace = namespace
+	result.PieProbeName = pieProbeName
+	result.Node = node
+	result.StorageClass = storageClass
+
+	s, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	err = postWithRetry(ctx, strings.TrimSuffix(serverURI, "/")+types.SharedAccessProbePath, s)
+	if err != nil {
+		return err
+	}
+
+	if !result.AllVisible {
+		return fmt.Errorf("%d files have unexpected content or some files are not visible",
+			result.ConsistencyErrors)
+	}
+	return nil
+}
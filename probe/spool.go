@@ -0,0 +1,125 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/topolvm/pie/types"
+)
+
+const (
+	// spoolDirName is the directory on the volume where the results not delivered to the controller are spooled.
+	spoolDirName = ".pie-spool"
+	// maxSpooledResults is the number of the results kept in the spool. The oldest ones are dropped beyond it.
+	maxSpooledResults = 100
+)
+
+// resultSpool keeps the results not delivered to the controller until they are delivered on the next runs.
+// Each result is a file named after its timestamp and its run ID, so that the files are sorted by their ages.
+type resultSpool struct {
+	dir string
+}
+
+func newResultSpool(path string) *resultSpool {
+	return &resultSpool{
+		dir: filepath.Join(path, spoolDirName),
+	}
+}
+
+// put writes the result into the spool.
+func (s *resultSpool) put(result *types.ResultEnvelope) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create the spool %s: %w", s.dir, err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	// Write it to a temporary file first not to replay a partially written result.
+	name := fmt.Sprintf("%020d-%s.json", result.FinishedAt.UnixNano(), result.RunID)
+	tmpName := filepath.Join(s.dir, "."+name)
+	if err := os.WriteFile(tmpName, data, 0644); err != nil {
+		return fmt.Errorf("failed to spool the result: %w", err)
+	}
+	if err := os.Rename(tmpName, filepath.Join(s.dir, name)); err != nil {
+		return fmt.Errorf("failed to spool the result: %w", err)
+	}
+
+	names, err := s.list()
+	if err != nil {
+		return err
+	}
+	for len(names) > maxSpooledResults {
+		log.Printf("dropping the oldest spooled result %s", names[0])
+		if err := os.Remove(filepath.Join(s.dir, names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// list returns the names of the spooled results from the oldest.
+func (s *resultSpool) list() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// replay sends the spooled results from the oldest, and removes the ones delivered, rejected or corrupted.
+// It stops at the first result not delivered.
+func (s *resultSpool) replay(ctx context.Context, send func(context.Context, *types.ResultEnvelope) error) error {
+	names, err := s.list()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		filename := filepath.Join(s.dir, name)
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		var result types.ResultEnvelope
+		if err := json.Unmarshal(data, &result); err != nil {
+			log.Printf("dropping the corrupted spooled result %s: %v", name, err)
+			if err := os.Remove(filename); err != nil {
+				return err
+			}
+			continue
+		}
+		err = send(ctx, &result)
+		switch {
+		case errors.Is(err, errRejected):
+			log.Printf("dropping the spooled result %s rejected by the controller: %v", name, err)
+		case err != nil:
+			return err
+		default:
+			log.Printf("replayed the spooled result %s", name)
+		}
+		if err := os.Remove(filename); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/topolvm/pie/types"
+)
+
+func spoolResults(t *testing.T, spool *resultSpool, count int) []string {
+	t.Helper()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	runIDs := []string{}
+	for i := 0; i < count; i++ {
+		runID := fmt.Sprintf("run-%03d", i)
+		err := spool.put(&types.ResultEnvelope{
+			SchemaVersion: types.ResultSchemaVersion,
+			RunID:         runID,
+			FinishedAt:    base.Add(time.Duration(i) * time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("failed to spool the result: %v", err)
+		}
+		runIDs = append(runIDs, runID)
+	}
+	return runIDs
+}
+
+func TestResultSpoolPut(t *testing.T) {
+	testCases := []struct {
+		name      string
+		count     int
+		wantCount int
+	}{
+		{name: "keeps the results", count: 3, wantCount: 3},
+		{name: "keeps the results up to the limit", count: maxSpooledResults, wantCount: maxSpooledResults},
+		{name: "drops the oldest results beyond the limit", count: maxSpooledResults + 5, wantCount: maxSpooledResults},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spool := newResultSpool(t.TempDir())
+			runIDs := spoolResults(t, spool, tc.count)
+
+			var replayed []string
+			err := spool.replay(context.Background(), func(_ context.Context, result *types.ResultEnvelope) error {
+				replayed = append(replayed, result.RunID)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("failed to replay the results: %v", err)
+			}
+			if want := runIDs[tc.count-tc.wantCount:]; !slices.Equal(replayed, want) {
+				t.Errorf("replayed %v, want %v", replayed, want)
+			}
+
+			// No temporary file is left after the results are written.
+			entries, err := os.ReadDir(spool.dir)
+			if err != nil {
+				t.Fatalf("failed to read the spool: %v", err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("%d files are left after the replay", len(entries))
+			}
+		})
+	}
+}
+
+func TestResultSpoolReplay(t *testing.T) {
+	errFailed := errors.New("failed")
+	testCases := []struct {
+		name         string
+		errs         map[string]error
+		wantErr      error
+		wantReplayed []string
+		wantLeft     int
+	}{
+		{
+			name:         "replays the results from the oldest",
+			wantReplayed: []string{"run-000", "run-001", "run-002"},
+		},
+		{
+			name:         "stops at the first result not delivered",
+			errs:         map[string]error{"run-001": errFailed},
+			wantErr:      errFailed,
+			wantReplayed: []string{"run-000", "run-001"},
+			wantLeft:     2,
+		},
+		{
+			name:         "drops the rejected results",
+			errs:         map[string]error{"run-001": errRejected},
+			wantReplayed: []string{"run-000", "run-001", "run-002"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spool := newResultSpool(t.TempDir())
+			spoolResults(t, spool, 3)
+
+			// The partially written results and the corrupted ones are never replayed.
+			err := os.WriteFile(filepath.Join(spool.dir, ".00000000000000000000-partial.json"), []byte("{"), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = os.WriteFile(filepath.Join(spool.dir, "00000000000000000000-corrupted.json"), []byte("{"), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var replayed []string
+			err = spool.replay(context.Background(), func(_ context.Context, result *types.ResultEnvelope) error {
+				replayed = append(replayed, result.RunID)
+				return tc.errs[result.RunID]
+			})
+			if !errors.Is(err, tc.wantErr) || (tc.wantErr == nil && err != nil) {
+				t.Errorf("replay() = %v, want %v", err, tc.wantErr)
+			}
+			if !slices.Equal(replayed, tc.wantReplayed) {
+				t.Errorf("replayed %v, want %v", replayed, tc.wantReplayed)
+			}
+			names, err := spool.list()
+			if err != nil {
+				t.Fatalf("failed to list the results: %v", err)
+			}
+			if len(names) != tc.wantLeft {
+				t.Errorf("%d results are left, want %d: %v", len(names), tc.wantLeft, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package probe
+
+import (
+	"fmt"
+	"syscall"
+
+	"github.com/topolvm/pie/types"
+)
+
+// GetFilesystemUsage returns the usage of the filesystem containing the path.
+func GetFilesystemUsage(path string) (*types.FilesystemUsage, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return nil, fmt.Errorf("failed to statfs %s: %w", path, err)
+	}
+	blockSize := uint64(stat.Bsize)
+	return &types.FilesystemUsage{
+		TotalBytes:  stat.Blocks * blockSize,
+		FreeBytes:   stat.Bavail * blockSize,
+		TotalInodes: stat.Files,
+		FreeInodes:  stat.Ffree,
+	}, nil
+}
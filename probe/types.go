@@ -1,11 +1,22 @@
 package probe
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/topolvm/pie/types"
+)
 
 type DiskMetrics struct {
 	ReadLatency  float64
 	WriteLatency float64
 	ErrorNumber  int
+	// IOMode is the mode of the I/O benchmarked, i.e. types.IOModeDirect or types.IOModeBuffered.
+	IOMode string
+	// FailureReason is the class of the error if the probe failed.
+	FailureReason string
+	// MetadataOperations is the result of the metadata benchmark if it is run.
+	MetadataOperations []types.MetadataOperationResult
 }
 
 type DiskMetricsInterface interface {
@@ -13,5 +24,13 @@ type DiskMetricsInterface interface {
 }
 
 type DiskInfoExporter interface {
-	Export(metrics *DiskMetrics) error
+	// Export exports the metrics of the run started at startedAt with the mount and the usage of the filesystem,
+	// which are nil for the Block volumes.
+	Export(
+		ctx context.Context,
+		startedAt time.Time,
+		metrics *DiskMetrics,
+		mount *types.MountInfo,
+		usage *types.FilesystemUsage,
+	) error
 }
@@ -143,6 +143,10 @@ var _ = Describe("PieProbe resource", func() {
 		succeedLabelKey := "succeed"
 		succeedTrueLabelPair := io_prometheus_client.LabelPair{Name: &succeedLabelKey, Value: &trueValue}
 
+		volumeModeLabelKey := "volume_mode"
+		volumeModeLabelValue := "Filesystem"
+		volumeModeLabelPair := io_prometheus_client.LabelPair{Name: &volumeModeLabelKey, Value: &volumeModeLabelValue}
+
 		pieProbeNameLabelKey := "pie_probe_name"
 		pieProbeNameLabelValue := "pie-probe-standard-sc"
 		pieProbeNameLabelPair := io_prometheus_client.LabelPair{Name: &pieProbeNameLabelKey, Value: &pieProbeNameLabelValue}
@@ -158,7 +162,8 @@ var _ = Describe("PieProbe resource", func() {
 			metricFamilies, err := parser.TextToMetricFamilies(resp.Body)
 			g.Expect(err).NotTo(HaveOccurred())
 
-			By("checking latency metrics have node and storage_class labels and the storage_class label value is standard")
+			By("checking latency metrics have node, storage_class and volume_mode labels " +
+				"and the storage_class label value is standard")
 			for _, metricName := range []string{
 				"pie_io_write_latency_on_mount_probe_seconds",
 				"pie_io_read_latency_on_mount_probe_seconds",
@@ -168,6 +173,22 @@ var _ = Describe("PieProbe resource", func() {
 					g.Expect(metric.Label).Should(ContainElement(&pieProbeNameLabelPair))
 					g.Expect(metric.Label).Should(ContainElement(&nodeLabelPair))
 					g.Expect(metric.Label).Should(ContainElement(&standardSCLabelPair))
+					g.Expect(metric.Label).Should(ContainElement(&volumeModeLabelPair))
+				}
+			}
+
+			By("checking the filesystem usage metrics are positive")
+			for _, metricName := range []string{
+				"pie_filesystem_size_bytes_on_mount_probe",
+				"pie_filesystem_free_bytes_on_mount_probe",
+				"pie_filesystem_inodes_on_mount_probe",
+				"pie_filesystem_free_inodes_on_mount_probe",
+			} {
+				g.Expect(metricName).Should(BeKeyOf(metricFamilies))
+				for _, metric := range metricFamilies[metricName].Metric {
+					g.Expect(metric.Label).Should(ContainElement(&pieProbeNameLabelPair))
+					g.Expect(metric.Label).Should(ContainElement(&standardSCLabelPair))
+					g.Expect(metric.GetGauge().GetValue()).Should(BeNumerically(">", 0))
 				}
 			}
 
@@ -1,10 +1,178 @@
 package types
 
+import "time"
+
+// The schema versions of ResultEnvelope, which is "<major>.<minor>".
+// The minor version is bumped on the compatible changes, e.g. new types of the measurements ignored by the older
+// receivers. The major version is bumped on the incompatible ones, which the receivers of the other major versions
+// reject.
+const (
+	ResultSchemaMajorVersion = 1
+	ResultSchemaVersion      = "1.0"
+)
+
+// The kinds of the probes reporting ResultEnvelope.
+const (
+	ProbeKindMount = "mount"
+)
+
+// ResultEnvelope is the result of a run of a probe.
+// It replaces MetricsExchangeFormat, which is still accepted from the older probes.
+type ResultEnvelope struct {
+	SchemaVersion string `json:"schema_version"`
+	// RunID identifies the run of the probe. The receiver ignores the results of the same run ID sent again.
+	RunID    string        `json:"run_id"`
+	Kind     string        `json:"kind"`
+	Identity ProbeIdentity `json:"identity"`
+	// StartedAt and FinishedAt are the times when the run started and finished.
+	// The results replayed from the spool keep their times.
+	StartedAt    time.Time     `json:"started_at"`
+	FinishedAt   time.Time     `json:"finished_at"`
+	Measurements []Measurement `json:"measurements"`
+}
+
+// ProbeIdentity identifies the probe and the volume probed.
+type ProbeIdentity struct {
+	PieProbeName string `json:"pie_probe_name"`
+	Node         string `json:"node"`
+	StorageClass string `json:"storage_class"`
+	// Namespace and PodName are the Pod of the probe.
+	// The Pod is in the namespace of the PieProbe, so the namespace also identifies the PieProbe.
+	Namespace string `json:"namespace,omitempty"`
+	PodName   string `json:"pod_name,omitempty"`
+	// PVCName and PVName are the volume probed.
+	// PVName is empty if the PVC is not bound yet when the probe is scheduled.
+	PVCName string `json:"pvc_name,omitempty"`
+	PVName  string `json:"pv_name,omitempty"`
+}
+
+// The types of the measurements.
+const (
+	MeasurementTypePerformance       = "performance"
+	MeasurementTypeMount             = "mount"
+	MeasurementTypeFilesystemUsage   = "filesystem_usage"
+	MeasurementTypeMetadataOperation = "metadata_operation"
+)
+
+// Measurement is a measurement in ResultEnvelope. Only the field of its type is set.
+// The receivers ignore the types unknown to them.
+type Measurement struct {
+	Type              string                   `json:"type"`
+	Performance       *PerformanceMeasurement  `json:"performance,omitempty"`
+	Mount             *MountInfo               `json:"mount,omitempty"`
+	FilesystemUsage   *FilesystemUsage         `json:"filesystem_usage,omitempty"`
+	MetadataOperation *MetadataOperationResult `json:"metadata_operation,omitempty"`
+}
+
+// PerformanceMeasurement is the result of the benchmark of the volume.
+type PerformanceMeasurement struct {
+	// VolumeMode is Filesystem or Block.
+	VolumeMode string `json:"volume_mode"`
+	// IOMode is the mode of the I/O benchmarked. It is empty if the probe failed before the benchmark.
+	IOMode    string `json:"io_mode,omitempty"`
+	Succeeded bool   `json:"succeeded"`
+	// FailureReason is the class of the error if the probe failed.
+	FailureReason string `json:"failure_reason,omitempty"`
+	// ReadLatency and WriteLatency are the mean latencies in seconds. They are valid only if the probe succeeded.
+	ReadLatency  float64 `json:"read_latency"`
+	WriteLatency float64 `json:"write_latency"`
+}
+
+// MetricsExchangeFormat is the unversioned result of the mount probes older than ResultEnvelope.
 type MetricsExchangeFormat struct {
-	PieProbeName            string  `json:"pie_probe_name"`
-	Node                    string  `json:"node"`
-	StorageClass            string  `json:"storage_class"`
-	WriteLatency            float64 `json:"write_latency"`
-	ReadLatency             float64 `json:"read_latency"`
-	PerformanceProbeSucceed bool    `json:"performance_probe_succeed"`
+	// RunID identifies the run of the probe. The receiver ignores the results of the same run ID sent again.
+	// It is empty if the probe is older than the field.
+	RunID string `json:"run_id,omitempty"`
+	// Timestamp is the time when the result was taken. The results replayed from the spool keep their timestamps.
+	Timestamp               time.Time `json:"timestamp,omitzero"`
+	PieProbeName            string    `json:"pie_probe_name"`
+	Node                    string    `json:"node"`
+	StorageClass            string    `json:"storage_class"`
+	WriteLatency            float64   `json:"write_latency"`
+	ReadLatency             float64   `json:"read_latency"`
+	PerformanceProbeSucceed bool      `json:"performance_probe_succeed"`
+	// VolumeMode is Filesystem or Block. It is empty if the probe is older than the field.
+	VolumeMode string `json:"volume_mode,omitempty"`
+	// IOMode is the mode of the I/O benchmarked. It is empty if the probe is older than the field,
+	// which always benchmarks with O_DIRECT, or failed before the benchmark.
+	IOMode string `json:"io_mode,omitempty"`
+	// FailureReason is the class of the error if the probe failed. It is empty if the probe succeeded.
+	FailureReason string `json:"failure_reason,omitempty"`
+	// Mount is the mount of the filesystem probed. It is nil for the Block volumes and the older probes.
+	Mount *MountInfo `json:"mount,omitempty"`
+	// Usage is the usage of the filesystem probed. It is nil for the Block volumes and the older probes.
+	Usage *FilesystemUsage `json:"usage,omitempty"`
+	// MetadataOperations is the result of the metadata benchmark. It is empty if the benchmark is not run.
+	MetadataOperations []MetadataOperationResult `json:"metadata_operations,omitempty"`
+}
+
+// FilesystemUsage is the capacity and the inodes of a filesystem read by statfs(2).
+// The free bytes and inodes are the ones available to unprivileged users.
+type FilesystemUsage struct {
+	TotalBytes  uint64 `json:"total_bytes"`
+	FreeBytes   uint64 `json:"free_bytes"`
+	TotalInodes uint64 `json:"total_inodes"`
+	FreeInodes  uint64 `json:"free_inodes"`
+}
+
+// The operations of the metadata benchmark.
+const (
+	MetadataOperationCreate   = "create"
+	MetadataOperationFsync    = "fsync"
+	MetadataOperationDirFsync = "dir_fsync"
+	MetadataOperationRename   = "rename"
+	MetadataOperationStat     = "stat"
+	MetadataOperationUnlink   = "unlink"
+)
+
+// MetadataOperationResult is the result of an operation of the metadata benchmark.
+type MetadataOperationResult struct {
+	Operation string `json:"operation"`
+	// Latency is the mean latency in seconds of the succeeded operations. It is 0 if none of them succeeded.
+	Latency float64 `json:"latency"`
+	Errors  int     `json:"errors"`
+}
+
+// The modes of the I/O of the benchmarks.
+const (
+	// IOModeDirect bypasses the page cache with O_DIRECT.
+	IOModeDirect = "direct"
+	// IOModeBuffered goes through the page cache and calls fdatasync after every write.
+	// It is used if the volume rejects O_DIRECT.
+	IOModeBuffered = "buffered"
+)
+
+// The classes of the errors failing the probes.
+const (
+	FailureReasonReadOnly         = "erofs"
+	FailureReasonNoSpace          = "enospc"
+	FailureReasonIOError          = "eio"
+	FailureReasonQuotaExceeded    = "edquot"
+	FailureReasonTimeout          = "timeout"
+	FailureReasonPermissionDenied = "permission_denied"
+	FailureReasonUnknown          = "unknown"
+)
+
+// MountInfo is the mount of a filesystem read from /proc/self/mountinfo.
+type MountInfo struct {
+	FilesystemType string `json:"filesystem_type"`
+	// MountOptions holds both the per-mount options and the per-superblock options.
+	MountOptions []string `json:"mount_options"`
+	ReadOnly     bool     `json:"read_only"`
+}
+
+// SharedAccessProbePath is the path on the controller to which the shared-access probes post their results.
+const SharedAccessProbePath = "/shared-access"
+
+type SharedAccessExchangeFormat struct {
+	Namespace    string `json:"namespace"`
+	PieProbeName string `json:"pie_probe_name"`
+	Node         string `json:"node"`
+	StorageClass string `json:"storage_class"`
+	// VisibilityLatency is the longest time until the files written on the other nodes are seen.
+	// It is valid only if all of them are seen.
+	VisibilityLatency float64 `json:"visibility_latency"`
+	AllVisible        bool    `json:"all_visible"`
+	// ConsistencyErrors is the number of the files whose content is not the one written.
+	ConsistencyErrors int `json:"consistency_errors"`
 }
@@ -0,0 +1,79 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestResultEncoding checks the keys of the results exchanged with the other versions of the probes and the controller.
+func TestResultEncoding(t *testing.T) {
+	testCases := []struct {
+		name        string
+		value       any
+		wantKeys    []string
+		notWantKeys []string
+	}{
+		{
+			name: "envelope",
+			value: ResultEnvelope{
+				SchemaVersion: ResultSchemaVersion,
+				RunID:         "run-1",
+				Kind:          ProbeKindMount,
+				Identity:      ProbeIdentity{PieProbeName: "pie-probe", Node: "node1", StorageClass: "sc"},
+				Measurements: []Measurement{
+					{
+						Type:        MeasurementTypePerformance,
+						Performance: &PerformanceMeasurement{VolumeMode: "Filesystem", Succeeded: true},
+					},
+				},
+			},
+			wantKeys: []string{
+				`"schema_version":"1.0"`, `"run_id":"run-1"`, `"kind":"mount"`, `"pie_probe_name":"pie-probe"`,
+				`"type":"performance"`, `"volume_mode":"Filesystem"`, `"succeeded":true`,
+			},
+			notWantKeys: []string{
+				`"pod_name"`, `"pvc_name"`, `"pv_name"`, `"io_mode"`, `"failure_reason"`, `"mount":`,
+			},
+		},
+		{
+			name: "legacy result without the optional fields",
+			value: MetricsExchangeFormat{
+				PieProbeName:            "pie-probe",
+				PerformanceProbeSucceed: true,
+			},
+			wantKeys: []string{`"pie_probe_name":"pie-probe"`, `"performance_probe_succeed":true`},
+			notWantKeys: []string{
+				`"run_id"`, `"timestamp"`, `"volume_mode"`, `"io_mode"`, `"failure_reason"`, `"mount":`, `"usage"`,
+				`"metadata_operations"`,
+			},
+		},
+		{
+			name: "legacy result with the timestamp",
+			value: MetricsExchangeFormat{
+				PieProbeName: "pie-probe",
+				Timestamp:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+			wantKeys: []string{`"timestamp":"2024-01-02T03:04:05Z"`},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.value)
+			if err != nil {
+				t.Fatalf("failed to encode: %v", err)
+			}
+			for _, key := range tc.wantKeys {
+				if !strings.Contains(string(data), key) {
+					t.Errorf("%s does not contain %s", data, key)
+				}
+			}
+			for _, key := range tc.notWantKeys {
+				if strings.Contains(string(data), key) {
+					t.Errorf("%s contains %s", data, key)
+				}
+			}
+		})
+	}
+}
@@ -8,6 +8,10 @@ ACTIONLINT_VERSION := v1.7.12
 GHALINT_VERSION := v1.5.6
 # https://github.com/helm/chart-testing/releases
 CHART_TESTING_VERSION := 3.14.0
+# https://github.com/bufbuild/buf/releases
+BUF_VERSION := v1.65.0
+# https://github.com/grpc/grpc-go/releases
+PROTOC_GEN_GO_GRPC_VERSION := v1.5.1
 # https://github.com/kubernetes-sigs/controller-tools/releases
 CONTROLLER_TOOLS_VERSION := v0.20.1
 # https://github.com/golangci/golangci-lint/releases
@@ -25,6 +29,7 @@ KUBERNETES_VERSION ?= 1.35
 # Tools versions which are defined in go.mod
 SELF_DIR := $(dir $(lastword $(MAKEFILE_LIST)))
 CONTROLLER_RUNTIME_VERSION := $(shell awk '/sigs\.k8s\.io\/controller-runtime/ {print substr($$2, 2)}' $(SELF_DIR)/go.mod)
+PROTOC_GEN_GO_VERSION := $(shell awk '/google\.golang\.org\/protobuf/ {print $$2}' $(SELF_DIR)/go.mod)
 
 ENVTEST_K8S_VERSION := $(KUBERNETES_VERSION).0
 